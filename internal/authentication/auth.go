@@ -1,11 +1,15 @@
 package authentication
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/alexedwards/argon2id"
@@ -29,8 +33,30 @@ func CheckPasswordHash(password, hash string) (bool, error) {
 	return password_equal_hash, nil
 }
 
-func MakeJWT(userID int, tokenSecret string, expresIn time.Duration) (string, error) {
-	claims := jwt.RegisteredClaims{}
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// CheckDummyPasswordHash runs a full argon2id comparison against a throwaway
+// hash, so a login for an unknown email costs as much as one for a real account.
+func CheckDummyPasswordHash(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = HashPassword("dummy-password")
+	})
+	if dummyHash == "" {
+		return
+	}
+	CheckPasswordHash(password, dummyHash)
+}
+
+type Claims struct {
+	Role string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+func MakeJWT(userID int, role string, tokenSecret string, expresIn time.Duration) (string, error) {
+	claims := Claims{Role: role}
 	claims.Issuer = "Books Store"
 	claims.IssuedAt = jwt.NewNumericDate(time.Now().UTC())
 	claims.ExpiresAt = jwt.NewNumericDate(time.Now().UTC().Add(expresIn))
@@ -40,26 +66,26 @@ func MakeJWT(userID int, tokenSecret string, expresIn time.Duration) (string, er
 	return newToken.SignedString([]byte(tokenSecret))
 }
 
-func ValidateJWT(tokenString, tokenSecret string) (int, error) {
-	claims := &jwt.RegisteredClaims{}
+func ValidateJWT(tokenString, tokenSecret string) (int, string, error) {
+	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
 		return []byte(tokenSecret), nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 
 	if err != nil {
-		return 0, err
+		return 0, "", err
 	}
 
 	if !token.Valid {
-		return 0, errors.New("Invalid token")
+		return 0, "", errors.New("Invalid token")
 	}
 
 	userId, err := strconv.Atoi(claims.Subject)
 	if err != nil {
-		return 0, err
+		return 0, "", err
 	}
 
-	return userId, nil
+	return userId, claims.Role, nil
 }
 
 func GetCustomerToken(headers http.Header) (string, error) {
@@ -80,3 +106,18 @@ func GetCustomerToken(headers http.Header) (string, error) {
 
 	return token, nil
 }
+
+func MakeRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("Could not generate refresh token, %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashRefreshToken returns the form refresh tokens are stored in, so a leaked
+// database file does not hand out working tokens.
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,14 +1,43 @@
 package config
 
 import (
+	"os"
+	"strconv"
 	"time"
 )
 
+const (
+	StoreBackendMemory = "memory"
+	StoreBackendSQLite = "sqlite"
+)
+
 type Config struct {
 	DBPath                string
 	ServerPort            string
 	ReportInterval        time.Duration
 	ReportOutputDirectory string
+	StoreBackend          string
+	SQLitePath            string
+	BackupDirectory       string
+	BackupCount           int
+	PublicIDs             bool
+	// ExportSender names the store in ONIX catalog exports, whose prices
+	// are in Currency (an ISO 4217 code).
+	ExportSender string
+	Currency     string
+	// Book covers are stored under CoverDirectory; uploads are limited to
+	// CoverMaxBytes.
+	CoverDirectory string
+	CoverMaxBytes  int
+	// ImportMaxBytes limits the size of a bulk book import.
+	ImportMaxBytes int
+	// RecommendationInterval is how often recommendations are rebuilt from
+	// all completed orders.
+	RecommendationInterval time.Duration
+	// Anonymous carts unchanged for SessionCartTTL are deleted, checked
+	// every CartExpiryInterval.
+	SessionCartTTL     time.Duration
+	CartExpiryInterval time.Duration
 }
 
 func LoadConfig() *Config {
@@ -17,5 +46,50 @@ func LoadConfig() *Config {
 		ServerPort:            "8080",
 		ReportInterval:        24 * time.Hour,
 		ReportOutputDirectory: "output-reports",
+		StoreBackend:          getEnv("STORE_BACKEND", StoreBackendMemory),
+		SQLitePath:            getEnv("SQLITE_PATH", "database.db"),
+		BackupDirectory:       getEnv("BACKUP_DIR", "backups"),
+		BackupCount:           getEnvInt("BACKUP_COUNT", 5),
+		PublicIDs:             getEnvBool("PUBLIC_IDS", false),
+		ExportSender:          getEnv("EXPORT_SENDER", "Book Store"),
+		Currency:              getEnv("CURRENCY", "USD"),
+		CoverDirectory:        getEnv("COVER_DIR", "covers"),
+		CoverMaxBytes:         getEnvInt("COVER_MAX_BYTES", 5<<20),
+		ImportMaxBytes:        getEnvInt("IMPORT_MAX_BYTES", 32<<20),
+
+		RecommendationInterval: getEnvDuration("RECOMMENDATION_INTERVAL", time.Hour),
+		SessionCartTTL:         getEnvDuration("SESSION_CART_TTL", 30*24*time.Hour),
+		CartExpiryInterval:     getEnvDuration("CART_EXPIRY_INTERVAL", time.Hour),
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
 	}
+	return value
 }
@@ -0,0 +1,175 @@
+package covers
+
+import (
+	"Book-Store/internal/models"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	SizeOriginal  = "original"
+	SizeMedium    = "medium"
+	SizeThumbnail = "thumbnail"
+)
+
+// maxCoverPixels bounds the decoded size of an upload, which can be far
+// larger than the file itself.
+const maxCoverPixels = 25_000_000
+
+var (
+	ErrUnsupportedImage = errors.New("cover must be a JPEG or PNG image")
+	ErrImageTooLarge    = errors.New("cover image dimensions are too large")
+)
+
+// variant is a resized copy of a cover, scaled down to fit its bounds.
+type variant struct {
+	size          string
+	width, height int
+}
+
+var variants = []variant{
+	{size: SizeMedium, width: 480, height: 720},
+	{size: SizeThumbnail, width: 160, height: 240},
+}
+
+func IsValidSize(size string) bool {
+	switch size {
+	case SizeOriginal, SizeMedium, SizeThumbnail:
+		return true
+	default:
+		return false
+	}
+}
+
+// CoverStore keeps the cover images of books on disk, one directory per
+// book holding the uploaded original and its variants.
+type CoverStore struct {
+	mu        sync.Mutex
+	directory string
+}
+
+func NewCoverStore(directory string) *CoverStore {
+	return &CoverStore{directory: directory}
+}
+
+// Save validates an uploaded image and stores it with its variants,
+// replacing any earlier cover of the book.
+func (cs *CoverStore) Save(bookID int, data []byte) (models.BookCover, error) {
+	switch http.DetectContentType(data) {
+	case "image/jpeg", "image/png":
+	default:
+		return models.BookCover{}, ErrUnsupportedImage
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return models.BookCover{}, ErrUnsupportedImage
+	}
+	if config.Width*config.Height > maxCoverPixels {
+		return models.BookCover{}, ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return models.BookCover{}, ErrUnsupportedImage
+	}
+
+	if err := os.MkdirAll(cs.directory, 0755); err != nil {
+		return models.BookCover{}, err
+	}
+
+	// Files are written to a fresh directory that then takes the place of
+	// the old one, so a failed upload leaves the previous cover intact.
+	staging, err := os.MkdirTemp(cs.directory, ".upload-")
+	if err != nil {
+		return models.BookCover{}, err
+	}
+	defer os.RemoveAll(staging)
+
+	if err := os.WriteFile(filepath.Join(staging, fileName(SizeOriginal, format)), data, 0644); err != nil {
+		return models.BookCover{}, err
+	}
+	for _, v := range variants {
+		if err := writeImage(filepath.Join(staging, fileName(v.size, format)),
+			resize(img, v.width, v.height), format); err != nil {
+			return models.BookCover{}, err
+		}
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	dir := cs.bookDirectory(bookID)
+	if err := os.RemoveAll(dir); err != nil {
+		return models.BookCover{}, err
+	}
+	if err := os.Rename(staging, dir); err != nil {
+		return models.BookCover{}, err
+	}
+
+	return models.BookCover{
+		Format:    format,
+		Width:     config.Width,
+		Height:    config.Height,
+		UpdatedAt: time.Now().UTC(),
+	}, nil
+}
+
+// Open returns the file holding one size of a book cover.
+func (cs *CoverStore) Open(bookID int, cover models.BookCover, size string) (*os.File, error) {
+	if !IsValidSize(size) {
+		return nil, fmt.Errorf("unknown cover size %q", size)
+	}
+	return os.Open(filepath.Join(cs.bookDirectory(bookID), fileName(size, cover.Format)))
+}
+
+// Delete removes every image of a book cover. Books without a cover are
+// not an error.
+func (cs *CoverStore) Delete(bookID int) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	return os.RemoveAll(cs.bookDirectory(bookID))
+}
+
+func (cs *CoverStore) bookDirectory(bookID int) string {
+	return filepath.Join(cs.directory, strconv.Itoa(bookID))
+}
+
+func fileName(size, format string) string {
+	if format == "jpeg" {
+		return size + ".jpg"
+	}
+	return size + "." + format
+}
+
+func writeImage(path string, img image.Image, format string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = encodeImage(file, img, format)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func encodeImage(w io.Writer, img image.Image, format string) error {
+	if format == "png" {
+		return png.Encode(w, img)
+	}
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+}
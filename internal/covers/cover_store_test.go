@@ -0,0 +1,228 @@
+package covers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// testImage returns a width by height image in a plain color.
+func testImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			img.Set(x, y, color.RGBA{R: 200, G: 80, B: 40, A: 255})
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// pngHeader is the start of a PNG claiming to be width by height, enough for
+// its dimensions to be read without any pixel data behind them.
+func pngHeader(width, height uint32) []byte {
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], width)
+	binary.BigEndian.PutUint32(ihdr[4:], height)
+	ihdr[8], ihdr[9] = 8, 2 // 8-bit RGB
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(&buf, binary.BigEndian, uint32(len(ihdr)))
+	chunk := append([]byte("IHDR"), ihdr...)
+	buf.Write(chunk)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(chunk))
+	return buf.Bytes()
+}
+
+func TestSaveRejectsUnsupportedImages(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"text", []byte("not an image at all"), ErrUnsupportedImage},
+		{"GIF", []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;"), ErrUnsupportedImage},
+		{"truncated PNG", encodePNG(t, testImage(20, 20))[:40], ErrUnsupportedImage},
+		{"too many pixels", pngHeader(10000, 10000), ErrImageTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cs := NewCoverStore(dir)
+			if _, err := cs.Save(1, tt.data); !errors.Is(err, tt.want) {
+				t.Fatalf("Save() error = %v, want %v", err, tt.want)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "1")); !os.IsNotExist(err) {
+				t.Errorf("rejected upload left files behind: %v", err)
+			}
+		})
+	}
+}
+
+func TestSaveWritesVariants(t *testing.T) {
+	type size struct{ width, height int }
+	tests := []struct {
+		name   string
+		data   []byte
+		format string
+		want   map[string]size
+	}{
+		{
+			name:   "wide PNG",
+			data:   encodePNG(t, testImage(1000, 500)),
+			format: "png",
+			want: map[string]size{
+				SizeOriginal:  {1000, 500},
+				SizeMedium:    {480, 240},
+				SizeThumbnail: {160, 80},
+			},
+		},
+		{
+			name:   "tall JPEG",
+			data:   encodeJPEG(t, testImage(300, 900)),
+			format: "jpeg",
+			want: map[string]size{
+				SizeOriginal:  {300, 900},
+				SizeMedium:    {240, 720},
+				SizeThumbnail: {80, 240},
+			},
+		},
+		{
+			name:   "small images are not enlarged",
+			data:   encodePNG(t, testImage(100, 120)),
+			format: "png",
+			want: map[string]size{
+				SizeOriginal:  {100, 120},
+				SizeMedium:    {100, 120},
+				SizeThumbnail: {100, 120},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := NewCoverStore(t.TempDir())
+			cover, err := cs.Save(7, tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			original := tt.want[SizeOriginal]
+			if cover.Format != tt.format || cover.Width != original.width || cover.Height != original.height {
+				t.Errorf("Save() = %+v, want a %dx%d %s", cover, original.width, original.height, tt.format)
+			}
+
+			for sizeName, want := range tt.want {
+				file, err := cs.Open(7, cover, sizeName)
+				if err != nil {
+					t.Fatal(err)
+				}
+				config, format, err := image.DecodeConfig(file)
+				file.Close()
+				if err != nil {
+					t.Fatalf("%s: %v", sizeName, err)
+				}
+				if format != tt.format || config.Width != want.width || config.Height != want.height {
+					t.Errorf("%s is a %dx%d %s, want %dx%d %s", sizeName,
+						config.Width, config.Height, format, want.width, want.height, tt.format)
+				}
+			}
+		})
+	}
+}
+
+func TestResizeAveragesPixels(t *testing.T) {
+	// A 4x2 image, red on the left and a black and white checkerboard on
+	// the right.
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	red := color.RGBA{R: 255, A: 255}
+	for y := range 2 {
+		src.Set(0, y, red)
+		src.Set(1, y, red)
+	}
+	src.Set(2, 0, color.White)
+	src.Set(3, 0, color.Black)
+	src.Set(2, 1, color.Black)
+	src.Set(3, 1, color.White)
+
+	dst := resize(src, 2, 2)
+	if got := dst.Bounds(); got.Dx() != 2 || got.Dy() != 1 {
+		t.Fatalf("resized to %v, want 2x1 to keep the aspect ratio", got)
+	}
+	if got := color.RGBAModel.Convert(dst.At(0, 0)); got != red {
+		t.Errorf("left pixel = %v, want %v", got, red)
+	}
+	gray := color.RGBA{R: 127, G: 127, B: 127, A: 255}
+	if got := color.RGBAModel.Convert(dst.At(1, 0)); got != gray {
+		t.Errorf("right pixel = %v, want the average %v", got, gray)
+	}
+}
+
+func TestSaveReplacesOldVariants(t *testing.T) {
+	dir := t.TempDir()
+	cs := NewCoverStore(dir)
+
+	if _, err := cs.Save(3, encodePNG(t, testImage(600, 900))); err != nil {
+		t.Fatal(err)
+	}
+	cover, err := cs.Save(3, encodeJPEG(t, testImage(600, 900)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if want := []string{"medium.jpg", "original.jpg", "thumbnail.jpg"}; !slices.Equal(names, want) {
+		t.Errorf("cover files = %v, want only the new %v", names, want)
+	}
+
+	// No staging directories are left next to the covers.
+	entries, err = os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("cover directory holds %d entries, want just the book's", len(entries))
+	}
+
+	if err := cs.Delete(3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cs.Open(3, cover, SizeOriginal); !os.IsNotExist(err) {
+		t.Errorf("Open() after Delete() error = %v, want the cover gone", err)
+	}
+	if err := cs.Delete(3); err != nil {
+		t.Errorf("Delete() of a missing cover: %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"Book-Store/internal/authentication"
+	"Book-Store/internal/http/middleware"
+	"Book-Store/internal/models"
+	"Book-Store/internal/response"
+	"Book-Store/internal/store"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const refreshTokenTTL = 30 * 24 * time.Hour
+
+type AuthHandler struct {
+	Store  store.CustomerStore
+	Tokens store.RefreshTokenStore
+	Carts  store.CartStore
+	Cfg    *middleware.ApiConfig
+}
+
+type loginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type tokenResponse struct {
+	ID           int    `json:"id"`
+	PublicID     string `json:"public_id,omitempty"`
+	Email        string `json:"email"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (h *AuthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	path = strings.TrimSpace(path)
+	pathParts := strings.Split(path, "/")
+
+	if len(pathParts) < 2 {
+		response.RespondWithError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		response.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	switch pathParts[1] {
+	case "login":
+		h.login(w, r)
+	case "refresh":
+		h.refresh(w, r)
+	case "logout":
+		h.logout(w, r)
+	default:
+		response.RespondWithError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+func (h *AuthHandler) login(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	var req loginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	email := strings.TrimSpace(req.Email)
+	if email == "" || req.Password == "" {
+		response.RespondWithError(w, http.StatusBadRequest, "Email and password are required!")
+		return
+	}
+
+	// Unknown emails and wrong passwords get the same answer, and both paths
+	// pay for a hash comparison so response times don't give accounts away.
+	customer, err := h.Store.GetCustomerByEmail(ctx, email)
+	if err != nil {
+		authentication.CheckDummyPasswordHash(req.Password)
+		response.RespondWithError(w, http.StatusUnauthorized, "Incorrect email or password")
+		return
+	}
+
+	match, err := authentication.CheckPasswordHash(req.Password, customer.Password)
+	if err != nil {
+		log.Printf("Could not check password for customer %d: %v", customer.ID, err)
+	}
+	if !match {
+		response.RespondWithError(w, http.StatusUnauthorized, "Incorrect email or password")
+		return
+	}
+
+	token, err := authentication.MakeJWT(customer.ID, customer.EffectiveRole(), h.Cfg.Token, accessTokenTTL)
+	if err != nil {
+		response.RespondWithError(w, http.StatusInternalServerError, "Error creating Token")
+		return
+	}
+
+	familyID, err := authentication.MakeRefreshToken()
+	if err != nil {
+		response.RespondWithError(w, http.StatusInternalServerError, "Error creating Token")
+		return
+	}
+
+	refreshToken, err := h.newRefreshToken(ctx, customer.ID, familyID)
+	if err != nil {
+		log.Printf("Could not store refresh token for customer %d: %v", customer.ID, err)
+		response.RespondWithError(w, http.StatusInternalServerError, "Error creating Token")
+		return
+	}
+
+	// A cart filled before logging in joins the customer's cart. Failing to
+	// merge it must not stop the login.
+	if session := r.Header.Get(cartSessionHeader); session != "" && h.Carts != nil {
+		if _, err := h.Carts.MergeCart(ctx, models.SessionCartKey(session), models.CustomerCartKey(customer.ID)); err != nil {
+			log.Printf("Could not merge session cart into cart of customer %d: %v", customer.ID, err)
+		}
+	}
+
+	response.RespondWithJSON(w, http.StatusOK, tokenResponse{
+		ID:           customer.ID,
+		PublicID:     customer.PublicID,
+		Email:        customer.Email,
+		Token:        token,
+		RefreshToken: refreshToken,
+	})
+}
+
+func (h *AuthHandler) refresh(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	var req refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		response.RespondWithError(w, http.StatusBadRequest, "refresh_token is required")
+		return
+	}
+
+	raw, err := authentication.MakeRefreshToken()
+	if err != nil {
+		response.RespondWithError(w, http.StatusInternalServerError, "Error creating Token")
+		return
+	}
+
+	rotated, err := h.Tokens.RotateRefreshToken(ctx,
+		authentication.HashRefreshToken(req.RefreshToken),
+		models.RefreshToken{
+			TokenHash: authentication.HashRefreshToken(raw),
+			ExpiresAt: time.Now().Add(refreshTokenTTL),
+		},
+	)
+	if err != nil {
+		if errors.Is(err, store.ErrRefreshTokenReused) {
+			log.Printf("Refresh token reuse detected, token family revoked")
+		}
+		response.RespondWithError(w, http.StatusUnauthorized, "Invalid refresh token")
+		return
+	}
+
+	// The role is read again so promotions and demotions apply on refresh.
+	customer, err := h.Store.GetCustomer(ctx, rotated.CustomerID)
+	if err != nil {
+		h.Tokens.RevokeRefreshToken(ctx, rotated.TokenHash)
+		response.RespondWithError(w, http.StatusUnauthorized, "Invalid refresh token")
+		return
+	}
+
+	token, err := authentication.MakeJWT(customer.ID, customer.EffectiveRole(), h.Cfg.Token, accessTokenTTL)
+	if err != nil {
+		response.RespondWithError(w, http.StatusInternalServerError, "Error creating Token")
+		return
+	}
+
+	response.RespondWithJSON(w, http.StatusOK, tokenResponse{
+		ID:           customer.ID,
+		PublicID:     customer.PublicID,
+		Email:        customer.Email,
+		Token:        token,
+		RefreshToken: raw,
+	})
+}
+
+func (h *AuthHandler) logout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	var req refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		response.RespondWithError(w, http.StatusBadRequest, "refresh_token is required")
+		return
+	}
+
+	err := h.Tokens.RevokeRefreshToken(ctx, authentication.HashRefreshToken(req.RefreshToken))
+	if err != nil && !errors.Is(err, store.ErrRefreshTokenNotFound) {
+		response.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Logged out"})
+}
+
+// newRefreshToken stores a fresh refresh token in the given family and returns
+// the raw value for the client; only its hash is kept server-side.
+func (h *AuthHandler) newRefreshToken(ctx context.Context, customerID int, familyID string) (string, error) {
+	raw, err := authentication.MakeRefreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = h.Tokens.CreateRefreshToken(ctx, models.RefreshToken{
+		TokenHash:  authentication.HashRefreshToken(raw),
+		CustomerID: customerID,
+		FamilyID:   familyID,
+		ExpiresAt:  time.Now().Add(refreshTokenTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return raw, nil
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"Book-Store/internal/authentication"
+	"Book-Store/internal/http/middleware"
+	"Book-Store/internal/models"
+	"Book-Store/internal/store/storetest"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newAuthTestHandler(t *testing.T) *AuthHandler {
+	t.Helper()
+	s := storetest.NewMemStore(t)
+
+	hash, err := authentication.HashPassword("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateCustomer(context.Background(), models.Customer{
+		Name: "Shevek", Email: "shevek@example.com", Password: hash,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return &AuthHandler{Store: s, Tokens: s, Carts: s, Cfg: &middleware.ApiConfig{Token: "test-secret"}}
+}
+
+type authResponse struct {
+	ID           int    `json:"id"`
+	Email        string `json:"email"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// postAuth sends body to /auth/{action} and decodes a successful response.
+func postAuth(h *AuthHandler, action, body string) (int, authResponse) {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/auth/"+action, strings.NewReader(body)))
+
+	var resp authResponse
+	if w.Code == http.StatusOK {
+		json.NewDecoder(w.Body).Decode(&resp)
+	}
+	return w.Code, resp
+}
+
+func TestLogin(t *testing.T) {
+	h := newAuthTestHandler(t)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"valid", `{"email":"shevek@example.com","password":"secret"}`, http.StatusOK},
+		{"email with spaces", `{"email":" shevek@example.com ","password":"secret"}`, http.StatusOK},
+		{"wrong password", `{"email":"shevek@example.com","password":"nope"}`, http.StatusUnauthorized},
+		{"unknown email", `{"email":"takver@example.com","password":"secret"}`, http.StatusUnauthorized},
+		{"missing password", `{"email":"shevek@example.com"}`, http.StatusBadRequest},
+		{"not JSON", `email=shevek`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := postAuth(h, "login", tt.body)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", status, tt.wantStatus)
+			}
+			if status != http.StatusOK {
+				return
+			}
+			if resp.Email != "shevek@example.com" || resp.Token == "" || resp.RefreshToken == "" {
+				t.Errorf("response = %+v, want email, token and refresh token", resp)
+			}
+			id, role, err := authentication.ValidateJWT(resp.Token, "test-secret")
+			if err != nil || id != resp.ID || role != models.RoleCustomer {
+				t.Errorf("token claims = %d, %q, %v, want %d, %q", id, role, err, resp.ID, models.RoleCustomer)
+			}
+		})
+	}
+}
+
+func TestLoginMethodNotAllowed(t *testing.T) {
+	h := newAuthTestHandler(t)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/login", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func refreshBody(token string) string {
+	return `{"refresh_token":"` + token + `"}`
+}
+
+func TestRefreshRotation(t *testing.T) {
+	h := newAuthTestHandler(t)
+
+	status, login := postAuth(h, "login", `{"email":"shevek@example.com","password":"secret"}`)
+	if status != http.StatusOK {
+		t.Fatalf("login status = %d", status)
+	}
+
+	status, first := postAuth(h, "refresh", refreshBody(login.RefreshToken))
+	if status != http.StatusOK {
+		t.Fatalf("refresh status = %d", status)
+	}
+	if first.RefreshToken == login.RefreshToken {
+		t.Fatal("refresh returned the same refresh token")
+	}
+	if first.ID != login.ID {
+		t.Errorf("refresh id = %d, want %d", first.ID, login.ID)
+	}
+	if first.Email != login.Email {
+		t.Errorf("refresh email = %q, want %q", first.Email, login.Email)
+	}
+
+	status, second := postAuth(h, "refresh", refreshBody(first.RefreshToken))
+	if status != http.StatusOK {
+		t.Fatalf("second refresh status = %d", status)
+	}
+
+	// Replaying a rotated token revokes its whole family, including the
+	// latest token.
+	if status, _ := postAuth(h, "refresh", refreshBody(login.RefreshToken)); status != http.StatusUnauthorized {
+		t.Errorf("reused token: status = %d, want %d", status, http.StatusUnauthorized)
+	}
+	if status, _ := postAuth(h, "refresh", refreshBody(second.RefreshToken)); status != http.StatusUnauthorized {
+		t.Errorf("token of a revoked family: status = %d, want %d", status, http.StatusUnauthorized)
+	}
+}
+
+func TestRefreshRejects(t *testing.T) {
+	h := newAuthTestHandler(t)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"missing token", `{}`, http.StatusBadRequest},
+		{"not JSON", `token`, http.StatusBadRequest},
+		{"unknown token", refreshBody("bm90LWlzc3VlZA"), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, _ := postAuth(h, "refresh", tt.body); status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestLogoutRevokesRefreshToken(t *testing.T) {
+	h := newAuthTestHandler(t)
+
+	_, login := postAuth(h, "login", `{"email":"shevek@example.com","password":"secret"}`)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/auth/logout", strings.NewReader(refreshBody(login.RefreshToken))))
+	if w.Code >= 300 {
+		t.Fatalf("logout status = %d", w.Code)
+	}
+
+	if status, _ := postAuth(h, "refresh", refreshBody(login.RefreshToken)); status != http.StatusUnauthorized {
+		t.Errorf("refresh after logout: status = %d, want %d", status, http.StatusUnauthorized)
+	}
+}
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"Book-Store/internal/covers"
 	"Book-Store/internal/models"
 	"Book-Store/internal/response"
 	"Book-Store/internal/store"
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
@@ -12,6 +15,18 @@ import (
 
 type AuthorHandler struct {
 	Store store.AuthorStore
+	Books store.BookStore
+	Tx    store.Transactor
+	IDs   store.PublicIDResolver
+	// Covers of books deleted along with their author are removed too.
+	Covers *covers.CoverStore
+}
+
+// createAuthorRequest lets a new author come with their books, which are
+// created in the same transaction.
+type createAuthorRequest struct {
+	models.Author
+	Books []models.Book `json:"books"`
 }
 
 func (h *AuthorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -23,12 +38,22 @@ func (h *AuthorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	var hasID bool
 
 	if len(pathParts) > 1 && pathParts[1] != "" {
-		idStr := strings.TrimSpace(pathParts[1])
-		parsedID, err := strconv.Atoi(idStr)
-		if err == nil {
-			id = parsedID
-			hasID = true
+		parsedID, ok := parseID(r.Context(), h.IDs, "authors", pathParts[1])
+		if !ok {
+			response.RespondWithError(w, http.StatusNotFound, "Author not found")
+			return
+		}
+		id = parsedID
+		hasID = true
+	}
+
+	if hasID && len(pathParts) == 3 && pathParts[2] == "books" {
+		if r.Method != http.MethodGet {
+			response.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
 		}
+		h.listAuthorBooks(w, r, id)
+		return
 	}
 
 	switch r.Method {
@@ -61,19 +86,64 @@ func (h *AuthorHandler) createAuthor(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	defer r.Body.Close()
 
-	var author models.Author
-	if err := json.NewDecoder(r.Body).Decode(&author); err != nil {
+	var req createAuthorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		response.RespondWithError(w, http.StatusBadRequest, "Invalid JSON")
 		return
 	}
 
-	createdAuthor, err := h.Store.CreateAuthor(ctx, author)
+	if len(req.Books) == 0 {
+		createdAuthor, err := h.Store.CreateAuthor(ctx, req.Author)
+		if err != nil {
+			response.RespondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		response.RespondWithJSON(w, http.StatusCreated, createdAuthor)
+		return
+	}
+
+	var (
+		createdAuthor models.Author
+		createdBooks  []models.Book
+	)
+	err := h.Tx.WithTx(ctx, func(tx store.Stores) error {
+		var err error
+		createdAuthor, err = tx.CreateAuthor(ctx, req.Author)
+		if err != nil {
+			return err
+		}
+
+		for _, book := range req.Books {
+			if err := book.NormalizeMetadata(); err != nil {
+				return err
+			}
+			// The new author is credited first, ahead of any contributors
+			// the book already names.
+			book.Author = createdAuthor
+			book.Contributors = append([]models.Contributor{
+				{Author: createdAuthor, Role: models.ContributorAuthor},
+			}, book.Contributors...)
+			createdBook, err := tx.CreateBook(ctx, book)
+			if err != nil {
+				return err
+			}
+			createdBooks = append(createdBooks, createdBook)
+		}
+		return nil
+	})
 	if err != nil {
-		response.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		response.RespondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	response.RespondWithJSON(w, http.StatusCreated, createdAuthor)
+	response.RespondWithJSON(w, http.StatusCreated, struct {
+		models.Author
+		Books []models.Book `json:"books"`
+	}{
+		Author: createdAuthor,
+		Books:  createdBooks,
+	})
 }
 
 func (h *AuthorHandler) getAuthor(w http.ResponseWriter, r *http.Request, id int) {
@@ -91,13 +161,42 @@ func (h *AuthorHandler) getAuthor(w http.ResponseWriter, r *http.Request, id int
 func (h *AuthorHandler) listAuthors(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	authors, err := h.Store.ListAuthors(ctx)
+	pageReq, ok := parsePageRequest(r)
+	if !ok {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	page, err := h.Store.ListAuthors(ctx, pageReq)
+	if err != nil {
+		respondWithListError(w, err)
+		return
+	}
+
+	respondWithPage(w, r, page, page.NextCursor)
+}
+
+func (h *AuthorHandler) listAuthorBooks(w http.ResponseWriter, r *http.Request, id int) {
+	ctx := r.Context()
+
+	if !h.Store.AuthorExists(id) {
+		response.RespondWithError(w, http.StatusNotFound, "Author not found")
+		return
+	}
+
+	pageReq, ok := parsePageRequest(r)
+	if !ok {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	page, err := h.Books.ListAuthorBooks(ctx, id, pageReq)
 	if err != nil {
-		response.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithListError(w, err)
 		return
 	}
 
-	response.RespondWithJSON(w, http.StatusOK, authors)
+	respondWithPage(w, r, page, page.NextCursor)
 }
 
 func (h *AuthorHandler) updateAuthor(w http.ResponseWriter, r *http.Request, id int) {
@@ -122,12 +221,57 @@ func (h *AuthorHandler) updateAuthor(w http.ResponseWriter, r *http.Request, id
 func (h *AuthorHandler) deleteAuthor(w http.ResponseWriter, r *http.Request, id int) {
 	ctx := r.Context()
 
-	err := h.Store.DeleteAuthor(ctx, id)
+	// Books crediting the author block the deletion unless cascade=true
+	// removes the credits (and books left without an author) or reassign_to
+	// moves them to another author.
+	var deletion models.AuthorDeletion
+	if s := r.URL.Query().Get("cascade"); s != "" {
+		cascade, err := strconv.ParseBool(s)
+		if err != nil {
+			response.RespondWithError(w, http.StatusBadRequest, "Invalid cascade")
+			return
+		}
+		deletion.Cascade = cascade
+	}
+	if s := r.URL.Query().Get("reassign_to"); s != "" {
+		target, ok := parseID(ctx, h.IDs, "authors", s)
+		if !ok || target == id || !h.Store.AuthorExists(target) {
+			response.RespondWithError(w, http.StatusBadRequest, "Reassignment author not found")
+			return
+		}
+		deletion.ReassignTo = &target
+	}
+
+	var credited []models.Book
+	if deletion.Cascade && deletion.ReassignTo == nil {
+		page, err := h.Books.ListAuthorBooks(ctx, id, models.PageRequest{})
+		if err != nil {
+			response.RespondWithError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		credited = page.Items
+	}
+
+	err := h.Store.DeleteAuthor(ctx, id, deletion)
+	if errors.Is(err, store.ErrAuthorHasBooks) {
+		response.RespondWithError(w, http.StatusConflict,
+			"Author is still credited on books; use cascade=true or reassign_to")
+		return
+	}
 	if err != nil {
 		response.RespondWithError(w, http.StatusNotFound, "Author not found")
 		return
 	}
 
+	for _, book := range credited {
+		if h.Books.BookExists(book.ID) {
+			continue
+		}
+		if err := h.Covers.Delete(book.ID); err != nil {
+			log.Printf("Failed to remove cover of book %d: %v", book.ID, err)
+		}
+	}
+
 	response.RespondWithJSON(w, http.StatusOK, "Author deleted successfully")
 }
 
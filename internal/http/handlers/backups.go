@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"Book-Store/internal/response"
+	"Book-Store/internal/store"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+)
+
+type BackupHandler struct {
+	Store store.BackupStore
+}
+
+func (h *BackupHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	path = strings.TrimSpace(path)
+	pathParts := strings.Split(path, "/")
+
+	if len(pathParts) == 4 && pathParts[3] == "restore" {
+		if r.Method == http.MethodPost {
+			h.restoreBackup(w, r, pathParts[2])
+			return
+		}
+		response.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	if len(pathParts) == 2 {
+		if r.Method == http.MethodGet {
+			h.listBackups(w, r)
+			return
+		}
+		response.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	response.RespondWithError(w, http.StatusNotFound, "Not found")
+}
+
+func (h *BackupHandler) listBackups(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	backups, err := h.Store.ListBackups(ctx)
+	if err != nil {
+		response.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.RespondWithJSON(w, http.StatusOK, backups)
+}
+
+func (h *BackupHandler) restoreBackup(w http.ResponseWriter, r *http.Request, name string) {
+	ctx := r.Context()
+
+	if err := h.Store.RestoreBackup(ctx, name); err != nil {
+		if errors.Is(err, store.ErrBackupNotFound) {
+			response.RespondWithError(w, http.StatusNotFound, "Backup not found")
+			return
+		}
+		log.Printf("Restoring backup %s failed: %v", name, err)
+		response.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Printf("Store restored from backup %s", name)
+	response.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Store restored from " + name})
+}
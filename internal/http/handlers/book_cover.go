@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"Book-Store/internal/covers"
+	"Book-Store/internal/response"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+)
+
+// multipartOverhead allows for the multipart framing around an uploaded
+// cover on top of the image itself.
+const multipartOverhead = 64 << 10
+
+// uploadCover takes a JPEG or PNG image in the "cover" field of a multipart
+// form and replaces the cover of the book with it.
+func (h *BookHandler) uploadCover(w http.ResponseWriter, r *http.Request, id int) {
+	ctx := r.Context()
+
+	if !h.BookStore.BookExists(id) {
+		response.RespondWithError(w, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.MaxCoverBytes+multipartOverhead)
+	if err := r.ParseMultipartForm(h.MaxCoverBytes); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.RespondWithError(w, http.StatusRequestEntityTooLarge, "Cover image is too large")
+			return
+		}
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid multipart upload")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("cover")
+	if err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, "Missing cover file")
+		return
+	}
+	defer file.Close()
+
+	if header.Size > h.MaxCoverBytes {
+		response.RespondWithError(w, http.StatusRequestEntityTooLarge, "Cover image is too large")
+		return
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid multipart upload")
+		return
+	}
+
+	cover, err := h.Covers.Save(id, data)
+	if errors.Is(err, covers.ErrUnsupportedImage) {
+		response.RespondWithError(w, http.StatusUnsupportedMediaType, err.Error())
+		return
+	}
+	if errors.Is(err, covers.ErrImageTooLarge) {
+		response.RespondWithError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to store cover of book %d: %v", id, err)
+		response.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	book, err := h.BookStore.SetBookCover(ctx, id, &cover)
+	if err != nil {
+		// The book went away while the images were written.
+		h.Covers.Delete(id)
+		response.RespondWithError(w, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	response.RespondWithJSON(w, http.StatusOK, book)
+}
+
+// getCover serves one size of a book cover. Clients may cache it for an
+// hour and then revalidate it against its ETag or modification time.
+func (h *BookHandler) getCover(w http.ResponseWriter, r *http.Request, id int) {
+	ctx := r.Context()
+
+	size := r.URL.Query().Get("size")
+	if size == "" {
+		size = covers.SizeOriginal
+	}
+	if !covers.IsValidSize(size) {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid size, expected thumbnail, medium or original")
+		return
+	}
+
+	book, err := h.BookStore.GetBook(ctx, id)
+	if err != nil {
+		response.RespondWithError(w, http.StatusNotFound, "Book not found")
+		return
+	}
+	if book.Cover == nil {
+		response.RespondWithError(w, http.StatusNotFound, "Book has no cover")
+		return
+	}
+
+	file, err := h.Covers.Open(id, *book.Cover, size)
+	if err != nil {
+		log.Printf("Cover of book %d is missing: %v", id, err)
+		response.RespondWithError(w, http.StatusNotFound, "Book has no cover")
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", "image/"+book.Cover.Format)
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Header().Set("ETag", fmt.Sprintf(`"%d-%s-%d"`, id, size, book.Cover.UpdatedAt.UnixNano()))
+	http.ServeContent(w, r, "", book.Cover.UpdatedAt, file)
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"Book-Store/internal/covers"
+	"Book-Store/internal/models"
+	"Book-Store/internal/store/storetest"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newCoverTestHandler returns a handler with one book and the directory its
+// covers are kept in.
+func newCoverTestHandler(t *testing.T) (*BookHandler, models.Book, string) {
+	t.Helper()
+	ctx := context.Background()
+	s := storetest.NewMemStore(t)
+	author, err := s.CreateAuthor(ctx, models.Author{FirstName: "Octavia E.", LastName: "Butler"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	book, err := s.CreateBook(ctx, models.Book{Title: "Kindred", Author: author})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	h := &BookHandler{BookStore: s, AuthorStore: s, Tx: s, Covers: covers.NewCoverStore(dir), MaxCoverBytes: 64 << 10}
+	return h, book, dir
+}
+
+func coverPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func uploadCover(t *testing.T, h *BookHandler, bookID int, data []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("cover", "cover.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(data)
+	form.Close()
+
+	r := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/books/%d/cover", bookID), &body)
+	r.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestUploadCover(t *testing.T) {
+	tests := []struct {
+		name   string
+		bookID func(book models.Book) int
+		data   []byte
+		want   int
+	}{
+		{"PNG", nil, coverPNG(t, 600, 900), http.StatusOK},
+		{"not an image", nil, []byte("%PDF-1.7 not a cover"), http.StatusUnsupportedMediaType},
+		// Random bytes don't compress, so the file stays over the limit.
+		{"over the size limit", nil, noise(128 << 10), http.StatusRequestEntityTooLarge},
+		{"unknown book", func(b models.Book) int { return b.ID + 100 }, coverPNG(t, 10, 10), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, book, dir := newCoverTestHandler(t)
+			id := book.ID
+			if tt.bookID != nil {
+				id = tt.bookID(book)
+			}
+
+			w := uploadCover(t, h, id, tt.data)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.want != http.StatusOK {
+				if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+					t.Errorf("refused upload left %d entries in the cover directory", len(entries))
+				}
+				return
+			}
+
+			var got models.Book
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Cover == nil || got.Cover.Width != 600 || got.Cover.Height != 900 || got.Cover.Format != "png" {
+				t.Errorf("book cover = %+v, want a 600x900 png", got.Cover)
+			}
+		})
+	}
+}
+
+// noise returns n bytes that start like a PNG but don't compress.
+func noise(n int) []byte {
+	data := make([]byte, n)
+	state := uint32(1)
+	for i := range data {
+		state = state*1664525 + 1013904223
+		data[i] = byte(state >> 24)
+	}
+	copy(data, "\x89PNG\r\n\x1a\n")
+	return data
+}
+
+func TestGetCover(t *testing.T) {
+	h, book, dir := newCoverTestHandler(t)
+	if w := uploadCover(t, h, book.ID, coverPNG(t, 600, 900)); w.Code != http.StatusOK {
+		t.Fatalf("upload status = %d: %s", w.Code, w.Body)
+	}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/books/%d/cover%s", book.ID, query), nil))
+		return w
+	}
+
+	w := get("?size=thumbnail")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	if got := w.Header().Get("Content-Type"); got != "image/png" {
+		t.Errorf("Content-Type = %q, want image/png", got)
+	}
+	config, err := png.DecodeConfig(w.Body)
+	if err != nil || config.Width != 160 || config.Height != 240 {
+		t.Errorf("thumbnail = %dx%d, %v; want 160x240", config.Width, config.Height, err)
+	}
+
+	if w := get("?size=huge"); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown size: status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	// Deleting the book takes its cover files along.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/books/%d", book.ID), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("delete status = %d: %s", w.Code, w.Body)
+	}
+	if _, err := os.Stat(filepath.Join(dir, fmt.Sprint(book.ID))); !os.IsNotExist(err) {
+		t.Errorf("cover files survived the book: %v", err)
+	}
+}
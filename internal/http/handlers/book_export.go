@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"Book-Store/internal/models"
+	"Book-Store/internal/response"
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportBatchSize is how many books an export reads from the store at a time.
+const exportBatchSize = maxPageLimit
+
+// catalogWriter writes books in one export format. begin and end frame the
+// books; flush pushes out what has been written so far.
+type catalogWriter interface {
+	begin() error
+	book(book models.Book) error
+	end() error
+	flush() error
+}
+
+// exportBooks streams every book matching the search filters, in ID order and
+// a batch at a time, as CSV, JSON Lines or an ONIX 3.0 message. CSV and JSON
+// Lines rows have the layout of an import.
+func (h *BookHandler) exportBooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var (
+		catalog     catalogWriter
+		contentType string
+		filename    string
+	)
+	switch format := r.URL.Query().Get("format"); format {
+	case "", importFormatCSV:
+		catalog = &csvCatalog{w: csv.NewWriter(w)}
+		contentType, filename = "text/csv; charset=utf-8", "catalog.csv"
+	case importFormatJSONL:
+		catalog = &jsonlCatalog{enc: json.NewEncoder(w)}
+		contentType, filename = "application/x-ndjson", "catalog.jsonl"
+	case "onix":
+		catalog = newONIXCatalog(w, h.ExportSender, h.Currency)
+		contentType, filename = "application/xml; charset=utf-8", "catalog.xml"
+	default:
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid format, expected csv, jsonl or onix")
+		return
+	}
+
+	criteria, err := h.searchCriteria(r)
+	if err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Nothing is written before the first batch is read, so a failing
+	// search still gets an error response.
+	started := false
+	start := func() error {
+		started = true
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		w.WriteHeader(http.StatusOK)
+		return catalog.begin()
+	}
+
+	err = h.BookStore.ScanBooks(ctx, criteria, exportBatchSize, func(books []models.Book) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		for _, book := range books {
+			if err := catalog.book(book); err != nil {
+				return err
+			}
+		}
+		if err := catalog.flush(); err != nil {
+			return err
+		}
+		http.NewResponseController(w).Flush()
+		return nil
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err != nil {
+		if !started {
+			response.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+		log.Printf("Catalog export stopped: %v", err)
+		return
+	}
+
+	if err := catalog.end(); err != nil {
+		log.Printf("Catalog export failed: %v", err)
+	}
+}
+
+// exportRow lays a book out as an import row. Contributors other than
+// authors are left out.
+func exportRow(book models.Book) bookImportRow {
+	row := bookImportRow{
+		Title:     book.Title,
+		Authors:   make([]string, 0, 1),
+		Genres:    book.Genres,
+		Price:     book.Price,
+		Stock:     book.Stock,
+		ISBN:      book.ISBN,
+		Publisher: book.Publisher,
+		Language:  book.Language,
+		PageCount: book.PageCount,
+		Format:    book.Format,
+		Edition:   book.Edition,
+	}
+	if !book.PublishedAt.IsZero() {
+		row.PublishedAt = book.PublishedAt.Format(time.DateOnly)
+	}
+
+	for _, c := range book.Contributors {
+		if c.Role == models.ContributorAuthor {
+			row.Authors = append(row.Authors, authorName(c.Author))
+		}
+	}
+	if len(row.Authors) == 0 {
+		row.Authors = append(row.Authors, authorName(book.Author))
+	}
+	return row
+}
+
+func authorName(author models.Author) string {
+	return strings.TrimSpace(author.FirstName + " " + author.LastName)
+}
+
+type csvCatalog struct {
+	w *csv.Writer
+}
+
+func (c *csvCatalog) begin() error {
+	return c.w.Write(importColumns)
+}
+
+func (c *csvCatalog) book(book models.Book) error {
+	row := exportRow(book)
+	return c.w.Write([]string{
+		row.Title,
+		strings.Join(row.Authors, ";"),
+		strings.Join(row.Genres, ";"),
+		row.PublishedAt,
+		strconv.FormatFloat(row.Price, 'f', -1, 64),
+		strconv.Itoa(row.Stock),
+		row.ISBN,
+		row.Publisher,
+		row.Language,
+		strconv.Itoa(row.PageCount),
+		row.Format,
+		row.Edition,
+	})
+}
+
+func (c *csvCatalog) end() error {
+	return c.flush()
+}
+
+func (c *csvCatalog) flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonlCatalog struct {
+	enc *json.Encoder
+}
+
+func (c *jsonlCatalog) begin() error { return nil }
+
+func (c *jsonlCatalog) book(book models.Book) error {
+	return c.enc.Encode(exportRow(book))
+}
+
+func (c *jsonlCatalog) end() error { return nil }
+
+func (c *jsonlCatalog) flush() error { return nil }
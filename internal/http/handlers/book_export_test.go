@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"Book-Store/internal/models"
+	"Book-Store/internal/store"
+	"Book-Store/internal/store/storetest"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// exportTestStore holds more books than fit in one export batch.
+func exportTestStore(t *testing.T) *store.MemStore {
+	t.Helper()
+	ctx := context.Background()
+	s := storetest.NewMemStore(t)
+
+	author, err := s.CreateAuthor(ctx, models.Author{FirstName: "Octavia E.", LastName: "Butler"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range exportBatchSize + 5 {
+		genre := "fantasy"
+		if i%2 == 1 {
+			genre = "science fiction"
+		}
+		book := models.Book{
+			Title:  fmt.Sprintf("Book %03d", i),
+			Author: author,
+			Genres: []string{genre},
+			Price:  float64(i),
+			Stock:  i % 3,
+		}
+		if _, err := s.CreateBook(ctx, book); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+// allBooks reads every book of s by title.
+func allBooks(t *testing.T, s store.BookStore) map[string]models.Book {
+	t.Helper()
+	books := make(map[string]models.Book)
+	criteria := models.SearchCriteria{Limit: maxPageLimit}
+	for {
+		result, err := s.SearchBooks(context.Background(), criteria)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, book := range result.Books {
+			books[book.Title] = book
+		}
+		if result.NextCursor == "" {
+			return books
+		}
+		criteria.Cursor = result.NextCursor
+	}
+}
+
+func exportBody(t *testing.T, h *BookHandler, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	src := exportTestStore(t)
+	exporter := &BookHandler{BookStore: src, AuthorStore: src, Tx: src}
+
+	tests := []struct {
+		format      string
+		contentType string
+		query       string
+		wantBooks   int
+	}{
+		{"csv", "text/csv", "", exportBatchSize + 5},
+		{"jsonl", "application/x-ndjson", "", exportBatchSize + 5},
+		{"csv", "text/csv", "&genre=science+fiction", (exportBatchSize + 5) / 2},
+		// Every third book is out of stock.
+		{"jsonl", "application/x-ndjson", "&in_stock=true", exportBatchSize + 5 - (exportBatchSize+5+2)/3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format+tt.query, func(t *testing.T) {
+			w := exportBody(t, exporter, "/books/export?format="+tt.format+tt.query)
+			if w.Code != http.StatusOK {
+				t.Fatalf("export status = %d: %s", w.Code, w.Body)
+			}
+			if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.contentType) {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+
+			dst := storetest.NewMemStore(t)
+			importer := &BookHandler{BookStore: dst, AuthorStore: dst, Tx: dst, MaxImportBytes: 1 << 20}
+			r := httptest.NewRequest(http.MethodPost, "/books/import", w.Body)
+			r.Header.Set("Content-Type", tt.contentType)
+			iw := httptest.NewRecorder()
+			importer.ServeHTTP(iw, r)
+
+			var report models.BookImportReport
+			if err := json.NewDecoder(iw.Body).Decode(&report); err != nil {
+				t.Fatalf("import status = %d: %v", iw.Code, err)
+			}
+			if report.Created != tt.wantBooks || report.Rejected != 0 || report.AuthorsCreated != 1 {
+				t.Errorf("import created %d, rejected %d, authors %d; want %d, 0, 1",
+					report.Created, report.Rejected, report.AuthorsCreated, tt.wantBooks)
+			}
+
+			// Every imported book matches the one it was exported from.
+			want := allBooks(t, src)
+			for title, book := range allBooks(t, dst) {
+				w := want[title]
+				if book.Price != w.Price || book.Stock != w.Stock || !slices.Equal(book.Genres, w.Genres) ||
+					book.Author.FirstName != w.Author.FirstName || book.Author.LastName != w.Author.LastName {
+					t.Errorf("imported %q as %+v, want %+v", title, book, w)
+				}
+			}
+		})
+	}
+}
+
+func TestExportONIX(t *testing.T) {
+	src := exportTestStore(t)
+	h := &BookHandler{BookStore: src, Tx: src, ExportSender: "Anarres Books", Currency: "EUR"}
+
+	w := exportBody(t, h, "/books/export?format=onix")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	body := w.Body.String()
+	for _, want := range []string{"<ONIXMessage", "Anarres Books", "<CurrencyCode>EUR</CurrencyCode>", "</ONIXMessage>"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("ONIX export is missing %q", want)
+		}
+	}
+	if got := strings.Count(body, "<Product>"); got != exportBatchSize+5 {
+		t.Errorf("ONIX export holds %d products, want %d", got, exportBatchSize+5)
+	}
+}
+
+func TestExportInvalidFormat(t *testing.T) {
+	src := storetest.NewMemStore(t)
+	h := &BookHandler{BookStore: src, Tx: src}
+
+	if w := exportBody(t, h, "/books/export?format=xlsx"); w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,455 @@
+package handlers
+
+import (
+	"Book-Store/internal/models"
+	"Book-Store/internal/response"
+	"Book-Store/internal/store"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	importFormatCSV   = "csv"
+	importFormatJSONL = "jsonl"
+)
+
+// errImportDryRun rolls back the import transaction of a dry run.
+var errImportDryRun = errors.New("dry run")
+
+// bookImportRow is one record of an import file. CSV files name these fields
+// in their header row and separate several authors or genres with ";".
+type bookImportRow struct {
+	Title       string   `json:"title"`
+	Authors     []string `json:"authors"`
+	Genres      []string `json:"genres"`
+	PublishedAt string   `json:"published_at"`
+	Price       float64  `json:"price"`
+	Stock       int      `json:"stock"`
+	ISBN        string   `json:"isbn"`
+	Publisher   string   `json:"publisher"`
+	Language    string   `json:"language"`
+	PageCount   int      `json:"page_count"`
+	Format      string   `json:"format"`
+	Edition     string   `json:"edition"`
+}
+
+// parsedImportRow is a row as read from the file; err is set when the row
+// could not be read.
+type parsedImportRow struct {
+	row bookImportRow
+	err error
+}
+
+// importBooks loads a CSV or JSON Lines file in one transaction. Rows are
+// matched to existing books by ISBN and updated, otherwise created; authors
+// are matched by full name and created when unknown. Invalid rows are
+// rejected without stopping the rest of the import.
+func (h *BookHandler) importBooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	dryRun := false
+	if s := r.URL.Query().Get("dry_run"); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			response.RespondWithError(w, http.StatusBadRequest, "Invalid dry_run")
+			return
+		}
+		dryRun = b
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.MaxImportBytes)
+
+	var rows []parsedImportRow
+	var err error
+	switch importFormat(r) {
+	case importFormatCSV:
+		rows, err = readCSVImport(r.Body)
+	case importFormatJSONL:
+		rows, err = readJSONLImport(r.Body)
+	default:
+		response.RespondWithError(w, http.StatusUnsupportedMediaType,
+			"Import must be CSV (text/csv) or JSON Lines (application/x-ndjson)")
+		return
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		response.RespondWithError(w, http.StatusRequestEntityTooLarge, "Import file is too large")
+		return
+	}
+	if err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report := models.BookImportReport{
+		DryRun: dryRun,
+		Rows:   make([]models.BookImportResult, 0, len(rows)),
+	}
+	err = h.Tx.WithTx(ctx, func(tx store.Stores) error {
+		authors, err := authorsByName(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		for i, parsed := range rows {
+			result := importRow(ctx, tx, authors, parsed, &report)
+			result.Row = i + 1
+			if dryRun {
+				result.BookID, result.PublicID = nil, ""
+			}
+			report.Rows = append(report.Rows, result)
+		}
+
+		if dryRun {
+			return errImportDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportDryRun) {
+		response.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	for _, result := range report.Rows {
+		switch result.Status {
+		case models.ImportCreated:
+			report.Created++
+		case models.ImportUpdated:
+			report.Updated++
+		default:
+			report.Rejected++
+		}
+	}
+
+	response.RespondWithJSON(w, http.StatusOK, report)
+}
+
+// importFormat takes the format query parameter, falling back to the
+// Content-Type of the request.
+func importFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return strings.ToLower(format)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		return importFormatCSV
+	case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return importFormatJSONL
+	default:
+		return ""
+	}
+}
+
+// importRow writes a single row through tx and records any authors it had
+// to create in authors and report. The row is written in a nested
+// transaction, so a rejected row leaves no authors behind.
+func importRow(ctx context.Context, tx store.Stores, authors map[string]models.Author,
+	parsed parsedImportRow, report *models.BookImportReport) models.BookImportResult {
+	result := models.BookImportResult{Status: models.ImportRejected, Title: parsed.row.Title}
+	if parsed.err != nil {
+		result.Error = parsed.err.Error()
+		return result
+	}
+
+	book, names, err := parsed.row.book()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	created := make(map[string]models.Author)
+	var saved models.Book
+	err = tx.WithTx(ctx, func(tx store.Stores) error {
+		var err error
+		for _, name := range names {
+			author, known := authors[strings.ToLower(name)]
+			if !known {
+				if author, err = tx.CreateAuthor(ctx, authorNamed(name)); err != nil {
+					return err
+				}
+				created[strings.ToLower(name)] = author
+			}
+			book.Contributors = append(book.Contributors,
+				models.Contributor{Author: author, Role: models.ContributorAuthor})
+		}
+		book.Author = book.Contributors[0].Author
+
+		var existing models.Book
+		found := false
+		if book.ISBN != "" {
+			existing, err = tx.GetBookByISBN(ctx, book.ISBN)
+			found = err == nil
+		}
+
+		if found {
+			book.Series = existing.Series
+			saved, err = tx.UpdateBook(ctx, existing.ID, book)
+			result.Status = models.ImportUpdated
+		} else {
+			saved, err = tx.CreateBook(ctx, book)
+			result.Status = models.ImportCreated
+		}
+		return err
+	})
+	if err != nil {
+		result.Status = models.ImportRejected
+		result.Error = err.Error()
+		return result
+	}
+
+	for name, author := range created {
+		authors[name] = author
+		report.AuthorsCreated++
+	}
+
+	result.BookID = &saved.ID
+	result.PublicID = saved.PublicID
+	return result
+}
+
+// book validates the row and turns it into a book without contributors,
+// returning the distinct author names to credit in their stead.
+func (row bookImportRow) book() (models.Book, []string, error) {
+	title := strings.TrimSpace(row.Title)
+	if title == "" {
+		return models.Book{}, nil, errors.New("title is required")
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range row.Authors {
+		name = strings.Join(strings.Fields(name), " ")
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return models.Book{}, nil, errors.New("at least one author is required")
+	}
+
+	if row.Price < 0 {
+		return models.Book{}, nil, errors.New("price cannot be negative")
+	}
+	if row.Stock < 0 {
+		return models.Book{}, nil, errors.New("stock cannot be negative")
+	}
+
+	var publishedAt time.Time
+	if s := strings.TrimSpace(row.PublishedAt); s != "" {
+		var err error
+		if publishedAt, err = time.Parse(time.DateOnly, s); err != nil {
+			if publishedAt, err = time.Parse(time.RFC3339, s); err != nil {
+				return models.Book{}, nil, fmt.Errorf("invalid published_at %q, expected YYYY-MM-DD", s)
+			}
+		}
+	}
+
+	var genres []string
+	for _, genre := range row.Genres {
+		if genre = strings.TrimSpace(genre); genre != "" {
+			genres = append(genres, genre)
+		}
+	}
+
+	book := models.Book{
+		Title:       title,
+		Genres:      genres,
+		PublishedAt: publishedAt,
+		Price:       row.Price,
+		Stock:       row.Stock,
+		ISBN:        row.ISBN,
+		Publisher:   strings.TrimSpace(row.Publisher),
+		Language:    row.Language,
+		PageCount:   row.PageCount,
+		Format:      row.Format,
+		Edition:     strings.TrimSpace(row.Edition),
+	}
+	if err := book.NormalizeMetadata(); err != nil {
+		return models.Book{}, nil, err
+	}
+	return book, names, nil
+}
+
+// authorsByName indexes every author by their lowercased full name.
+func authorsByName(ctx context.Context, tx store.Stores) (map[string]models.Author, error) {
+	page, err := tx.ListAuthors(ctx, models.PageRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	authors := make(map[string]models.Author, len(page.Items))
+	for _, author := range page.Items {
+		name := strings.Join(strings.Fields(author.FirstName+" "+author.LastName), " ")
+		authors[strings.ToLower(name)] = author
+	}
+	return authors, nil
+}
+
+// authorNamed splits a full name into a last name, its final word, and
+// first names.
+func authorNamed(name string) models.Author {
+	words := strings.Fields(name)
+	return models.Author{
+		FirstName: strings.Join(words[:len(words)-1], " "),
+		LastName:  words[len(words)-1],
+	}
+}
+
+func readCSVImport(body io.Reader) ([]parsedImportRow, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV import is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	columns := make([]string, len(header))
+	hasColumn := make(map[string]bool)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		if !slices.Contains(importColumns, name) {
+			return nil, fmt.Errorf("unknown CSV column %q", name)
+		}
+		columns[i] = name
+		hasColumn[name] = true
+	}
+	if !hasColumn["title"] || !hasColumn["authors"] {
+		return nil, errors.New("CSV header must include title and authors")
+	}
+
+	rows := make([]parsedImportRow, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			rows = append(rows, parsedImportRow{err: parseErr.Err})
+			continue
+		}
+		rows = append(rows, csvImportRow(columns, record))
+	}
+	return rows, nil
+}
+
+// importColumns are the CSV columns of an import, which is also the layout
+// of a CSV export.
+var importColumns = []string{
+	"title", "authors", "genres", "published_at", "price", "stock",
+	"isbn", "publisher", "language", "page_count", "format", "edition",
+}
+
+func csvImportRow(columns, record []string) parsedImportRow {
+	var parsed parsedImportRow
+	row := &parsed.row
+	for i, value := range record {
+		value = strings.TrimSpace(value)
+		var err error
+		switch columns[i] {
+		case "title":
+			row.Title = value
+		case "authors":
+			row.Authors = splitImportList(value)
+		case "genres":
+			row.Genres = splitImportList(value)
+		case "published_at":
+			row.PublishedAt = value
+		case "price":
+			row.Price, err = parseImportFloat(value)
+		case "stock":
+			row.Stock, err = parseImportInt(value)
+		case "isbn":
+			row.ISBN = value
+		case "publisher":
+			row.Publisher = value
+		case "language":
+			row.Language = value
+		case "page_count":
+			row.PageCount, err = parseImportInt(value)
+		case "format":
+			row.Format = value
+		case "edition":
+			row.Edition = value
+		}
+		if err != nil && parsed.err == nil {
+			parsed.err = fmt.Errorf("invalid %s %q", columns[i], value)
+		}
+	}
+	return parsed
+}
+
+func splitImportList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ";")
+}
+
+func parseImportFloat(value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+func parseImportInt(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// readJSONLImport reads one JSON object per line; blank lines are skipped
+// and do not count as rows.
+func readJSONLImport(body io.Reader) ([]parsedImportRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	rows := make([]parsedImportRow, 0)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var parsed parsedImportRow
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&parsed.row); err != nil {
+			parsed = parsedImportRow{err: fmt.Errorf("invalid JSON: %v", err)}
+		}
+		rows = append(rows, parsed)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
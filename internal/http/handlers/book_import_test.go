@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"Book-Store/internal/models"
+	"Book-Store/internal/store"
+	"Book-Store/internal/store/storetest"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// rejectingStores fails to create books with the given title, after the
+// import has already created their authors.
+type rejectingStores struct {
+	store.Stores
+	title string
+}
+
+func (s rejectingStores) WithTx(ctx context.Context, fn func(tx store.Stores) error) error {
+	return s.Stores.WithTx(ctx, func(tx store.Stores) error {
+		return fn(rejectingStores{Stores: tx, title: s.title})
+	})
+}
+
+func (s rejectingStores) CreateBook(ctx context.Context, book models.Book) (models.Book, error) {
+	if book.Title == s.title {
+		return models.Book{}, errors.New("rejected by store")
+	}
+	return s.Stores.CreateBook(ctx, book)
+}
+
+const importCSV = `title,authors,price,stock,isbn
+Dune,Frank Herbert,9.99,4,9780441013593
+Use of Weapons,Iain Banks,8.5,2,
+,Nobody,1,1,
+Dune,Frank Herbert;Brian Herbert,12,6,0-441-01359-7
+`
+
+func TestImportBooks(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		maxBytes    int64
+		wantStatus  int
+		wantRows    []string
+		wantAuthors []string
+	}{
+		{
+			name:        "rejected rows leave no authors behind",
+			target:      "/books/import",
+			contentType: "text/csv",
+			body:        importCSV,
+			wantStatus:  http.StatusOK,
+			wantRows:    []string{models.ImportCreated, models.ImportRejected, models.ImportRejected, models.ImportUpdated},
+			wantAuthors: []string{"Herbert", "Herbert"},
+		},
+		{
+			name:        "dry run",
+			target:      "/books/import?dry_run=true",
+			contentType: "text/csv",
+			body:        importCSV,
+			wantStatus:  http.StatusOK,
+			wantRows:    []string{models.ImportCreated, models.ImportRejected, models.ImportRejected, models.ImportUpdated},
+		},
+		{
+			name:        "JSON Lines",
+			target:      "/books/import",
+			contentType: "application/x-ndjson",
+			body:        `{"title":"Excession","authors":["Iain M. Banks"],"price":7}` + "\n",
+			wantStatus:  http.StatusOK,
+			wantRows:    []string{models.ImportCreated},
+			wantAuthors: []string{"Banks"},
+		},
+		{
+			name:        "too large",
+			target:      "/books/import",
+			contentType: "text/csv",
+			body:        importCSV,
+			maxBytes:    16,
+			wantStatus:  http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:        "unknown format",
+			target:      "/books/import",
+			contentType: "application/json",
+			body:        `[]`,
+			wantStatus:  http.StatusUnsupportedMediaType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := storetest.NewMemStore(t)
+			tx := rejectingStores{Stores: s, title: "Use of Weapons"}
+			h := &BookHandler{BookStore: s, AuthorStore: s, Tx: tx, MaxImportBytes: 1 << 20}
+			if tt.maxBytes > 0 {
+				h.MaxImportBytes = tt.maxBytes
+			}
+
+			r := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var report models.BookImportReport
+			if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+				t.Fatal(err)
+			}
+			var statuses []string
+			for _, row := range report.Rows {
+				statuses = append(statuses, row.Status)
+			}
+			if !slices.Equal(statuses, tt.wantRows) {
+				t.Errorf("row statuses = %v, want %v", statuses, tt.wantRows)
+			}
+
+			authors, err := s.ListAuthors(ctx, models.PageRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, a := range authors.Items {
+				names = append(names, a.LastName)
+			}
+			if !slices.Equal(names, tt.wantAuthors) {
+				t.Errorf("authors = %v, want %v", names, tt.wantAuthors)
+			}
+			if report.AuthorsCreated != len(tt.wantAuthors) && !report.DryRun {
+				t.Errorf("authors_created = %d, want %d", report.AuthorsCreated, len(tt.wantAuthors))
+			}
+		})
+	}
+}
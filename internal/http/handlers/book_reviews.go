@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"Book-Store/internal/http/middleware"
+	"Book-Store/internal/models"
+	"Book-Store/internal/response"
+	"Book-Store/internal/store"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// serveReviews handles /books/{id}/reviews and /books/{id}/reviews/{rid}.
+func (h *BookHandler) serveReviews(w http.ResponseWriter, r *http.Request, bookID int, rawReviewID string) {
+	if rawReviewID == "" {
+		switch r.Method {
+		case http.MethodGet:
+			h.listReviews(w, r, bookID)
+		case http.MethodPost:
+			h.createReview(w, r, bookID)
+		default:
+			response.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+		return
+	}
+
+	id, ok := parseID(r.Context(), h.IDs, "reviews", rawReviewID)
+	if !ok {
+		response.RespondWithError(w, http.StatusNotFound, "Review not found")
+		return
+	}
+
+	// Reviews are only reachable under the book they are about.
+	review, err := h.ReviewStore.GetReview(r.Context(), id)
+	if err != nil || review.BookID != bookID {
+		response.RespondWithError(w, http.StatusNotFound, "Review not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		response.RespondWithJSON(w, http.StatusOK, review)
+	case http.MethodPut:
+		h.updateReview(w, r, review)
+	case http.MethodDelete:
+		h.deleteReview(w, r, review)
+	default:
+		response.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (h *BookHandler) listReviews(w http.ResponseWriter, r *http.Request, bookID int) {
+	ctx := r.Context()
+
+	if !h.BookStore.BookExists(bookID) {
+		response.RespondWithError(w, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	pageReq, ok := parsePageRequest(r)
+	if !ok {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	result, err := h.ReviewStore.ListBookReviews(ctx, bookID, pageReq)
+	if err != nil {
+		respondWithListError(w, err)
+		return
+	}
+
+	respondWithPage(w, r, result, result.NextCursor)
+}
+
+// createReview lets a customer review a book from one of their completed
+// orders, once.
+func (h *BookHandler) createReview(w http.ResponseWriter, r *http.Request, bookID int) {
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	var review models.Review
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := review.Normalize(); err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !h.BookStore.BookExists(bookID) {
+		response.RespondWithError(w, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	review.BookID = bookID
+	review.CustomerID = middleware.GetUserIDFromContext(ctx)
+
+	created, err := h.ReviewStore.CreateReview(ctx, review)
+	if errors.Is(err, store.ErrReviewNotAllowed) {
+		response.RespondWithError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if errors.Is(err, store.ErrDuplicateReview) {
+		response.RespondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.RespondWithJSON(w, http.StatusCreated, created)
+}
+
+// updateReview is reserved to the author of the review.
+func (h *BookHandler) updateReview(w http.ResponseWriter, r *http.Request, existing models.Review) {
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	if existing.CustomerID != middleware.GetUserIDFromContext(ctx) {
+		response.RespondWithError(w, http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	var review models.Review
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if err := review.Normalize(); err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	updated, err := h.ReviewStore.UpdateReview(ctx, existing.ID, review)
+	if err != nil {
+		response.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	response.RespondWithJSON(w, http.StatusOK, updated)
+}
+
+// deleteReview lets authors withdraw their reviews and staff moderate them.
+func (h *BookHandler) deleteReview(w http.ResponseWriter, r *http.Request, review models.Review) {
+	ctx := r.Context()
+
+	if review.CustomerID != middleware.GetUserIDFromContext(ctx) &&
+		!middleware.HasRole(ctx, models.RoleStaff, models.RoleAdmin) {
+		response.RespondWithError(w, http.StatusForbidden, "Forbidden")
+		return
+	}
+
+	if err := h.ReviewStore.DeleteReview(ctx, review.ID); err != nil {
+		response.RespondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	response.RespondWithJSON(w, http.StatusOK, "Review deleted successfully")
+}
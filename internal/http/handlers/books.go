@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"Book-Store/internal/covers"
 	"Book-Store/internal/models"
+	"Book-Store/internal/recommendations"
 	"Book-Store/internal/response"
 	"Book-Store/internal/store"
+	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -14,6 +18,21 @@ import (
 type BookHandler struct {
 	BookStore   store.BookStore
 	AuthorStore store.AuthorStore
+	SeriesStore store.SeriesStore
+	ReviewStore store.ReviewStore
+	Tx          store.Transactor
+	IDs         store.PublicIDResolver
+
+	Recommendations *recommendations.Recommender
+
+	// ExportSender and Currency describe the store in ONIX exports.
+	ExportSender string
+	Currency     string
+
+	Covers        *covers.CoverStore
+	MaxCoverBytes int64
+
+	MaxImportBytes int64
 }
 
 func (h *BookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -21,16 +40,83 @@ func (h *BookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	path = strings.TrimSpace(path)
 	pathParts := strings.Split(path, "/")
 
+	if len(pathParts) == 2 && pathParts[1] == "suggest" {
+		if r.Method != http.MethodGet {
+			response.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		h.suggestBooks(w, r)
+		return
+	}
+
+	if len(pathParts) == 2 && pathParts[1] == "import" {
+		if r.Method != http.MethodPost {
+			response.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		h.importBooks(w, r)
+		return
+	}
+
+	if len(pathParts) == 2 && pathParts[1] == "export" {
+		if r.Method != http.MethodGet {
+			response.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		h.exportBooks(w, r)
+		return
+	}
+
+	if len(pathParts) == 3 && pathParts[1] == "isbn" {
+		if r.Method != http.MethodGet {
+			response.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		h.getBookByISBN(w, r, pathParts[2])
+		return
+	}
+
 	var id int
 	var hasID bool
 
 	if len(pathParts) > 1 && pathParts[1] != "" {
-		idStr := strings.TrimSpace(pathParts[1])
-		parsedID, err := strconv.Atoi(idStr)
-		if err == nil {
-			id = parsedID
-			hasID = true
+		parsedID, ok := parseID(r.Context(), h.IDs, "books", pathParts[1])
+		if !ok {
+			response.RespondWithError(w, http.StatusNotFound, "Book not found")
+			return
+		}
+		id = parsedID
+		hasID = true
+	}
+
+	if hasID && len(pathParts) == 3 && pathParts[2] == "cover" {
+		switch r.Method {
+		case http.MethodPut:
+			h.uploadCover(w, r, id)
+		case http.MethodGet:
+			h.getCover(w, r, id)
+		default:
+			response.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+		return
+	}
+
+	if hasID && len(pathParts) == 3 && pathParts[2] == "recommendations" {
+		if r.Method != http.MethodGet {
+			response.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		h.bookRecommendations(w, r, id)
+		return
+	}
+
+	if hasID && len(pathParts) >= 3 && len(pathParts) <= 4 && pathParts[2] == "reviews" {
+		rawReviewID := ""
+		if len(pathParts) == 4 {
+			rawReviewID = pathParts[3]
 		}
+		h.serveReviews(w, r, id, rawReviewID)
+		return
 	}
 
 	switch r.Method {
@@ -70,13 +156,32 @@ func (h *BookHandler) createBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !h.AuthorStore.AuthorExists(book.Author.ID) {
-		log.Printf("Cannot create book: author %d not found", book.Author.ID)
+	if err := book.NormalizeMetadata(); err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !h.creditsKnownAuthors(ctx, &book) {
+		log.Printf("Cannot create book: contributor not found")
 		response.RespondWithError(w, http.StatusNotFound, "Author not found")
 		return
 	}
 
+	if err := book.NormalizeContributors(); err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !h.placesInKnownSeries(ctx, &book) {
+		response.RespondWithError(w, http.StatusNotFound, "Series not found")
+		return
+	}
+
 	createdBook, err := h.BookStore.CreateBook(ctx, book)
+	if errors.Is(err, store.ErrDuplicateISBN) {
+		response.RespondWithError(w, http.StatusConflict, err.Error())
+		return
+	}
 	if err != nil {
 		response.RespondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -84,43 +189,189 @@ func (h *BookHandler) createBook(w http.ResponseWriter, r *http.Request) {
 	response.RespondWithJSON(w, http.StatusCreated, createdBook)
 }
 
+// creditsKnownAuthors resolves the author references of a book, its
+// contributors or else its single author, and reports whether they all
+// exist.
+func (h *BookHandler) creditsKnownAuthors(ctx context.Context, book *models.Book) bool {
+	if len(book.Contributors) == 0 {
+		return resolveReference(ctx, h.IDs, "authors", book.Author.PublicID, &book.Author.ID) &&
+			h.AuthorStore.AuthorExists(book.Author.ID)
+	}
+
+	for i := range book.Contributors {
+		author := &book.Contributors[i].Author
+		if !resolveReference(ctx, h.IDs, "authors", author.PublicID, &author.ID) ||
+			!h.AuthorStore.AuthorExists(author.ID) {
+			return false
+		}
+	}
+	return true
+}
+
+// placesInKnownSeries resolves the series reference of a book, if any, and
+// reports whether the series exists.
+func (h *BookHandler) placesInKnownSeries(ctx context.Context, book *models.Book) bool {
+	if book.Series == nil {
+		return true
+	}
+	if !resolveReference(ctx, h.IDs, "series", book.Series.PublicID, &book.Series.ID) {
+		return false
+	}
+	_, err := h.SeriesStore.GetSeries(ctx, book.Series.ID)
+	return err == nil
+}
+
 func (h *BookHandler) searchBooks(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+
+	criteria, err := h.searchCriteria(r)
+	if err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	pageReq, ok := parsePageRequest(r)
+	if !ok {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+	criteria.Limit = pageReq.Limit
+	criteria.Cursor = pageReq.Cursor
+
+	result, err := h.BookStore.SearchBooks(ctx, criteria)
+	if err != nil {
+		respondWithListError(w, err)
+		return
+	}
+
+	respondWithPage(w, r, result, result.NextCursor)
+}
+
+// searchCriteria reads the search filters and sort order shared by the
+// book listing and the export.
+func (h *BookHandler) searchCriteria(r *http.Request) (models.SearchCriteria, error) {
+	ctx := r.Context()
+	query := r.URL.Query().Get("q")
 	title := r.URL.Query().Get("title")
 	author := r.URL.Query().Get("author")
-	genre := r.URL.Query().Get("genre")
-	sortBy := r.URL.Query().Get("sort_by")
-	sortOrder := r.URL.Query().Get("sort_order")
 
 	var minPricePtr, maxPricePtr *float64
 	if s := r.URL.Query().Get("min_price"); s != "" {
-		if f, err := strconv.ParseFloat(s, 64); err == nil {
-			minPricePtr = &f
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return models.SearchCriteria{}, errors.New("Invalid min_price")
 		}
+		minPricePtr = &f
 	}
 	if s := r.URL.Query().Get("max_price"); s != "" {
-		if f, err := strconv.ParseFloat(s, 64); err == nil {
-			maxPricePtr = &f
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return models.SearchCriteria{}, errors.New("Invalid max_price")
+		}
+		maxPricePtr = &f
+	}
+
+	// Genres may repeat (genre=a&genre=b) or be comma separated; a book
+	// matches if it has any of them.
+	var genres []string
+	for _, value := range r.URL.Query()["genre"] {
+		for _, genre := range strings.Split(value, ",") {
+			if genre = strings.TrimSpace(genre); genre != "" {
+				genres = append(genres, genre)
+			}
+		}
+	}
+
+	var inStockPtr *bool
+	if s := r.URL.Query().Get("in_stock"); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return models.SearchCriteria{}, errors.New("Invalid in_stock")
 		}
+		inStockPtr = &b
 	}
 
-	criteria := models.SearchCriteria{
+	var authorIDPtr *int
+	if s := r.URL.Query().Get("author_id"); s != "" {
+		authorID, ok := parseID(ctx, h.IDs, "authors", s)
+		if !ok {
+			return models.SearchCriteria{}, errors.New("Invalid author_id")
+		}
+		authorIDPtr = &authorID
+	}
+
+	var seriesPtr *int
+	if s := r.URL.Query().Get("series"); s != "" {
+		seriesID, ok := parseID(ctx, h.IDs, "series", s)
+		if !ok {
+			return models.SearchCriteria{}, errors.New("Invalid series")
+		}
+		seriesPtr = &seriesID
+	}
+
+	var minRatingPtr *float64
+	if s := r.URL.Query().Get("min_rating"); s != "" {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || f < models.MinRating || f > models.MaxRating {
+			return models.SearchCriteria{}, errors.New("Invalid min_rating, expected 1 to 5")
+		}
+		minRatingPtr = &f
+	}
+
+	// sort takes a comma separated key list (sort=-price,title); the older
+	// sort_by/sort_order pair is still accepted as a single key.
+	sortSpec := r.URL.Query().Get("sort")
+	if sortSpec == "" {
+		if sortBy := r.URL.Query().Get("sort_by"); sortBy != "" {
+			if strings.EqualFold(r.URL.Query().Get("sort_order"), "desc") {
+				sortBy = "-" + sortBy
+			}
+			sortSpec = sortBy
+		}
+	}
+	sortKeys, err := models.ParseSort(sortSpec)
+	if err != nil {
+		return models.SearchCriteria{}, err
+	}
+
+	return models.SearchCriteria{
+		Query:     query,
 		Title:     title,
 		Author:    author,
-		Genre:     genre,
+		AuthorID:  authorIDPtr,
+		Genres:    genres,
 		MinPrice:  minPricePtr,
 		MaxPrice:  maxPricePtr,
-		SortBy:    sortBy,
-		SortOrder: sortOrder,
+		InStock:   inStockPtr,
+		Series:    seriesPtr,
+		MinRating: minRatingPtr,
+		Sort:      sortKeys,
+	}, nil
+}
+
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 50
+)
+
+func (h *BookHandler) suggestBooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	prefix := r.URL.Query().Get("prefix")
+
+	limit := defaultSuggestLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 {
+			limit = min(n, maxSuggestLimit)
+		}
 	}
 
-	books, err := h.BookStore.SearchBooks(ctx, criteria)
+	suggestions, err := h.BookStore.SuggestBooks(ctx, prefix, limit)
 	if err != nil {
 		response.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
-	response.RespondWithJSON(w, http.StatusOK, books)
+	response.RespondWithJSON(w, http.StatusOK, suggestions)
 }
 
 func (h *BookHandler) getBookById(w http.ResponseWriter, r *http.Request, id int) {
@@ -135,6 +386,25 @@ func (h *BookHandler) getBookById(w http.ResponseWriter, r *http.Request, id int
 	response.RespondWithJSON(w, http.StatusOK, book)
 }
 
+// getBookByISBN accepts either ISBN form, with or without hyphens.
+func (h *BookHandler) getBookByISBN(w http.ResponseWriter, r *http.Request, raw string) {
+	ctx := r.Context()
+
+	isbn, err := models.NormalizeISBN(raw)
+	if err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid ISBN")
+		return
+	}
+
+	book, err := h.BookStore.GetBookByISBN(ctx, isbn)
+	if err != nil {
+		response.RespondWithError(w, http.StatusNotFound, "Book does not exist")
+		return
+	}
+
+	response.RespondWithJSON(w, http.StatusOK, book)
+}
+
 func (h *BookHandler) updateBook(w http.ResponseWriter, r *http.Request, id int) {
 	ctx := r.Context()
 	defer r.Body.Close()
@@ -145,13 +415,32 @@ func (h *BookHandler) updateBook(w http.ResponseWriter, r *http.Request, id int)
 		return
 	}
 
-	if !h.AuthorStore.AuthorExists(book.Author.ID) {
-		log.Printf("Cannot update book: author %d not found", book.Author.ID)
+	if err := book.NormalizeMetadata(); err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !h.creditsKnownAuthors(ctx, &book) {
+		log.Printf("Cannot update book: contributor not found")
 		response.RespondWithError(w, http.StatusNotFound, "Author not found")
 		return
 	}
 
+	if err := book.NormalizeContributors(); err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !h.placesInKnownSeries(ctx, &book) {
+		response.RespondWithError(w, http.StatusNotFound, "Series not found")
+		return
+	}
+
 	updated_book, update_err := h.BookStore.UpdateBook(ctx, id, book)
+	if errors.Is(update_err, store.ErrDuplicateISBN) {
+		response.RespondWithError(w, http.StatusConflict, update_err.Error())
+		return
+	}
 	if update_err != nil {
 		response.RespondWithError(w, http.StatusNotFound, "Book not found")
 		return
@@ -169,6 +458,10 @@ func (h *BookHandler) deleteBook(w http.ResponseWriter, r *http.Request, id int)
 		return
 	}
 
+	if err := h.Covers.Delete(id); err != nil {
+		log.Printf("Failed to remove cover of book %d: %v", id, err)
+	}
+
 	response.RespondWithJSON(w, http.StatusOK, "Book deleted successfully")
 }
 
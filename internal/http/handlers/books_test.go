@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"Book-Store/internal/http/middleware"
+	"Book-Store/internal/models"
+	"Book-Store/internal/store/storetest"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSearchCriteria(t *testing.T) {
+	price, rating, series, author, inStock := 9.5, 4.0, 3, 7, true
+
+	tests := []struct {
+		name    string
+		query   string
+		want    models.SearchCriteria
+		wantErr bool
+		// errMsg is the message shown to the client, when fixed.
+		errMsg string
+	}{
+		{
+			name:  "no filters",
+			query: "",
+			want:  models.SearchCriteria{},
+		},
+		{
+			name:  "filters",
+			query: "q=dune&min_price=9.5&genre=fantasy,sf&genre=+horror+&in_stock=true&series=3&min_rating=4",
+			want: models.SearchCriteria{
+				Query:     "dune",
+				Genres:    []string{"fantasy", "sf", "horror"},
+				MinPrice:  &price,
+				InStock:   &inStock,
+				Series:    &series,
+				MinRating: &rating,
+			},
+		},
+		{
+			name:  "author facet",
+			query: "author_id=7",
+			want:  models.SearchCriteria{AuthorID: &author},
+		},
+		{
+			name:  "sort keys",
+			query: "sort=-price,title",
+			want:  models.SearchCriteria{Sort: []models.SortKey{{Field: "price", Desc: true}, {Field: "title"}}},
+		},
+		{
+			name:  "legacy sort",
+			query: "sort_by=price&sort_order=DESC",
+			want:  models.SearchCriteria{Sort: []models.SortKey{{Field: "price", Desc: true}}},
+		},
+		{name: "bad min_price", query: "min_price=cheap", wantErr: true, errMsg: "Invalid min_price"},
+		{name: "bad max_price", query: "max_price=1e", wantErr: true, errMsg: "Invalid max_price"},
+		{name: "bad in_stock", query: "in_stock=maybe", wantErr: true, errMsg: "Invalid in_stock"},
+		{name: "bad author_id", query: "author_id=x", wantErr: true, errMsg: "Invalid author_id"},
+		{name: "bad series", query: "series=first", wantErr: true, errMsg: "Invalid series"},
+		{name: "min_rating out of range", query: "min_rating=6", wantErr: true, errMsg: "Invalid min_rating, expected 1 to 5"},
+		{name: "unknown sort key", query: "sort=colour", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &BookHandler{}
+			r := httptest.NewRequest(http.MethodGet, "/books/?"+tt.query, nil)
+			got, err := h.searchCriteria(r)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("searchCriteria() = %+v, want an error", got)
+				}
+				if tt.errMsg != "" && err.Error() != tt.errMsg {
+					t.Errorf("error = %q, want %q", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("searchCriteria() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("searchCriteria() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// snapshotBeforePublicIDs is a database.json written before public IDs
+// existed, from before books listed their contributors.
+const snapshotBeforePublicIDs = `{
+	"books": {"1": {"id": 1, "title": "Kindred", "author": {"id": 1, "first_name": "Octavia E.", "last_name": "Butler"}, "price": 10, "stock": 2}},
+	"authors": {"1": {"id": 1, "first_name": "Octavia E.", "last_name": "Butler"}},
+	"customers": {"1": {"id": 1, "name": "Dana", "email": "dana@example.com"}},
+	"orders": {"1": {"id": 1, "customer": {"id": 1}, "status": "completed",
+		"items": [{"book": {"id": 1, "title": "Kindred", "author": {"id": 1, "first_name": "Octavia E.", "last_name": "Butler"}, "price": 10}, "quantity": 1}]}}
+}`
+
+func TestGetBookFromSnapshotBeforePublicIDs(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "database.json")
+	if err := os.WriteFile(path, []byte(snapshotBeforePublicIDs), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := storetest.OpenMemStore(t, path)
+	if err := s.EnablePublicIDs(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	author, err := s.GetAuthor(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	book, err := s.GetBook(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if author.PublicID == "" || book.PublicID == "" {
+		t.Fatalf("backfill left author %q and book %q without public IDs", author.PublicID, book.PublicID)
+	}
+
+	h := middleware.HideInternalIDs(&BookHandler{BookStore: s, AuthorStore: s, Tx: s, IDs: s})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/books/"+book.PublicID, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	var got models.Book
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Author.PublicID != author.PublicID {
+		t.Errorf("author public_id = %q, want %q", got.Author.PublicID, author.PublicID)
+	}
+	if len(got.Contributors) != 1 || got.Contributors[0].Author.PublicID != author.PublicID {
+		t.Errorf("contributors = %+v, want the author by public ID", got.Contributors)
+	}
+
+	customer, err := s.GetCustomer(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order, err := s.GetOrder(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Customer.PublicID != customer.PublicID {
+		t.Errorf("order customer public_id = %q, want %q", order.Customer.PublicID, customer.PublicID)
+	}
+	if sold := order.Items[0].Book; sold.PublicID != book.PublicID || sold.Author.PublicID != author.PublicID {
+		t.Errorf("order item book = %q by %q, want %q by %q", sold.PublicID, sold.Author.PublicID, book.PublicID, author.PublicID)
+	}
+}
@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"Book-Store/internal/http/middleware"
+	"Book-Store/internal/models"
+	"Book-Store/internal/response"
+	"Book-Store/internal/store"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// cartSessionHeader carries the ID of an anonymous cart. It is issued with
+// the first change to such a cart and sent back with later requests and on
+// login, which merges the cart into the customer's.
+const cartSessionHeader = "X-Cart-Session"
+
+var errEmptyCart = errors.New("Cart is empty")
+
+// CartHandler serves the cart of the signed-in customer, or of the anonymous
+// session named by X-Cart-Session.
+type CartHandler struct {
+	Store store.CartStore
+	Books store.BookStore
+	Tx    store.Transactor
+	IDs   store.PublicIDResolver
+}
+
+type cartItemRequest struct {
+	Book     models.Book `json:"book"`
+	Quantity int         `json:"quantity"`
+}
+
+func (h *CartHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	path = strings.TrimSpace(path)
+	pathParts := strings.Split(path, "/")
+
+	if len(pathParts) == 2 && pathParts[1] == "checkout" {
+		if r.Method != http.MethodPost {
+			response.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		h.checkout(w, r)
+		return
+	}
+
+	if len(pathParts) >= 2 && pathParts[1] == "items" {
+		switch {
+		case len(pathParts) == 2 && r.Method == http.MethodPost:
+			h.addItem(w, r)
+		case len(pathParts) == 3 && (r.Method == http.MethodPut || r.Method == http.MethodDelete):
+			bookID, ok := parseID(r.Context(), h.IDs, "books", pathParts[2])
+			if !ok {
+				response.RespondWithError(w, http.StatusNotFound, "Book not found")
+				return
+			}
+			h.setItem(w, r, bookID)
+		case len(pathParts) <= 3:
+			response.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		default:
+			response.RespondWithError(w, http.StatusNotFound, "Not found")
+		}
+		return
+	}
+
+	if len(pathParts) > 1 {
+		response.RespondWithError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.getCart(w, r)
+	case http.MethodDelete:
+		h.clearCart(w, r)
+	default:
+		response.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+// cartKey names the cart a request works on. Anonymous callers without a
+// session get a new one when create is set; otherwise their key is empty.
+func cartKey(r *http.Request, create bool) (key, session string, ok bool) {
+	if middleware.GetRoleFromContext(r.Context()) != "" {
+		return models.CustomerCartKey(middleware.GetUserIDFromContext(r.Context())), "", true
+	}
+
+	session = r.Header.Get(cartSessionHeader)
+	if session == "" {
+		if !create {
+			return "", "", true
+		}
+		session = uuid.NewString()
+	}
+	if _, err := uuid.Parse(session); err != nil {
+		return "", "", false
+	}
+	return models.SessionCartKey(session), session, true
+}
+
+func (h *CartHandler) getCart(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	key, session, ok := cartKey(r, false)
+	if !ok {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid cart session")
+		return
+	}
+
+	cart := models.Cart{}
+	if key != "" {
+		var err error
+		if cart, err = h.Store.GetCart(ctx, key); err != nil {
+			response.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+	}
+
+	h.respondWithCart(w, r, cart, session)
+}
+
+func (h *CartHandler) addItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	var req cartItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+	if req.Quantity <= 0 {
+		response.RespondWithError(w, http.StatusBadRequest, "Quantity must be positive")
+		return
+	}
+	if !resolveReference(ctx, h.IDs, "books", req.Book.PublicID, &req.Book.ID) || !h.Books.BookExists(req.Book.ID) {
+		response.RespondWithError(w, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	key, session, ok := cartKey(r, true)
+	if !ok {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid cart session")
+		return
+	}
+
+	cart, err := h.Store.AddCartItem(ctx, key, req.Book.ID, req.Quantity)
+	if err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.respondWithCart(w, r, cart, session)
+}
+
+// setItem changes the quantity of a book in the cart; DELETE and a quantity
+// of zero take it out.
+func (h *CartHandler) setItem(w http.ResponseWriter, r *http.Request, bookID int) {
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	var req cartItemRequest
+	if r.Method == http.MethodPut {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.RespondWithError(w, http.StatusBadRequest, "Invalid JSON")
+			return
+		}
+		if req.Quantity < 0 {
+			response.RespondWithError(w, http.StatusBadRequest, "Quantity must not be negative")
+			return
+		}
+		// Books deleted from the catalog can still be taken out.
+		if req.Quantity > 0 && !h.Books.BookExists(bookID) {
+			response.RespondWithError(w, http.StatusNotFound, "Book not found")
+			return
+		}
+	}
+
+	// Only adding a book starts a new anonymous cart.
+	key, session, ok := cartKey(r, req.Quantity > 0)
+	if !ok {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid cart session")
+		return
+	}
+	if key == "" {
+		h.respondWithCart(w, r, models.Cart{}, "")
+		return
+	}
+
+	cart, err := h.Store.SetCartItem(ctx, key, bookID, req.Quantity)
+	if err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.respondWithCart(w, r, cart, session)
+}
+
+func (h *CartHandler) clearCart(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	key, session, ok := cartKey(r, false)
+	if !ok {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid cart session")
+		return
+	}
+
+	if key != "" {
+		if err := h.Store.DeleteCart(ctx, key); err != nil {
+			response.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+	}
+
+	h.respondWithCart(w, r, models.Cart{}, session)
+}
+
+// checkout turns the customer's cart into an order and empties it, in one
+// transaction so a failed order leaves the cart as it was.
+func (h *CartHandler) checkout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	customerID := middleware.GetUserIDFromContext(ctx)
+	key := models.CustomerCartKey(customerID)
+
+	var order models.Order
+	err := h.Tx.WithTx(ctx, func(tx store.Stores) error {
+		cart, err := tx.GetCart(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		order = models.Order{Customer: models.Customer{ID: customerID}}
+		for _, item := range cart.Items {
+			// Books deleted from the catalog can't be bought any more.
+			if !tx.BookExists(item.BookID) {
+				continue
+			}
+			order.Items = append(order.Items, models.OrderItem{
+				Book:     models.Book{ID: item.BookID},
+				Quantity: item.Quantity,
+			})
+		}
+		if len(order.Items) == 0 {
+			return errEmptyCart
+		}
+
+		order, err = tx.CreateOrder(ctx, order)
+		if err != nil {
+			return err
+		}
+		return tx.DeleteCart(ctx, key)
+	})
+	if err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.RespondWithJSON(w, http.StatusCreated, order)
+}
+
+func (h *CartHandler) respondWithCart(w http.ResponseWriter, r *http.Request, cart models.Cart, session string) {
+	view, err := h.view(r.Context(), cart)
+	if err != nil {
+		response.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	if session != "" {
+		view.Session = session
+		w.Header().Set(cartSessionHeader, session)
+	}
+	response.RespondWithJSON(w, http.StatusOK, view)
+}
+
+// view prices a cart with the current price and stock of its books.
+func (h *CartHandler) view(ctx context.Context, cart models.Cart) (models.CartView, error) {
+	view := models.CartView{Items: make([]models.CartLine, 0, len(cart.Items)), UpdatedAt: cart.UpdatedAt}
+	for _, item := range cart.Items {
+		book, err := h.Books.GetBook(ctx, item.BookID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return models.CartView{}, ctx.Err()
+			}
+			continue
+		}
+
+		line := models.CartLine{
+			Book:      book,
+			Quantity:  item.Quantity,
+			Subtotal:  book.Price * float64(item.Quantity),
+			Available: book.Stock >= item.Quantity,
+		}
+		view.Items = append(view.Items, line)
+		view.Total += line.Subtotal
+	}
+	return view, nil
+}
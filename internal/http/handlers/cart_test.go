@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"Book-Store/internal/authentication"
+	"Book-Store/internal/http/middleware"
+	"Book-Store/internal/models"
+	"Book-Store/internal/store/storetest"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCartCheckout(t *testing.T) {
+	tests := []struct {
+		name       string
+		quantity   int
+		wantStatus int
+		wantStock  int
+		wantInCart int
+	}{
+		{name: "order placed", quantity: 2, wantStatus: http.StatusCreated, wantStock: 1, wantInCart: 0},
+		{name: "short on stock", quantity: 5, wantStatus: http.StatusBadRequest, wantStock: 3, wantInCart: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := storetest.NewMemStore(t)
+
+			author, err := s.CreateAuthor(ctx, models.Author{FirstName: "Ursula", LastName: "Le Guin"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			book, err := s.CreateBook(ctx, models.Book{Title: "The Dispossessed", Author: author, Price: 10, Stock: 3})
+			if err != nil {
+				t.Fatal(err)
+			}
+			customer, err := s.CreateCustomer(ctx, models.Customer{Name: "Shevek", Email: "shevek@example.com"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			key := models.CustomerCartKey(customer.ID)
+			if _, err := s.AddCartItem(ctx, key, book.ID, tt.quantity); err != nil {
+				t.Fatal(err)
+			}
+
+			h := &CartHandler{Store: s, Books: s, Tx: s}
+			r := httptest.NewRequest(http.MethodPost, "/cart/checkout", nil)
+			rctx := context.WithValue(r.Context(), middleware.UserIDKey, customer.ID)
+			rctx = context.WithValue(rctx, middleware.RoleKey, models.RoleCustomer)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r.WithContext(rctx))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+
+			got, err := s.GetBook(ctx, book.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Stock != tt.wantStock {
+				t.Errorf("stock = %d, want %d", got.Stock, tt.wantStock)
+			}
+
+			cart, err := s.GetCart(ctx, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			inCart := 0
+			for _, item := range cart.Items {
+				inCart += item.Quantity
+			}
+			if inCart != tt.wantInCart {
+				t.Errorf("cart holds %d books, want %d", inCart, tt.wantInCart)
+			}
+		})
+	}
+}
+
+func TestLoginMergesSessionCart(t *testing.T) {
+	ctx := context.Background()
+	s := storetest.NewMemStore(t)
+	author, err := s.CreateAuthor(ctx, models.Author{FirstName: "Ursula", LastName: "Le Guin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	book, err := s.CreateBook(ctx, models.Book{Title: "The Dispossessed", Author: author, Price: 10, Stock: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := authentication.HashPassword("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	customer, err := s.CreateCustomer(ctx, models.Customer{Name: "Shevek", Email: "shevek@example.com", Password: hash})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	carts := &CartHandler{Store: s, Books: s, Tx: s}
+	w := httptest.NewRecorder()
+	carts.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/cart/items",
+		strings.NewReader(fmt.Sprintf(`{"book":{"id":%d},"quantity":2}`, book.ID))))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	session := w.Header().Get(cartSessionHeader)
+	if session == "" {
+		t.Fatal("adding to an anonymous cart issued no session")
+	}
+
+	auth := &AuthHandler{Store: s, Tokens: s, Carts: s, Cfg: &middleware.ApiConfig{Token: "test-secret"}}
+	r := httptest.NewRequest(http.MethodPost, "/auth/login",
+		strings.NewReader(`{"email":"shevek@example.com","password":"secret"}`))
+	r.Header.Set(cartSessionHeader, session)
+	w = httptest.NewRecorder()
+	auth.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	cart, err := s.GetCart(ctx, models.CustomerCartKey(customer.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cart.Items) != 1 || cart.Items[0].BookID != book.ID || cart.Items[0].Quantity != 2 {
+		t.Errorf("customer cart = %+v, want the session's 2 copies of book %d", cart.Items, book.ID)
+	}
+	if session, err := s.GetCart(ctx, models.SessionCartKey(session)); err != nil || len(session.Items) != 0 {
+		t.Errorf("session cart = %+v, %v after login, want it emptied", session.Items, err)
+	}
+}
@@ -4,19 +4,24 @@ import (
 	"Book-Store/internal/authentication"
 	"Book-Store/internal/http/middleware"
 	"Book-Store/internal/models"
+	"Book-Store/internal/recommendations"
 	"Book-Store/internal/response"
 	"Book-Store/internal/store"
+	"context"
 	"encoding/json"
 	"log"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 )
 
+const accessTokenTTL = time.Hour
+
 type CustomerHandler struct {
-	Store store.CustomerStore
-	Cfg   *middleware.ApiConfig
+	Store           store.CustomerStore
+	Cfg             *middleware.ApiConfig
+	IDs             store.PublicIDResolver
+	Recommendations *recommendations.Recommender
 }
 
 func (h *CustomerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -28,12 +33,22 @@ func (h *CustomerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	var hasID bool
 
 	if len(pathParts) > 1 && pathParts[1] != "" {
-		idStr := strings.TrimSpace(pathParts[1])
-		parsedID, err := strconv.Atoi(idStr)
-		if err == nil {
-			id = parsedID
-			hasID = true
+		parsedID, ok := parseID(r.Context(), h.IDs, "customers", pathParts[1])
+		if !ok {
+			response.RespondWithError(w, http.StatusNotFound, "Customer not found")
+			return
 		}
+		id = parsedID
+		hasID = true
+	}
+
+	if hasID && len(pathParts) == 3 && pathParts[2] == "recommendations" {
+		if r.Method != http.MethodGet {
+			response.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		h.customerRecommendations(w, r, id)
+		return
 	}
 
 	switch r.Method {
@@ -90,6 +105,7 @@ func (h *CustomerHandler) createCustomer(w http.ResponseWriter, r *http.Request)
 	}
 
 	customer.Password = hashed_password
+	customer.Role = models.RoleCustomer
 	createdCustomer, err := h.Store.CreateCustomer(ctx, customer)
 	if err != nil {
 		response.RespondWithError(w, http.StatusInternalServerError, err.Error())
@@ -98,8 +114,9 @@ func (h *CustomerHandler) createCustomer(w http.ResponseWriter, r *http.Request)
 
 	token, err := authentication.MakeJWT(
 		createdCustomer.ID,
+		createdCustomer.Role,
 		h.Cfg.Token,
-		time.Hour,
+		accessTokenTTL,
 	)
 	if err != nil {
 		response.RespondWithError(w, http.StatusInternalServerError, "Error creating Token")
@@ -108,6 +125,7 @@ func (h *CustomerHandler) createCustomer(w http.ResponseWriter, r *http.Request)
 
 	response.RespondWithJSON(w, http.StatusCreated, struct {
 		ID        int            `json:"id"`
+		PublicID  string         `json:"public_id,omitempty"`
 		Name      string         `json:"name"`
 		Email     string         `json:"email"`
 		Token     string         `json:"token"`
@@ -115,6 +133,7 @@ func (h *CustomerHandler) createCustomer(w http.ResponseWriter, r *http.Request)
 		CreatedAt time.Time      `json:"created_at"`
 	}{
 		ID:        createdCustomer.ID,
+		PublicID:  createdCustomer.PublicID,
 		Name:      createdCustomer.Name,
 		Email:     createdCustomer.Email,
 		Token:     token,
@@ -126,49 +145,85 @@ func (h *CustomerHandler) createCustomer(w http.ResponseWriter, r *http.Request)
 func (h *CustomerHandler) getCustomer(w http.ResponseWriter, r *http.Request, id int) {
 	ctx := r.Context()
 
+	if !canAccessCustomer(ctx, id, middleware.StaffRoles...) {
+		response.RespondWithError(w, http.StatusNotFound, "Customer not found")
+		return
+	}
+
 	customer, err := h.Store.GetCustomer(ctx, id)
 	if err != nil {
 		response.RespondWithError(w, http.StatusNotFound, "Customer not found")
 		return
 	}
 
-	response.RespondWithJSON(w, http.StatusOK, customer)
+	response.RespondWithJSON(w, http.StatusOK, withoutPassword(customer))
 }
 
 func (h *CustomerHandler) listCustomers(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	customers, err := h.Store.ListCustomers(ctx)
+	pageReq, ok := parsePageRequest(r)
+	if !ok {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	page, err := h.Store.ListCustomers(ctx, pageReq)
 	if err != nil {
-		response.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithListError(w, err)
 		return
 	}
 
-	response.RespondWithJSON(w, http.StatusOK, customers)
+	for i := range page.Items {
+		page.Items[i] = withoutPassword(page.Items[i])
+	}
+
+	respondWithPage(w, r, page, page.NextCursor)
 }
 
 func (h *CustomerHandler) updateCustomer(w http.ResponseWriter, r *http.Request, id int) {
 	ctx := r.Context()
 	defer r.Body.Close()
 
+	if !canAccessCustomer(ctx, id, middleware.AdminRoles...) {
+		response.RespondWithError(w, http.StatusNotFound, "Customer not found")
+		return
+	}
+
 	var customer models.Customer
 	if err := json.NewDecoder(r.Body).Decode(&customer); err != nil {
 		response.RespondWithError(w, http.StatusBadRequest, "Invalid JSON")
 		return
 	}
 
+	if customer.Role != "" {
+		if middleware.GetRoleFromContext(ctx) != models.RoleAdmin {
+			response.RespondWithError(w, http.StatusForbidden, "Only admins can change roles")
+			return
+		}
+		if !models.IsValidRole(customer.Role) {
+			response.RespondWithError(w, http.StatusBadRequest, "Invalid role")
+			return
+		}
+	}
+
 	updatedCustomer, err := h.Store.UpdateCustomer(ctx, id, customer)
 	if err != nil {
 		response.RespondWithError(w, http.StatusNotFound, "Customer not found")
 		return
 	}
 
-	response.RespondWithJSON(w, http.StatusOK, updatedCustomer)
+	response.RespondWithJSON(w, http.StatusOK, withoutPassword(updatedCustomer))
 }
 
 func (h *CustomerHandler) deleteCustomer(w http.ResponseWriter, r *http.Request, id int) {
 	ctx := r.Context()
 
+	if !canAccessCustomer(ctx, id, middleware.StaffRoles...) {
+		response.RespondWithError(w, http.StatusNotFound, "Customer not found")
+		return
+	}
+
 	err := h.Store.DeleteCustomer(ctx, id)
 	if err != nil {
 		response.RespondWithError(w, http.StatusNotFound, "Customer not found")
@@ -178,3 +233,14 @@ func (h *CustomerHandler) deleteCustomer(w http.ResponseWriter, r *http.Request,
 	response.RespondWithJSON(w, http.StatusOK, "Customer deleted successfully")
 }
 
+// canAccessCustomer reports whether the caller may see or change customer id:
+// customers reach their own record and callers with one of roles everyone's.
+// Callers answer 404 otherwise so other customers' IDs can't be probed.
+func canAccessCustomer(ctx context.Context, id int, roles ...string) bool {
+	return middleware.GetUserIDFromContext(ctx) == id || middleware.HasRole(ctx, roles...)
+}
+
+func withoutPassword(customer models.Customer) models.Customer {
+	customer.Password = ""
+	return customer
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"Book-Store/internal/store"
+	"context"
+	"strconv"
+	"strings"
+)
+
+// parseID turns an {id} path segment into an internal key. With public IDs
+// enabled (ids != nil) only public IDs are accepted, so the integer keys stay
+// internal.
+func parseID(ctx context.Context, ids store.PublicIDResolver, entity, raw string) (int, bool) {
+	raw = strings.TrimSpace(raw)
+	if ids != nil {
+		return ids.ResolvePublicID(ctx, entity, raw)
+	}
+
+	id, err := strconv.Atoi(raw)
+	return id, err == nil
+}
+
+// resolveReference fills *id from publicID when a request body refers to
+// another record by its public ID. It reports false for unknown public IDs.
+func resolveReference(ctx context.Context, ids store.PublicIDResolver, entity, publicID string, id *int) bool {
+	if ids == nil || publicID == "" {
+		return true
+	}
+
+	resolved, ok := ids.ResolvePublicID(ctx, entity, publicID)
+	if ok {
+		*id = resolved
+	}
+	return ok
+}
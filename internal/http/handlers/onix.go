@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"Book-Store/internal/models"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ONIX 3.0 reference-tag records. Codes come from the ONIX code lists,
+// noted next to each mapping.
+const onixNamespace = "http://ns.editeur.org/onix/3.0/reference"
+
+type onixHeader struct {
+	XMLName      xml.Name `xml:"Header"`
+	SenderName   string   `xml:"Sender>SenderName"`
+	SentDateTime string
+}
+
+type onixProduct struct {
+	XMLName            xml.Name `xml:"Product"`
+	RecordReference    string
+	NotificationType   string
+	ProductIdentifiers []onixProductIdentifier `xml:"ProductIdentifier"`
+	DescriptiveDetail  onixDescriptiveDetail
+	PublishingDetail   *onixPublishingDetail `xml:",omitempty"`
+	ProductSupply      onixProductSupply
+}
+
+type onixProductIdentifier struct {
+	ProductIDType string
+	IDValue       string
+}
+
+type onixDescriptiveDetail struct {
+	ProductComposition string
+	ProductForm        string
+	Collection         *onixCollection `xml:",omitempty"`
+	TitleDetail        onixTitleDetail
+	Contributors       []onixContributor `xml:"Contributor"`
+	EditionStatement   string            `xml:",omitempty"`
+	Language           *onixLanguage     `xml:",omitempty"`
+	Extent             *onixExtent       `xml:",omitempty"`
+	Subjects           []onixSubject     `xml:"Subject"`
+}
+
+type onixCollection struct {
+	CollectionType string
+	TitleDetail    onixTitleDetail
+}
+
+type onixTitleDetail struct {
+	TitleType         string
+	TitleElementLevel string `xml:"TitleElement>TitleElementLevel"`
+	PartNumber        string `xml:"TitleElement>PartNumber,omitempty"`
+	TitleText         string `xml:"TitleElement>TitleText"`
+}
+
+type onixContributor struct {
+	SequenceNumber   int
+	ContributorRole  string
+	PersonName       string
+	NamesBeforeKey   string `xml:",omitempty"`
+	KeyNames         string `xml:",omitempty"`
+	BiographicalNote string `xml:",omitempty"`
+}
+
+type onixLanguage struct {
+	LanguageRole string
+	LanguageCode string
+}
+
+type onixExtent struct {
+	ExtentType  string
+	ExtentValue int
+	ExtentUnit  string
+}
+
+type onixSubject struct {
+	SubjectSchemeIdentifier string
+	SubjectHeadingText      string
+}
+
+type onixPublishingDetail struct {
+	Publisher      *onixPublisher      `xml:",omitempty"`
+	PublishingDate *onixPublishingDate `xml:",omitempty"`
+}
+
+type onixPublisher struct {
+	PublishingRole string
+	PublisherName  string
+}
+
+type onixPublishingDate struct {
+	PublishingDateRole string
+	Date               string
+}
+
+type onixProductSupply struct {
+	SupplyDetail onixSupplyDetail
+}
+
+type onixSupplyDetail struct {
+	SupplierRole        string `xml:"Supplier>SupplierRole"`
+	SupplierName        string `xml:"Supplier>SupplierName"`
+	ProductAvailability string
+	OnHand              int `xml:"Stock>OnHand"`
+	Price               onixPrice
+}
+
+type onixPrice struct {
+	PriceType    string
+	PriceAmount  string
+	CurrencyCode string
+}
+
+// onixProductForms maps book formats to List 150.
+var onixProductForms = map[string]string{
+	models.FormatHardcover: "BB",
+	models.FormatPaperback: "BC",
+	models.FormatEbook:     "ED",
+	models.FormatAudiobook: "AJ",
+}
+
+// onixContributorRoles maps contributor roles to List 17.
+var onixContributorRoles = map[string]string{
+	models.ContributorAuthor:      "A01",
+	models.ContributorEditor:      "B01",
+	models.ContributorTranslator:  "B06",
+	models.ContributorIllustrator: "A12",
+	models.ContributorNarrator:    "E07",
+}
+
+// onixLanguages turns common two-letter language codes into the ISO 639-2/B
+// codes ONIX uses. Three-letter codes are passed through and other languages
+// are left out.
+var onixLanguages = map[string]string{
+	"ar": "ara", "de": "ger", "en": "eng", "es": "spa", "fr": "fre",
+	"it": "ita", "ja": "jpn", "ko": "kor", "nl": "dut", "pl": "pol",
+	"pt": "por", "ru": "rus", "sv": "swe", "tr": "tur", "zh": "chi",
+}
+
+type onixCatalog struct {
+	enc      *xml.Encoder
+	w        io.Writer
+	sender   string
+	currency string
+}
+
+func newONIXCatalog(w io.Writer, sender, currency string) *onixCatalog {
+	return &onixCatalog{enc: xml.NewEncoder(w), w: w, sender: sender, currency: currency}
+}
+
+func (c *onixCatalog) begin() error {
+	if _, err := io.WriteString(c.w, xml.Header); err != nil {
+		return err
+	}
+
+	err := c.enc.EncodeToken(xml.StartElement{
+		Name: xml.Name{Local: "ONIXMessage"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "xmlns"}, Value: onixNamespace},
+			{Name: xml.Name{Local: "release"}, Value: "3.0"},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.enc.Encode(onixHeader{
+		SenderName:   c.sender,
+		SentDateTime: time.Now().UTC().Format("20060102T1504Z"),
+	})
+}
+
+func (c *onixCatalog) book(book models.Book) error {
+	return c.enc.Encode(c.product(book))
+}
+
+func (c *onixCatalog) end() error {
+	if err := c.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "ONIXMessage"}}); err != nil {
+		return err
+	}
+	return c.flush()
+}
+
+func (c *onixCatalog) flush() error {
+	return c.enc.Flush()
+}
+
+func (c *onixCatalog) product(book models.Book) onixProduct {
+	reference := book.PublicID
+	if reference == "" {
+		reference = "book-" + strconv.Itoa(book.ID)
+	}
+
+	product := onixProduct{
+		RecordReference:  reference,
+		NotificationType: "03", // List 1: notification confirmed on publication
+		ProductIdentifiers: []onixProductIdentifier{
+			{ProductIDType: "01", IDValue: reference}, // List 5: proprietary
+		},
+		DescriptiveDetail: onixDescriptiveDetail{
+			ProductComposition: "00", // List 2: single-component retail product
+			ProductForm:        "00", // List 150: undefined
+			TitleDetail: onixTitleDetail{
+				TitleType:         "01", // List 15: distinctive title
+				TitleElementLevel: "01", // List 149: product
+				TitleText:         book.Title,
+			},
+			EditionStatement: book.Edition,
+		},
+	}
+	if book.ISBN != "" {
+		product.ProductIdentifiers = append(product.ProductIdentifiers,
+			onixProductIdentifier{ProductIDType: "15", IDValue: book.ISBN}) // List 5: ISBN-13
+	}
+
+	detail := &product.DescriptiveDetail
+	if form, ok := onixProductForms[book.Format]; ok {
+		detail.ProductForm = form
+	}
+
+	if book.Series != nil {
+		detail.Collection = &onixCollection{
+			CollectionType: "10", // List 148: publisher collection
+			TitleDetail: onixTitleDetail{
+				TitleType:         "01",
+				TitleElementLevel: "02", // List 149: collection
+				PartNumber:        strconv.FormatFloat(book.Series.Position, 'f', -1, 64),
+				TitleText:         book.Series.Name,
+			},
+		}
+	}
+
+	contributors := book.Contributors
+	if len(contributors) == 0 {
+		contributors = []models.Contributor{{Author: book.Author, Role: models.ContributorAuthor}}
+	}
+	for i, contributor := range contributors {
+		detail.Contributors = append(detail.Contributors, onixContributor{
+			SequenceNumber:   i + 1,
+			ContributorRole:  onixContributorRoles[contributor.Role],
+			PersonName:       authorName(contributor.Author),
+			NamesBeforeKey:   contributor.Author.FirstName,
+			KeyNames:         contributor.Author.LastName,
+			BiographicalNote: contributor.Author.Bio,
+		})
+	}
+
+	language := book.Language
+	if code, ok := onixLanguages[language]; ok {
+		language = code
+	}
+	if len(language) == 3 {
+		detail.Language = &onixLanguage{LanguageRole: "01", LanguageCode: language} // List 22: language of text
+	}
+
+	if book.PageCount > 0 {
+		// List 23: main content page count, List 24: pages.
+		detail.Extent = &onixExtent{ExtentType: "00", ExtentValue: book.PageCount, ExtentUnit: "03"}
+	}
+
+	for _, genre := range book.Genres {
+		// List 27: keywords.
+		detail.Subjects = append(detail.Subjects, onixSubject{SubjectSchemeIdentifier: "20", SubjectHeadingText: genre})
+	}
+
+	if book.Publisher != "" || !book.PublishedAt.IsZero() {
+		product.PublishingDetail = &onixPublishingDetail{}
+		if book.Publisher != "" {
+			product.PublishingDetail.Publisher = &onixPublisher{
+				PublishingRole: "01", // List 45: publisher
+				PublisherName:  book.Publisher,
+			}
+		}
+		if !book.PublishedAt.IsZero() {
+			product.PublishingDetail.PublishingDate = &onixPublishingDate{
+				PublishingDateRole: "01", // List 163: publication date
+				Date:               book.PublishedAt.Format("20060102"),
+			}
+		}
+	}
+
+	availability := "21" // List 65: in stock
+	if book.Stock <= 0 {
+		availability = "31" // List 65: out of stock
+	}
+	product.ProductSupply.SupplyDetail = onixSupplyDetail{
+		SupplierRole:        "00", // List 93: unspecified
+		SupplierName:        c.sender,
+		ProductAvailability: availability,
+		OnHand:              max(book.Stock, 0),
+		Price: onixPrice{
+			// List 58: RRP including tax, as catalog prices are what
+			// customers pay.
+			PriceType:    "02",
+			PriceAmount:  strconv.FormatFloat(book.Price, 'f', 2, 64),
+			CurrencyCode: c.currency,
+		},
+	}
+	return product
+}
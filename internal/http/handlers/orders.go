@@ -3,17 +3,21 @@ package handlers
 import (
 	"Book-Store/internal/http/middleware"
 	"Book-Store/internal/models"
+	"Book-Store/internal/recommendations"
 	"Book-Store/internal/response"
 	"Book-Store/internal/store"
+	"context"
 	"encoding/json"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 )
 
 type OrderHandler struct {
 	Store store.OrderStore
+	IDs   store.PublicIDResolver
+	// Recommendations, when set, learns from orders as they complete.
+	Recommendations *recommendations.Recommender
 }
 
 func (h *OrderHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -27,12 +31,13 @@ func (h *OrderHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	)
 
 	if len(pathParts) > 1 && pathParts[1] != "" {
-		idStr := strings.TrimSpace(pathParts[1])
-		parsedID, err := strconv.Atoi(idStr)
-		if err == nil {
-			id = parsedID
-			hasID = true
+		parsedID, ok := parseID(r.Context(), h.IDs, "orders", pathParts[1])
+		if !ok {
+			response.RespondWithError(w, http.StatusNotFound, "Order not found")
+			return
 		}
+		id = parsedID
+		hasID = true
 	}
 
 	switch r.Method {
@@ -73,6 +78,14 @@ func (h *OrderHandler) createOrder(w http.ResponseWriter, r *http.Request) {
 
 	order.Customer.ID = userID
 
+	for i := range order.Items {
+		book := &order.Items[i].Book
+		if !resolveReference(ctx, h.IDs, "books", book.PublicID, &book.ID) {
+			response.RespondWithError(w, http.StatusBadRequest, "book not found in order")
+			return
+		}
+	}
+
 	resultChan := make(chan models.Order, 1)
 	errChan := make(chan error, 1)
 
@@ -101,7 +114,7 @@ func (h *OrderHandler) getOrderByID(w http.ResponseWriter, r *http.Request, id i
 	ctx := r.Context()
 
 	order, err := h.Store.GetOrder(ctx, id)
-	if err != nil {
+	if err != nil || !canAccessOrder(ctx, order) {
 		response.RespondWithError(w, http.StatusNotFound, "Order not found")
 		return
 	}
@@ -116,6 +129,12 @@ func (h *OrderHandler) changeStatus(w http.ResponseWriter, r *http.Request, id i
 		return
 	}
 
+	order, err := h.Store.GetOrder(ctx, id)
+	if err != nil || !canAccessOrder(ctx, order) {
+		response.RespondWithError(w, http.StatusNotFound, "Order not found")
+		return
+	}
+
 	switch status {
 	case "completed":
 		_, err := h.Store.CompleteOrder(ctx, id)
@@ -123,6 +142,10 @@ func (h *OrderHandler) changeStatus(w http.ResponseWriter, r *http.Request, id i
 			response.RespondWithError(w, http.StatusBadRequest, err.Error())
 			return
 		}
+		if h.Recommendations != nil {
+			order.Status = "completed"
+			h.Recommendations.RecordOrder(order)
+		}
 		response.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Order completed"})
 	case "cancelled":
 		_, err := h.Store.CancelOrder(ctx, id)
@@ -140,23 +163,35 @@ func (h *OrderHandler) searchOrderByStatus(w http.ResponseWriter, r *http.Reques
 	ctx := r.Context()
 	status := r.URL.Query().Get("status")
 
-	orders, err := h.Store.SearchOrderByStatus(ctx, status)
+	pageReq, ok := parsePageRequest(r)
+	if !ok {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	page, err := h.Store.SearchOrderByStatus(ctx, status, pageReq)
 	if err != nil {
-		response.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithListError(w, err)
 		return
 	}
-	response.RespondWithJSON(w, http.StatusOK, orders)
+	respondWithPage(w, r, page, page.NextCursor)
 }
 
 func (h *OrderHandler) listOrders(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	orders, err := h.Store.ListOrders(ctx)
+	pageReq, ok := parsePageRequest(r)
+	if !ok {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	page, err := h.Store.ListOrders(ctx, pageReq)
 	if err != nil {
-		response.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithListError(w, err)
 		return
 	}
-	response.RespondWithJSON(w, http.StatusOK, orders)
+	respondWithPage(w, r, page, page.NextCursor)
 }
 
 func (h *OrderHandler) getOrdersInTimeRange(w http.ResponseWriter, r *http.Request) {
@@ -181,11 +216,23 @@ func (h *OrderHandler) getOrdersInTimeRange(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	orders, err := h.Store.GetOrdersInTimeRange(ctx, startDate, endDate)
+	pageReq, ok := parsePageRequest(r)
+	if !ok {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	page, err := h.Store.GetOrdersInTimeRange(ctx, startDate, endDate, pageReq)
 	if err != nil {
-		response.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		respondWithListError(w, err)
 		return
 	}
+	respondWithPage(w, r, page, page.NextCursor)
+}
 
-	response.RespondWithJSON(w, http.StatusOK, orders)
+// canAccessOrder lets customers reach only their own orders. Staff fulfil
+// orders, so they are exempt along with admins.
+func canAccessOrder(ctx context.Context, order models.Order) bool {
+	return order.Customer.ID == middleware.GetUserIDFromContext(ctx) ||
+		middleware.HasRole(ctx, models.RoleStaff, models.RoleAdmin)
 }
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"Book-Store/internal/http/middleware"
+	"Book-Store/internal/models"
+	"Book-Store/internal/store/storetest"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOrdersInTimeRangePages(t *testing.T) {
+	ctx := context.Background()
+	s := storetest.NewMemStore(t)
+
+	author, err := s.CreateAuthor(ctx, models.Author{FirstName: "Ursula", LastName: "Le Guin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	book, err := s.CreateBook(ctx, models.Book{Title: "The Dispossessed", Author: author, Price: 10, Stock: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	customer, err := s.CreateCustomer(ctx, models.Customer{Name: "Shevek", Email: "shevek@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		order := models.Order{Customer: customer, Items: []models.OrderItem{{Book: book, Quantity: 1}}}
+		if _, err := s.CreateOrder(ctx, order); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h := &OrderHandler{Store: s}
+	get := func(target string) (*httptest.ResponseRecorder, models.Page[models.Order]) {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		rctx := context.WithValue(r.Context(), middleware.RoleKey, models.RoleStaff)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r.WithContext(rctx))
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s status = %d, want %d: %s", target, w.Code, http.StatusOK, w.Body)
+		}
+		var page models.Page[models.Order]
+		if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+			t.Fatal(err)
+		}
+		return w, page
+	}
+
+	now := time.Now().UTC()
+	query := url.Values{
+		"start_date": {now.Add(-time.Hour).Format(time.RFC3339)},
+		"end_date":   {now.Add(time.Hour).Format(time.RFC3339)},
+		"limit":      {"2"},
+	}
+	w, first := get("/orders/?" + query.Encode())
+	if len(first.Items) != 2 || first.Total != 3 || first.NextCursor == "" {
+		t.Fatalf("first page = %d of %d orders, cursor %q; want 2 of 3 and a cursor", len(first.Items), first.Total, first.NextCursor)
+	}
+	link := w.Header().Get("Link")
+	if !strings.Contains(link, "cursor="+first.NextCursor) || !strings.Contains(link, "start_date=") {
+		t.Errorf("Link = %q, want the next page of the same range", link)
+	}
+
+	query.Set("cursor", first.NextCursor)
+	w, second := get("/orders/?" + query.Encode())
+	if len(second.Items) != 1 || second.NextCursor != "" || w.Header().Get("Link") != "" {
+		t.Errorf("last page = %d orders, cursor %q, Link %q; want 1 order and no next page", len(second.Items), second.NextCursor, w.Header().Get("Link"))
+	}
+
+	query.Set("limit", "0")
+	r := httptest.NewRequest(http.MethodGet, "/orders/?"+query.Encode(), nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("limit=0 status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"Book-Store/internal/models"
+	"Book-Store/internal/pagination"
+	"Book-Store/internal/response"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// parsePageRequest reads the limit and cursor query parameters. Pages hold
+// defaultPageLimit items unless asked otherwise, and never more than
+// maxPageLimit.
+func parsePageRequest(r *http.Request) (models.PageRequest, bool) {
+	page := models.PageRequest{Limit: defaultPageLimit, Cursor: r.URL.Query().Get("cursor")}
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return page, false
+		}
+		page.Limit = min(n, maxPageLimit)
+	}
+	return page, true
+}
+
+// respondWithPage writes a page of a listing, pointing a Link header at the
+// next page when there is one.
+func respondWithPage(w http.ResponseWriter, r *http.Request, body any, nextCursor string) {
+	if nextCursor != "" {
+		next := *r.URL
+		query := next.Query()
+		query.Set("cursor", nextCursor)
+		next.RawQuery = query.Encode()
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.String()))
+	}
+	response.RespondWithJSON(w, http.StatusOK, body)
+}
+
+func respondWithListError(w http.ResponseWriter, err error) {
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	response.RespondWithError(w, http.StatusInternalServerError, err.Error())
+}
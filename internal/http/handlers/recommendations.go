@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"Book-Store/internal/http/middleware"
+	"Book-Store/internal/response"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultRecommendationLimit = 10
+	maxRecommendationLimit     = 50
+)
+
+func parseRecommendationLimit(r *http.Request) (int, bool) {
+	s := r.URL.Query().Get("limit")
+	if s == "" {
+		return defaultRecommendationLimit, true
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return min(n, maxRecommendationLimit), true
+}
+
+// bookRecommendations lists the books customers bought together with book id.
+func (h *BookHandler) bookRecommendations(w http.ResponseWriter, r *http.Request, id int) {
+	ctx := r.Context()
+
+	limit, ok := parseRecommendationLimit(r)
+	if !ok {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	if !h.BookStore.BookExists(id) {
+		response.RespondWithError(w, http.StatusNotFound, "Book not found")
+		return
+	}
+
+	recommendations, err := h.Recommendations.ForBook(ctx, id, limit)
+	if err != nil {
+		response.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.RespondWithJSON(w, http.StatusOK, recommendations)
+}
+
+// customerRecommendations suggests books to a customer from their purchase
+// history.
+func (h *CustomerHandler) customerRecommendations(w http.ResponseWriter, r *http.Request, id int) {
+	ctx := r.Context()
+
+	if !canAccessCustomer(ctx, id, middleware.StaffRoles...) || !h.Store.CustomerExists(id) {
+		response.RespondWithError(w, http.StatusNotFound, "Customer not found")
+		return
+	}
+
+	limit, ok := parseRecommendationLimit(r)
+	if !ok {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	recommendations, err := h.Recommendations.ForCustomer(ctx, id, limit)
+	if err != nil {
+		response.RespondWithError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	response.RespondWithJSON(w, http.StatusOK, recommendations)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"Book-Store/internal/models"
 	"Book-Store/internal/reports"
 	"Book-Store/internal/response"
 	"Book-Store/internal/store"
@@ -20,14 +21,14 @@ func (h *ReportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	path = strings.TrimSpace(path)
 	pathParts := strings.Split(path, "/")
 
-	if len(pathParts) > 1 && pathParts[1] == "generate" {
+	if len(pathParts) > 2 && pathParts[2] == "generate" {
 		if r.Method == http.MethodPost {
 			h.generateReport(w, r)
 			return
 		}
 	}
 
-	if len(pathParts) > 1 && pathParts[1] == "latest" {
+	if len(pathParts) > 2 && pathParts[2] == "latest" {
 		if r.Method == http.MethodGet {
 			h.getLatestReport(w)
 			return
@@ -72,16 +73,25 @@ func (h *ReportHandler) getLatestReport(w http.ResponseWriter) {
 func (h *ReportHandler) listReports(w http.ResponseWriter, r *http.Request) {
 	since := r.URL.Query().Get("since")
 
-	if since != "" {
-		daysAgo, err := strconv.Atoi(since)
-		if err == nil {
-			since := time.Now().AddDate(0, 0, -daysAgo)
-			reports := h.ReportStore.GetReportsSince(since)
-			response.RespondWithJSON(w, http.StatusOK, reports)
-			return
-		}
+	pageReq, ok := parsePageRequest(r)
+	if !ok {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	var (
+		page models.Page[models.SalesReport]
+		err  error
+	)
+	if daysAgo, convErr := strconv.Atoi(since); since != "" && convErr == nil {
+		page, err = h.ReportStore.GetReportsSince(time.Now().AddDate(0, 0, -daysAgo), pageReq)
+	} else {
+		page, err = h.ReportStore.ListAllReports(pageReq)
+	}
+	if err != nil {
+		respondWithListError(w, err)
+		return
 	}
 
-	allReports := h.ReportStore.ListAllReports()
-	response.RespondWithJSON(w, http.StatusOK, allReports)
+	respondWithPage(w, r, page, page.NextCursor)
 }
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"Book-Store/internal/models"
+	"Book-Store/internal/response"
+	"Book-Store/internal/store"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+type SeriesHandler struct {
+	Store store.SeriesStore
+	IDs   store.PublicIDResolver
+}
+
+func (h *SeriesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	path = strings.TrimSpace(path)
+	pathParts := strings.Split(path, "/")
+
+	var id int
+	var hasID bool
+
+	if len(pathParts) > 1 && pathParts[1] != "" {
+		parsedID, ok := parseID(r.Context(), h.IDs, "series", pathParts[1])
+		if !ok {
+			response.RespondWithError(w, http.StatusNotFound, "Series not found")
+			return
+		}
+		id = parsedID
+		hasID = true
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		h.createSeries(w, r)
+	case http.MethodGet:
+		if hasID {
+			h.getSeries(w, r, id)
+		} else {
+			h.listSeries(w, r)
+		}
+	case http.MethodPut:
+		if !hasID {
+			response.RespondWithError(w, http.StatusBadRequest, "Missing series ID")
+			return
+		}
+		h.updateSeries(w, r, id)
+	case http.MethodDelete:
+		if !hasID {
+			response.RespondWithError(w, http.StatusBadRequest, "Missing series ID")
+			return
+		}
+		h.deleteSeries(w, r, id)
+	default:
+		response.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (h *SeriesHandler) createSeries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	var series models.Series
+	if err := json.NewDecoder(r.Body).Decode(&series); err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if strings.TrimSpace(series.Name) == "" {
+		response.RespondWithError(w, http.StatusBadRequest, "Series name is required")
+		return
+	}
+
+	createdSeries, err := h.Store.CreateSeries(ctx, series)
+	if err != nil {
+		response.RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.RespondWithJSON(w, http.StatusCreated, createdSeries)
+}
+
+// getSeries returns the series with its books in reading order.
+func (h *SeriesHandler) getSeries(w http.ResponseWriter, r *http.Request, id int) {
+	ctx := r.Context()
+
+	series, err := h.Store.GetSeries(ctx, id)
+	if err != nil {
+		response.RespondWithError(w, http.StatusNotFound, "Series not found")
+		return
+	}
+
+	books, err := h.Store.ListSeriesBooks(ctx, id)
+	if err != nil {
+		response.RespondWithError(w, http.StatusNotFound, "Series not found")
+		return
+	}
+
+	response.RespondWithJSON(w, http.StatusOK, struct {
+		models.Series
+		Books []models.Book `json:"books"`
+	}{
+		Series: series,
+		Books:  books,
+	})
+}
+
+func (h *SeriesHandler) listSeries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	pageReq, ok := parsePageRequest(r)
+	if !ok {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid limit")
+		return
+	}
+
+	page, err := h.Store.ListSeries(ctx, pageReq)
+	if err != nil {
+		respondWithListError(w, err)
+		return
+	}
+
+	respondWithPage(w, r, page, page.NextCursor)
+}
+
+func (h *SeriesHandler) updateSeries(w http.ResponseWriter, r *http.Request, id int) {
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	var series models.Series
+	if err := json.NewDecoder(r.Body).Decode(&series); err != nil {
+		response.RespondWithError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	if strings.TrimSpace(series.Name) == "" {
+		response.RespondWithError(w, http.StatusBadRequest, "Series name is required")
+		return
+	}
+
+	updatedSeries, err := h.Store.UpdateSeries(ctx, id, series)
+	if err != nil {
+		response.RespondWithError(w, http.StatusNotFound, "Series not found")
+		return
+	}
+
+	response.RespondWithJSON(w, http.StatusOK, updatedSeries)
+}
+
+// deleteSeries leaves the books of the series in the catalog, no longer
+// part of any series.
+func (h *SeriesHandler) deleteSeries(w http.ResponseWriter, r *http.Request, id int) {
+	ctx := r.Context()
+
+	if err := h.Store.DeleteSeries(ctx, id); err != nil {
+		response.RespondWithError(w, http.StatusNotFound, "Series not found")
+		return
+	}
+
+	response.RespondWithJSON(w, http.StatusOK, "Series deleted successfully")
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"Book-Store/internal/authentication"
+	"Book-Store/internal/models"
 	"Book-Store/internal/response"
 	"context"
 	"net/http"
@@ -9,7 +10,10 @@ import (
 
 type key int
 
-const UserIDKey key = 0
+const (
+	UserIDKey key = iota
+	RoleKey
+)
 
 func AuthMiddleware(tokenSecret string, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -19,17 +23,37 @@ func AuthMiddleware(tokenSecret string, next http.Handler) http.Handler {
 			return
 		}
 
-		userID, err := authentication.ValidateJWT(token, tokenSecret)
+		userID, role, err := authentication.ValidateJWT(token, tokenSecret)
 		if err != nil {
 			response.RespondWithError(w, http.StatusUnauthorized, "Invalid token")
 			return
 		}
 
+		// Tokens issued before roles existed carry no role claim.
+		if role == "" {
+			role = models.RoleCustomer
+		}
+
 		ctx := context.WithValue(r.Context(), UserIDKey, userID)
+		ctx = context.WithValue(ctx, RoleKey, role)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// OptionalAuthMiddleware authenticates the request when it carries an
+// Authorization header and lets anonymous requests through otherwise, leaving
+// it to RoleMiddleware to decide whether they are allowed.
+func OptionalAuthMiddleware(tokenSecret string, next http.Handler) http.Handler {
+	authenticated := AuthMiddleware(tokenSecret, next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		authenticated.ServeHTTP(w, r)
+	})
+}
+
 func GetUserIDFromContext(ctx context.Context) int {
 	if ctx == nil {
 		return 0
@@ -39,3 +63,13 @@ func GetUserIDFromContext(ctx context.Context) int {
 	}
 	return 0
 }
+
+func GetRoleFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if role, ok := ctx.Value(RoleKey).(string); ok {
+		return role
+	}
+	return ""
+}
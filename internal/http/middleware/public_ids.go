@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// HideInternalIDs keeps integer keys out of JSON responses when records are
+// exposed by their public IDs: members named "id" or ending in "_id" are
+// dropped wherever they hold a number. Other responses pass through as
+// written.
+func HideInternalIDs(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hw := &idHidingWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(hw, r)
+		hw.finish()
+	})
+}
+
+// idHidingWriter buffers JSON bodies so their IDs can be dropped once the
+// handler is done.
+type idHidingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	buffering   bool
+	body        bytes.Buffer
+}
+
+func (w *idHidingWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = status
+
+	mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	w.buffering = mediaType == "application/json"
+	if !w.buffering {
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (w *idHidingWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	if w.buffering {
+		return w.body.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, so
+// streamed responses can still be flushed.
+func (w *idHidingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *idHidingWriter) finish() {
+	if !w.buffering {
+		return
+	}
+
+	body := w.body.Bytes()
+	var stripped bytes.Buffer
+	if err := stripIDs(json.NewDecoder(bytes.NewReader(body)), &stripped); err == nil {
+		// Keep the layout of response.RespondWithJSON.
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, stripped.Bytes(), "\n", "    "); err == nil {
+			body = append(indented.Bytes(), '\n')
+		}
+	}
+
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.Write(body)
+}
+
+// stripIDs copies one JSON value from dec to out, leaving out numeric ID
+// members of objects.
+func stripIDs(dec *json.Decoder, out *bytes.Buffer) error {
+	dec.UseNumber()
+
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	return copyValue(dec, token, out)
+}
+
+func copyValue(dec *json.Decoder, token json.Token, out *bytes.Buffer) error {
+	switch token {
+	case json.Delim('{'):
+		return copyObject(dec, out)
+	case json.Delim('['):
+		return copyArray(dec, out)
+	}
+
+	if number, ok := token.(json.Number); ok {
+		out.WriteString(number.String())
+		return nil
+	}
+	encoded, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	out.Write(encoded)
+	return nil
+}
+
+func copyObject(dec *json.Decoder, out *bytes.Buffer) error {
+	out.WriteByte('{')
+	first := true
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		value, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		name, _ := key.(string)
+		if _, isNumber := value.(json.Number); isNumber && isIDMember(name) {
+			continue
+		}
+
+		if !first {
+			out.WriteByte(',')
+		}
+		first = false
+		if err := copyValue(dec, key, out); err != nil {
+			return err
+		}
+		out.WriteByte(':')
+		if err := copyValue(dec, value, out); err != nil {
+			return err
+		}
+	}
+	return closeDelim(dec, '}', out)
+}
+
+func copyArray(dec *json.Decoder, out *bytes.Buffer) error {
+	out.WriteByte('[')
+	first := true
+	for dec.More() {
+		value, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if !first {
+			out.WriteByte(',')
+		}
+		first = false
+		if err := copyValue(dec, value, out); err != nil {
+			return err
+		}
+	}
+	return closeDelim(dec, ']', out)
+}
+
+func closeDelim(dec *json.Decoder, delim byte, out *bytes.Buffer) error {
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	out.WriteByte(delim)
+	return nil
+}
+
+func isIDMember(name string) bool {
+	return name == "id" || strings.HasSuffix(name, "_id")
+}
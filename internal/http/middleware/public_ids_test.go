@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"Book-Store/internal/response"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHideInternalIDs(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		// want is compact; JSON responses are compared in the layout of
+		// response.RespondWithJSON.
+		want string
+	}{
+		{
+			name:        "object",
+			contentType: "application/json",
+			body:        `{"id":3,"public_id":"0190a","title":"Dune"}`,
+			want:        `{"public_id":"0190a","title":"Dune"}`,
+		},
+		{
+			name:        "nested references",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"items":[{"id":1,"book_id":4,"author":{"id":2,"name":"x"}}],"total":1}`,
+			want:        `{"items":[{"author":{"name":"x"}}],"total":1}`,
+		},
+		{
+			name:        "string IDs are public",
+			contentType: "application/json",
+			body:        `{"id":"0190a","session_id":"abc","stock":0}`,
+			want:        `{"id":"0190a","session_id":"abc","stock":0}`,
+		},
+		{
+			name:        "numbers keep their form",
+			contentType: "application/json",
+			body:        `{"price":9.50,"big":12345678901234567890}`,
+			want:        `{"price":9.50,"big":12345678901234567890}`,
+		},
+		{
+			name:        "not JSON",
+			contentType: "text/csv",
+			body:        "id,title\n3,Dune\n",
+			want:        "id,title\n3,Dune\n",
+		},
+		{
+			name:        "invalid JSON passes through",
+			contentType: "application/json",
+			body:        `{"id":3`,
+			want:        `{"id":3`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := HideInternalIDs(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Header().Set("Content-Length", "999")
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(tt.body))
+			}))
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if w.Code != http.StatusCreated {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusCreated)
+			}
+			want := tt.want
+			if json.Valid([]byte(tt.want)) {
+				layout := httptest.NewRecorder()
+				response.RespondWithJSON(layout, http.StatusOK, json.RawMessage(tt.want))
+				want = layout.Body.String()
+			}
+			if got := w.Body.String(); got != want {
+				t.Errorf("body = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"Book-Store/internal/models"
+	"Book-Store/internal/response"
+	"context"
+	"net/http"
+	"slices"
+)
+
+var (
+	AnyRole    = []string{models.RoleCustomer, models.RoleStaff, models.RoleAdmin}
+	StaffRoles = []string{models.RoleStaff, models.RoleAdmin}
+	AdminRoles = []string{models.RoleAdmin}
+)
+
+// Policy returns the roles allowed to perform a request, or nil when the
+// request is public.
+type Policy func(r *http.Request) []string
+
+// MethodPolicy builds a Policy from a method -> roles table. Methods missing
+// from the table are public.
+func MethodPolicy(rules map[string][]string) Policy {
+	return func(r *http.Request) []string {
+		return rules[r.Method]
+	}
+}
+
+// RoleMiddleware enforces policy on top of AuthMiddleware or
+// OptionalAuthMiddleware, which put the caller's role in the context.
+func RoleMiddleware(policy Policy, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		roles := policy(r)
+		if roles == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		role := GetRoleFromContext(r.Context())
+		if role == "" {
+			response.RespondWithError(w, http.StatusUnauthorized, "Authentication required")
+			return
+		}
+
+		if !slices.Contains(roles, role) {
+			response.RespondWithError(w, http.StatusForbidden, "Forbidden")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func HasRole(ctx context.Context, roles ...string) bool {
+	return slices.Contains(roles, GetRoleFromContext(ctx))
+}
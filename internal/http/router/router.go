@@ -4,32 +4,129 @@ import (
 	"Book-Store/internal/http/handlers"
 	"Book-Store/internal/http/middleware"
 	"net/http"
+	"strings"
 )
 
 func Router(
 	apiCfg *middleware.ApiConfig,
 	bookHandler *handlers.BookHandler,
 	authorHandler *handlers.AuthorHandler,
+	seriesHandler *handlers.SeriesHandler,
 	customerHandler *handlers.CustomerHandler,
 	orderHandler *handlers.OrderHandler,
 	reportHandler *handlers.ReportHandler,
 	metricsHandler *handlers.MetricsHandler,
+	authHandler *handlers.AuthHandler,
+	backupHandler *handlers.BackupHandler,
+	cartHandler *handlers.CartHandler,
 	hitsHandler *middleware.ApiConfig,
 ) {
-	http.Handle("/books/", apiCfg.MiddlewareMetricsInc(bookHandler))
-	http.Handle("/authors/", apiCfg.MiddlewareMetricsInc(authorHandler))
+	catalogPolicy := middleware.MethodPolicy(map[string][]string{
+		http.MethodPost:   middleware.StaffRoles,
+		http.MethodPut:    middleware.StaffRoles,
+		http.MethodDelete: middleware.StaffRoles,
+	})
 
-	http.Handle("/customers", apiCfg.MiddlewareMetricsInc(customerHandler))
+	http.Handle("/books/", middleware.OptionalAuthMiddleware(apiCfg.Token,
+		middleware.RoleMiddleware(bookPolicy(catalogPolicy), apiCfg.MiddlewareMetricsInc(bookHandler))))
+	http.Handle("/authors/", middleware.OptionalAuthMiddleware(apiCfg.Token,
+		middleware.RoleMiddleware(catalogPolicy, apiCfg.MiddlewareMetricsInc(authorHandler))))
+	http.Handle("/series/", middleware.OptionalAuthMiddleware(apiCfg.Token,
+		middleware.RoleMiddleware(catalogPolicy, apiCfg.MiddlewareMetricsInc(seriesHandler))))
+
+	http.Handle("/customers", middleware.OptionalAuthMiddleware(apiCfg.Token,
+		middleware.RoleMiddleware(middleware.MethodPolicy(map[string][]string{
+			http.MethodGet: middleware.StaffRoles,
+		}), apiCfg.MiddlewareMetricsInc(customerHandler))))
 	http.Handle("/customers/", middleware.AuthMiddleware(apiCfg.Token,
-		apiCfg.MiddlewareMetricsInc(customerHandler)))
+		middleware.RoleMiddleware(customerPolicy, apiCfg.MiddlewareMetricsInc(customerHandler))))
 
-	http.Handle("/orders", apiCfg.MiddlewareMetricsInc(orderHandler))
+	http.Handle("/orders", middleware.OptionalAuthMiddleware(apiCfg.Token,
+		middleware.RoleMiddleware(middleware.MethodPolicy(map[string][]string{
+			http.MethodPost: middleware.AnyRole,
+			http.MethodGet:  middleware.StaffRoles,
+		}), apiCfg.MiddlewareMetricsInc(orderHandler))))
 	http.Handle("/orders/", middleware.AuthMiddleware(apiCfg.Token,
-		apiCfg.MiddlewareMetricsInc(orderHandler)))
+		middleware.RoleMiddleware(orderPolicy, apiCfg.MiddlewareMetricsInc(orderHandler))))
+
+	http.Handle("/cart", middleware.OptionalAuthMiddleware(apiCfg.Token,
+		middleware.RoleMiddleware(cartPolicy, apiCfg.MiddlewareMetricsInc(cartHandler))))
+	http.Handle("/cart/", middleware.OptionalAuthMiddleware(apiCfg.Token,
+		middleware.RoleMiddleware(cartPolicy, apiCfg.MiddlewareMetricsInc(cartHandler))))
+
+	http.Handle("/auth/", authHandler)
+
+	reportPolicy := middleware.MethodPolicy(map[string][]string{
+		http.MethodGet:  middleware.StaffRoles,
+		http.MethodPost: middleware.StaffRoles,
+	})
+	http.Handle("/reports/sales", middleware.OptionalAuthMiddleware(apiCfg.Token,
+		middleware.RoleMiddleware(reportPolicy, reportHandler)))
+	http.Handle("/reports/sales/", middleware.OptionalAuthMiddleware(apiCfg.Token,
+		middleware.RoleMiddleware(reportPolicy, reportHandler)))
 
-	http.Handle("/reports/sales", reportHandler)
+	// Only backends that keep snapshot backups get the admin backup routes.
+	if backupHandler != nil {
+		adminPolicy := middleware.MethodPolicy(map[string][]string{
+			http.MethodGet:  middleware.AdminRoles,
+			http.MethodPost: middleware.AdminRoles,
+		})
+		http.Handle("/admin/backups", middleware.AuthMiddleware(apiCfg.Token,
+			middleware.RoleMiddleware(adminPolicy, backupHandler)))
+		http.Handle("/admin/backups/", middleware.AuthMiddleware(apiCfg.Token,
+			middleware.RoleMiddleware(adminPolicy, backupHandler)))
+	}
 
 	http.Handle("/metrics", metricsHandler)
 
 	http.Handle("/metrics/hits", hitsHandler)
 }
+
+// bookPolicy opens writing reviews to customers; the rest of the catalog
+// follows catalog.
+func bookPolicy(catalog middleware.Policy) middleware.Policy {
+	return func(r *http.Request) []string {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		if len(parts) >= 3 && parts[2] == "reviews" && r.Method != http.MethodGet {
+			return middleware.AnyRole
+		}
+		return catalog(r)
+	}
+}
+
+// cartPolicy leaves carts open to anonymous shoppers; only checking out
+// needs an account.
+func cartPolicy(r *http.Request) []string {
+	if strings.Trim(r.URL.Path, "/") == "cart/checkout" {
+		return middleware.AnyRole
+	}
+	return nil
+}
+
+// customerPolicy keeps listing and deleting customers to staff; reading and
+// updating a profile is left to the handler's ownership checks.
+func customerPolicy(r *http.Request) []string {
+	if r.Method == http.MethodDelete || (r.Method == http.MethodGet && !hasPathID(r)) {
+		return middleware.StaffRoles
+	}
+	return nil
+}
+
+// orderPolicy lets customers cancel orders but keeps completing them, and
+// listing everyone's orders, to staff.
+func orderPolicy(r *http.Request) []string {
+	if r.Method == http.MethodPut && r.URL.Query().Get("status") == "completed" {
+		return middleware.StaffRoles
+	}
+	if r.Method == http.MethodGet && !hasPathID(r) {
+		return middleware.StaffRoles
+	}
+	return middleware.AnyRole
+}
+
+// hasPathID reports whether the request names a resource below its
+// collection. "/customers/" and "/customers" both name the collection.
+func hasPathID(r *http.Request) bool {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	return len(parts) > 1 && parts[1] != ""
+}
@@ -0,0 +1,289 @@
+package router
+
+import (
+	"Book-Store/internal/authentication"
+	"Book-Store/internal/http/handlers"
+	"Book-Store/internal/http/middleware"
+	"Book-Store/internal/models"
+	"Book-Store/internal/reports"
+	"Book-Store/internal/store"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "router-test-secret"
+
+// Router registers on http.DefaultServeMux, so every test shares the one
+// server set up by TestMain.
+var (
+	testStore *store.MemStore
+	// accounts maps a role, or a customer's name, to its customer ID.
+	accounts = make(map[string]int)
+	// otherOrder is an order placed by the "other" account.
+	otherOrder int
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "router")
+	if err != nil {
+		log.Fatal(err)
+	}
+	code := runWithServer(m, dir)
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func runWithServer(m *testing.M, dir string) int {
+	testStore = store.NewMemStore()
+	if err := testStore.LoadFromFile(filepath.Join(dir, "database.json")); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, account := range []struct{ name, role string }{
+		{models.RoleCustomer, models.RoleCustomer},
+		{models.RoleStaff, models.RoleStaff},
+		{models.RoleAdmin, models.RoleAdmin},
+		{"other", models.RoleCustomer},
+		{"leaving", models.RoleCustomer},
+		{"closed", models.RoleCustomer},
+	} {
+		c, err := testStore.CreateCustomer(context.Background(), models.Customer{
+			Name: account.name, Email: account.name + "@example.com", Role: account.role,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		accounts[account.name] = c.ID
+	}
+
+	order, err := testStore.CreateOrder(context.Background(), models.Order{Customer: models.Customer{ID: accounts["other"]}})
+	if err != nil {
+		log.Fatal(err)
+	}
+	otherOrder = order.ID
+
+	apiCfg := &middleware.ApiConfig{Token: testSecret}
+	Router(
+		apiCfg,
+		&handlers.BookHandler{BookStore: testStore, AuthorStore: testStore, SeriesStore: testStore, ReviewStore: testStore},
+		&handlers.AuthorHandler{Store: testStore},
+		&handlers.SeriesHandler{Store: testStore},
+		&handlers.CustomerHandler{Store: testStore, Cfg: apiCfg},
+		&handlers.OrderHandler{Store: testStore},
+		&handlers.ReportHandler{OrderStore: testStore, ReportStore: reports.NewReportStore(filepath.Join(dir, "reports"))},
+		&handlers.MetricsHandler{BookStore: testStore, AuthorStore: testStore, CustomerStore: testStore, OrderStore: testStore},
+		&handlers.AuthHandler{Store: testStore, Tokens: testStore, Cfg: apiCfg},
+		&handlers.BackupHandler{Store: testStore},
+		&handlers.CartHandler{Store: testStore, Books: testStore, Tx: testStore},
+		apiCfg,
+	)
+	return m.Run()
+}
+
+// routeTest sends a request as the named account, or anonymously when as is
+// empty.
+type routeTest struct {
+	as     string
+	method string
+	target string
+	body   string
+	want   int
+}
+
+func runRouteTests(t *testing.T, tests []routeTest) {
+	t.Helper()
+	for _, tt := range tests {
+		name := tt.as
+		if name == "" {
+			name = "anonymous"
+		}
+		t.Run(name+" "+tt.method+" "+tt.target, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.as != "" {
+				id := accounts[tt.as]
+				c, err := testStore.GetCustomer(context.Background(), id)
+				if err != nil {
+					t.Fatal(err)
+				}
+				token, err := authentication.MakeJWT(id, c.EffectiveRole(), testSecret, time.Minute)
+				if err != nil {
+					t.Fatal(err)
+				}
+				r.Header.Set("Authorization", "Customer "+token)
+			}
+
+			w := httptest.NewRecorder()
+			http.DefaultServeMux.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func accountPath(prefix, name string) string {
+	return fmt.Sprintf("%s/%d", prefix, accounts[name])
+}
+
+func TestCatalogRoutes(t *testing.T) {
+	runRouteTests(t, []routeTest{
+		{"", http.MethodGet, "/books/", "", http.StatusOK},
+		{"", http.MethodPost, "/books/", "{", http.StatusUnauthorized},
+		{models.RoleCustomer, http.MethodPost, "/books/", "{", http.StatusForbidden},
+		{models.RoleStaff, http.MethodPost, "/books/", "{", http.StatusBadRequest},
+		{models.RoleCustomer, http.MethodDelete, "/authors/1", "", http.StatusForbidden},
+		{"", http.MethodGet, "/authors/", "", http.StatusOK},
+		{"", http.MethodGet, "/series/", "", http.StatusOK},
+		{"", http.MethodPost, "/books/1/reviews", "{", http.StatusUnauthorized},
+		{models.RoleCustomer, http.MethodPost, "/books/1/reviews", "{", http.StatusBadRequest},
+		{models.RoleCustomer, http.MethodPost, "/series/", "{", http.StatusForbidden},
+		{models.RoleStaff, http.MethodPost, "/series/", "{", http.StatusBadRequest},
+	})
+}
+
+func TestCustomerRoutes(t *testing.T) {
+	runRouteTests(t, []routeTest{
+		{"", http.MethodGet, "/customers", "", http.StatusUnauthorized},
+		{models.RoleCustomer, http.MethodGet, "/customers", "", http.StatusForbidden},
+		{models.RoleStaff, http.MethodGet, "/customers", "", http.StatusOK},
+		{"", http.MethodGet, accountPath("/customers", models.RoleCustomer), "", http.StatusUnauthorized},
+		{models.RoleCustomer, http.MethodGet, accountPath("/customers", models.RoleCustomer), "", http.StatusOK},
+		{models.RoleAdmin, http.MethodGet, accountPath("/customers", models.RoleCustomer), "", http.StatusOK},
+		// Other customers are hidden rather than forbidden.
+		{models.RoleCustomer, http.MethodGet, accountPath("/customers", "other"), "", http.StatusNotFound},
+		{models.RoleCustomer, http.MethodPut, accountPath("/customers", "other"), `{"name":"x"}`, http.StatusNotFound},
+		{models.RoleCustomer, http.MethodPut, accountPath("/customers", models.RoleCustomer), `{"role":"admin"}`, http.StatusForbidden},
+		{models.RoleAdmin, http.MethodPut, accountPath("/customers", "other"), `{"role":"boss"}`, http.StatusBadRequest},
+		// Deleting accounts is kept to staff, even for one's own.
+		{models.RoleCustomer, http.MethodDelete, accountPath("/customers", "other"), "", http.StatusForbidden},
+		{"leaving", http.MethodDelete, accountPath("/customers", "leaving"), "", http.StatusForbidden},
+		{models.RoleAdmin, http.MethodDelete, accountPath("/customers", "leaving"), "", http.StatusOK},
+		// Staff look after accounts but only admins edit them.
+		{models.RoleStaff, http.MethodGet, accountPath("/customers", "other"), "", http.StatusOK},
+		{models.RoleStaff, http.MethodPut, accountPath("/customers", "other"), `{"name":"x"}`, http.StatusNotFound},
+		{models.RoleStaff, http.MethodDelete, accountPath("/customers", "closed"), "", http.StatusOK},
+	})
+}
+
+func TestOrderRoutes(t *testing.T) {
+	runRouteTests(t, []routeTest{
+		{"", http.MethodPost, "/orders", "{", http.StatusUnauthorized},
+		{models.RoleCustomer, http.MethodGet, "/orders", "", http.StatusForbidden},
+		{models.RoleStaff, http.MethodGet, "/orders", "", http.StatusOK},
+		{models.RoleCustomer, http.MethodGet, "/orders/", "", http.StatusForbidden},
+		{"", http.MethodGet, "/orders/1", "", http.StatusUnauthorized},
+		{models.RoleCustomer, http.MethodPut, "/orders/1?status=completed", "", http.StatusForbidden},
+		{"other", http.MethodGet, fmt.Sprintf("/orders/%d", otherOrder), "", http.StatusOK},
+		{models.RoleStaff, http.MethodGet, fmt.Sprintf("/orders/%d", otherOrder), "", http.StatusOK},
+		{models.RoleCustomer, http.MethodGet, fmt.Sprintf("/orders/%d", otherOrder), "", http.StatusNotFound},
+		{models.RoleCustomer, http.MethodPut, fmt.Sprintf("/orders/%d?status=cancelled", otherOrder), "", http.StatusNotFound},
+	})
+}
+
+func TestReportRoutes(t *testing.T) {
+	runRouteTests(t, []routeTest{
+		{"", http.MethodGet, "/reports/sales", "", http.StatusUnauthorized},
+		{models.RoleCustomer, http.MethodGet, "/reports/sales", "", http.StatusForbidden},
+	})
+}
+
+func TestBackupRoutes(t *testing.T) {
+	runRouteTests(t, []routeTest{
+		{"", http.MethodGet, "/admin/backups", "", http.StatusUnauthorized},
+		{models.RoleStaff, http.MethodGet, "/admin/backups", "", http.StatusForbidden},
+		{models.RoleStaff, http.MethodPost, "/admin/backups/x/restore", "", http.StatusForbidden},
+		{models.RoleAdmin, http.MethodGet, "/admin/backups", "", http.StatusOK},
+	})
+}
+
+func TestInvalidToken(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/customers", nil)
+	r.Header.Set("Authorization", "Customer not-a-token")
+	w := httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+type policyTest struct {
+	method string
+	target string
+	want   []string
+}
+
+func runPolicyTests(t *testing.T, policy middleware.Policy, tests []policyTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, nil)
+			if got := policy(r); !slices.Equal(got, tt.want) {
+				t.Errorf("policy = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderPolicy(t *testing.T) {
+	// "/orders/" reaches orderPolicy rather than the exact "/orders" route,
+	// so it must not open the listing to customers.
+	runPolicyTests(t, orderPolicy, []policyTest{
+		{http.MethodGet, "/orders/", middleware.StaffRoles},
+		{http.MethodGet, "/orders//", middleware.StaffRoles},
+		{http.MethodGet, "/orders/?customer_id=2", middleware.StaffRoles},
+		{http.MethodGet, "/orders/7", middleware.AnyRole},
+		{http.MethodGet, "/orders/7/", middleware.AnyRole},
+		{http.MethodPost, "/orders/", middleware.AnyRole},
+		{http.MethodPut, "/orders/7?status=cancelled", middleware.AnyRole},
+		{http.MethodPut, "/orders/7?status=completed", middleware.StaffRoles},
+	})
+}
+
+func TestCustomerPolicy(t *testing.T) {
+	// Reading and updating one customer is public to the policy; the handler
+	// checks ownership.
+	runPolicyTests(t, customerPolicy, []policyTest{
+		{http.MethodGet, "/customers/", middleware.StaffRoles},
+		{http.MethodGet, "/customers//", middleware.StaffRoles},
+		{http.MethodGet, "/customers/?limit=5", middleware.StaffRoles},
+		{http.MethodGet, "/customers/3", nil},
+		{http.MethodGet, "/customers/3/recommendations", nil},
+		{http.MethodPut, "/customers/3", nil},
+		{http.MethodDelete, "/customers/3", middleware.StaffRoles},
+		{http.MethodDelete, "/customers/", middleware.StaffRoles},
+	})
+}
+
+func TestCartPolicy(t *testing.T) {
+	runPolicyTests(t, cartPolicy, []policyTest{
+		{http.MethodGet, "/cart", nil},
+		{http.MethodGet, "/cart/", nil},
+		{http.MethodPost, "/cart/items", nil},
+		{http.MethodPut, "/cart/items/4", nil},
+		{http.MethodPost, "/cart/checkout", middleware.AnyRole},
+		{http.MethodPost, "/cart/checkout/", middleware.AnyRole},
+	})
+}
+
+func TestBookPolicy(t *testing.T) {
+	catalog := middleware.MethodPolicy(map[string][]string{
+		http.MethodPost: middleware.StaffRoles,
+	})
+	runPolicyTests(t, bookPolicy(catalog), []policyTest{
+		{http.MethodGet, "/books/", nil},
+		{http.MethodPost, "/books/", middleware.StaffRoles},
+		{http.MethodGet, "/books/4/reviews", nil},
+		{http.MethodPost, "/books/4/reviews", middleware.AnyRole},
+		{http.MethodPost, "/books/4/reviews/", middleware.AnyRole},
+		{http.MethodDelete, "/books/4/reviews/9", middleware.AnyRole},
+	})
+}
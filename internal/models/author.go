@@ -2,7 +2,18 @@ package models
 
 type Author struct {
 	ID        int    `json:"id"`
+	PublicID  string `json:"public_id,omitempty"`
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	Bio       string `json:"bio"`
 }
+
+// AuthorDeletion says what happens to the books that credit an author being
+// deleted. With neither option set, the deletion is refused while any do.
+type AuthorDeletion struct {
+	// Cascade removes the author's credits and deletes books left without
+	// an author.
+	Cascade bool
+	// ReassignTo moves the author's credits to another author.
+	ReassignTo *int
+}
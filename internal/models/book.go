@@ -1,13 +1,102 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	FormatHardcover = "hardcover"
+	FormatPaperback = "paperback"
+	FormatEbook     = "ebook"
+	FormatAudiobook = "audiobook"
+)
 
 type Book struct {
 	ID          int       `json:"id"`
+	PublicID    string    `json:"public_id,omitempty"`
 	Title       string    `json:"title"`
 	Author      Author    `json:"author"`
 	Genres      []string  `json:"genres"`
 	PublishedAt time.Time `json:"published_at"`
 	Price       float64   `json:"price"`
 	Stock       int       `json:"stock"`
+
+	// Contributors credits every author of the book with a role. Author is
+	// the first of them with the author role.
+	Contributors []Contributor `json:"contributors"`
+	// Series is nil for standalone books.
+	Series *SeriesEntry `json:"series"`
+	// Cover is nil until a cover image is uploaded.
+	Cover *BookCover `json:"cover"`
+	// Rating summarizes the reviews of the book and is kept by the store.
+	Rating RatingSummary `json:"rating"`
+
+	// ISBN is stored as a bare ISBN-13 and is unique across the catalog.
+	ISBN      string `json:"isbn"`
+	Publisher string `json:"publisher"`
+	// Language is an ISO 639 code such as "en".
+	Language  string `json:"language"`
+	PageCount int    `json:"page_count"`
+	Format    string `json:"format"`
+	Edition   string `json:"edition"`
+
+	// Score is the relevance of the book to a full-text search query.
+	Score float64 `json:"score,omitempty"`
+}
+
+func IsValidBookFormat(format string) bool {
+	switch format {
+	case FormatHardcover, FormatPaperback, FormatEbook, FormatAudiobook:
+		return true
+	default:
+		return false
+	}
+}
+
+// NormalizeMetadata validates the optional publishing details of a book and
+// puts the ISBN, language and format into their stored form.
+func (b *Book) NormalizeMetadata() error {
+	if b.ISBN != "" {
+		isbn, err := NormalizeISBN(b.ISBN)
+		if err != nil {
+			return err
+		}
+		b.ISBN = isbn
+	}
+
+	b.Language = strings.ToLower(strings.TrimSpace(b.Language))
+	if b.Language != "" && !isLanguageCode(b.Language) {
+		return errors.New("language must be an ISO 639 code")
+	}
+
+	b.Format = strings.ToLower(strings.TrimSpace(b.Format))
+	if b.Format != "" && !IsValidBookFormat(b.Format) {
+		return errors.New("format must be one of hardcover, paperback, ebook, audiobook")
+	}
+
+	if b.PageCount < 0 {
+		return errors.New("page_count cannot be negative")
+	}
+
+	if b.Series != nil && b.Series.Position < 0 {
+		return errors.New("series position cannot be negative")
+	}
+
+	b.Publisher = strings.TrimSpace(b.Publisher)
+	b.Edition = strings.TrimSpace(b.Edition)
+	return nil
+}
+
+func isLanguageCode(code string) bool {
+	if len(code) != 2 && len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
 }
@@ -0,0 +1,29 @@
+package models
+
+const (
+	ImportCreated  = "created"
+	ImportUpdated  = "updated"
+	ImportRejected = "rejected"
+)
+
+// BookImportResult is the outcome of one row of an import file. Row counts
+// records from 1, not counting a CSV header.
+type BookImportResult struct {
+	Row      int    `json:"row"`
+	Status   string `json:"status"`
+	Title    string `json:"title,omitempty"`
+	BookID   *int   `json:"book_id,omitempty"`
+	PublicID string `json:"public_id,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// BookImportReport sums up an import. A dry run reports what would have
+// happened without writing anything, so its rows carry no book IDs.
+type BookImportReport struct {
+	DryRun         bool               `json:"dry_run"`
+	Created        int                `json:"created"`
+	Updated        int                `json:"updated"`
+	Rejected       int                `json:"rejected"`
+	AuthorsCreated int                `json:"authors_created"`
+	Rows           []BookImportResult `json:"rows"`
+}
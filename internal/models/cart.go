@@ -0,0 +1,48 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// CustomerCartKey and SessionCartKey name the cart of a signed-in customer
+// and of an anonymous session in the cart store.
+func CustomerCartKey(customerID int) string {
+	return "customer:" + strconv.Itoa(customerID)
+}
+
+func SessionCartKey(session string) string {
+	return "session:" + session
+}
+
+type CartItem struct {
+	BookID   int `json:"book_id"`
+	Quantity int `json:"quantity"`
+}
+
+// Cart holds the books a customer means to buy. It only records what was
+// added; prices and stock are looked up when it is shown or checked out.
+type Cart struct {
+	Items     []CartItem `json:"items"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// CartLine is a cart item with the book's current details.
+type CartLine struct {
+	Book     Book    `json:"book"`
+	Quantity int     `json:"quantity"`
+	Subtotal float64 `json:"subtotal"`
+	// Available reports whether enough copies are in stock.
+	Available bool `json:"available"`
+}
+
+// CartView is a cart priced at current prices. Books deleted from the
+// catalog since they were added are left out.
+type CartView struct {
+	Items []CartLine `json:"items"`
+	Total float64    `json:"total"`
+	// Session identifies an anonymous cart; clients send it back in the
+	// X-Cart-Session header, including when they log in.
+	Session   string    `json:"session,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
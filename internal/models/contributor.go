@@ -0,0 +1,83 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	ContributorAuthor      = "author"
+	ContributorEditor      = "editor"
+	ContributorTranslator  = "translator"
+	ContributorIllustrator = "illustrator"
+	ContributorNarrator    = "narrator"
+)
+
+// Contributor credits an author with a role on a book.
+type Contributor struct {
+	Author Author `json:"author"`
+	Role   string `json:"role"`
+}
+
+func IsValidContributorRole(role string) bool {
+	switch role {
+	case ContributorAuthor, ContributorEditor, ContributorTranslator, ContributorIllustrator, ContributorNarrator:
+		return true
+	default:
+		return false
+	}
+}
+
+// NormalizeContributors validates the credits of a book. A book given only
+// an Author gets it as its sole contributor; otherwise Author becomes the
+// first contributor with the author role. Roles default to author.
+func (b *Book) NormalizeContributors() error {
+	if len(b.Contributors) == 0 {
+		b.Contributors = []Contributor{{Author: b.Author, Role: ContributorAuthor}}
+		return nil
+	}
+
+	contributors := make([]Contributor, 0, len(b.Contributors))
+	for _, c := range b.Contributors {
+		c.Role = strings.ToLower(strings.TrimSpace(c.Role))
+		if c.Role == "" {
+			c.Role = ContributorAuthor
+		}
+		if !IsValidContributorRole(c.Role) {
+			return fmt.Errorf("unknown contributor role %q", c.Role)
+		}
+		for _, other := range contributors {
+			if other.Author.ID == c.Author.ID && other.Role == c.Role {
+				return fmt.Errorf("an author is listed twice as %s", c.Role)
+			}
+		}
+		contributors = append(contributors, c)
+	}
+
+	for _, c := range contributors {
+		if c.Role == ContributorAuthor {
+			b.Author = c.Author
+			b.Contributors = contributors
+			return nil
+		}
+	}
+	return errors.New("a book needs at least one contributor with the author role")
+}
+
+// CreditedAuthors lists every author credited on the book once, in credit
+// order.
+func (b Book) CreditedAuthors() []Author {
+	if len(b.Contributors) == 0 {
+		return []Author{b.Author}
+	}
+
+	authors := make([]Author, 0, len(b.Contributors))
+	for _, c := range b.Contributors {
+		if !slices.ContainsFunc(authors, func(a Author) bool { return a.ID == c.Author.ID }) {
+			authors = append(authors, c.Author)
+		}
+	}
+	return authors
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeContributors(t *testing.T) {
+	lem, kandel := Author{ID: 1, LastName: "Lem"}, Author{ID: 2, LastName: "Kandel"}
+
+	tests := []struct {
+		name       string
+		book       Book
+		wantAuthor Author
+		want       []Contributor
+		wantErr    bool
+		// errMsg is the message shown to the client, when fixed.
+		errMsg string
+	}{
+		{
+			name:       "single author",
+			book:       Book{Author: lem},
+			wantAuthor: lem,
+			want:       []Contributor{{Author: lem, Role: ContributorAuthor}},
+		},
+		{
+			name:       "author follows a translator",
+			book:       Book{Contributors: []Contributor{{Author: kandel, Role: " Translator "}, {Author: lem}}},
+			wantAuthor: lem,
+			want:       []Contributor{{Author: kandel, Role: ContributorTranslator}, {Author: lem, Role: ContributorAuthor}},
+		},
+		{
+			name:       "one author in two roles",
+			book:       Book{Contributors: []Contributor{{Author: lem}, {Author: lem, Role: ContributorIllustrator}}},
+			wantAuthor: lem,
+			want:       []Contributor{{Author: lem, Role: ContributorAuthor}, {Author: lem, Role: ContributorIllustrator}},
+		},
+		{name: "unknown role", book: Book{Contributors: []Contributor{{Author: lem, Role: "ghost"}}}, wantErr: true},
+		{
+			name:    "listed twice",
+			book:    Book{Contributors: []Contributor{{Author: lem}, {Author: lem, Role: "author"}}},
+			wantErr: true,
+			errMsg:  "an author is listed twice as author",
+		},
+		{name: "no author role", book: Book{Contributors: []Contributor{{Author: kandel, Role: ContributorEditor}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := tt.book
+			err := book.NormalizeContributors()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NormalizeContributors() = %+v, want an error", book.Contributors)
+				}
+				if tt.errMsg != "" && err.Error() != tt.errMsg {
+					t.Errorf("error = %q, want %q", err, tt.errMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if book.Author != tt.wantAuthor || !reflect.DeepEqual(book.Contributors, tt.want) {
+				t.Errorf("NormalizeContributors() = %+v, %+v; want %+v, %+v", book.Author, book.Contributors, tt.wantAuthor, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// BookCover describes the cover image of a book. The image and its resized
+// variants are kept as files; the store only records that they exist.
+type BookCover struct {
+	// Format is the image format, "jpeg" or "png".
+	Format    string    `json:"format"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
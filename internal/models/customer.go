@@ -4,9 +4,19 @@ import "time"
 
 type Customer struct {
 	ID        int       `json:"id"`
+	PublicID  string    `json:"public_id,omitempty"`
 	Name      string    `json:"name"`
 	Email     string    `json:"email"`
-	Password  string    `json:"password"`
+	Password  string    `json:"password,omitempty"`
+	Role      string    `json:"role"`
 	Address   Address   `json:"address"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// EffectiveRole treats customers saved before roles existed as plain customers.
+func (c Customer) EffectiveRole() string {
+	if c.Role == "" {
+		return RoleCustomer
+	}
+	return c.Role
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeISBN(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{name: "ISBN-13", raw: "9780306406157", want: "9780306406157"},
+		{name: "ISBN-13 with hyphens", raw: "978-0-306-40615-7", want: "9780306406157"},
+		{name: "ISBN-13 with spaces", raw: "978 0 306 40615 7", want: "9780306406157"},
+		{name: "979 prefix", raw: "979-10-90636-07-1", want: "9791090636071"},
+		{name: "ISBN-10 becomes ISBN-13", raw: "0-306-40615-2", want: "9780306406157"},
+		{name: "ISBN-10 with X check digit", raw: "080442957X", want: "9780804429573"},
+		{name: "ISBN-10 with lowercase x", raw: "080442957x", want: "9780804429573"},
+		{name: "ISBN-13 bad checksum", raw: "9780306406158", wantErr: true},
+		{name: "ISBN-10 bad checksum", raw: "0306406153", wantErr: true},
+		{name: "ISBN-13 wrong prefix", raw: "9770306406157", wantErr: true},
+		{name: "X inside ISBN-10", raw: "03064X6152", wantErr: true},
+		{name: "letters in ISBN-13", raw: "978030640615A", wantErr: true},
+		{name: "too short", raw: "030640615", wantErr: true},
+		{name: "too long", raw: "97803064061570", wantErr: true},
+		{name: "empty", raw: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeISBN(tt.raw)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidISBN) {
+					t.Errorf("NormalizeISBN(%q) = %q, %v, want ErrInvalidISBN", tt.raw, got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("NormalizeISBN(%q) = %q, %v, want %q", tt.raw, got, err, tt.want)
+			}
+		})
+	}
+}
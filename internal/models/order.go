@@ -9,6 +9,7 @@ type OrderItem struct {
 
 type Order struct {
 	ID         int         `json:"id"`
+	PublicID   string      `json:"public_id,omitempty"`
 	Customer   Customer    `json:"customer"`
 	Items      []OrderItem `json:"items"`
 	TotalPrice float64     `json:"total_price"`
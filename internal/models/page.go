@@ -0,0 +1,16 @@
+package models
+
+// PageRequest asks for up to Limit items following Cursor, the opaque
+// next_cursor of a previous page. A Limit of zero or less means everything.
+type PageRequest struct {
+	Limit  int
+	Cursor string
+}
+
+// Page is one slice of an ordered listing. Total counts every item in the
+// listing, not just this page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int    `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// Recommendation is a book suggested from purchase data. For a book, Score
+// counts the completed orders that contained both books; for a customer it
+// blends co-purchases with genre and author affinity into a value in [0, 1].
+type Recommendation struct {
+	Book  Book    `json:"book"`
+	Score float64 `json:"score"`
+}
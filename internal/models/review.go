@@ -0,0 +1,59 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// Review is a customer's rating of a book they bought, with optional text.
+type Review struct {
+	ID         int    `json:"id"`
+	PublicID   string `json:"public_id,omitempty"`
+	BookID     int    `json:"book_id"`
+	CustomerID int    `json:"customer_id"`
+	// CustomerName is shown as the reviewer; their email stays private.
+	CustomerName string `json:"customer_name"`
+
+	Rating int    `json:"rating"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Normalize validates the rating and trims the text of a review.
+func (r *Review) Normalize() error {
+	if r.Rating < MinRating || r.Rating > MaxRating {
+		return errors.New("rating must be between 1 and 5")
+	}
+	r.Title = strings.TrimSpace(r.Title)
+	r.Body = strings.TrimSpace(r.Body)
+	return nil
+}
+
+// RatingSummary aggregates the reviews of a book.
+type RatingSummary struct {
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+	// Histogram counts reviews per rating; index 0 holds one-star reviews.
+	Histogram [MaxRating]int `json:"histogram"`
+}
+
+// Add counts a review with rating into the summary.
+func (s *RatingSummary) Add(rating int) {
+	s.Histogram[rating-MinRating]++
+	s.Count++
+
+	total := 0
+	for i, n := range s.Histogram {
+		total += (i + MinRating) * n
+	}
+	s.Average = float64(total) / float64(s.Count)
+}
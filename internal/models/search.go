@@ -1,13 +1,107 @@
 package models
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Book sort keys.
+const (
+	SortTitle       = "title"
+	SortPrice       = "price"
+	SortPublishedAt = "published_at"
+	SortStock       = "stock"
+	SortAuthor      = "author"
+	SortPopularity  = "popularity"
+	SortRelevance   = "relevance"
+	SortRating      = "rating"
+)
+
+var bookSortKeys = []string{
+	SortTitle, SortPrice, SortPublishedAt, SortStock, SortAuthor, SortPopularity, SortRelevance, SortRating,
+}
+
+// SortKey is one key of a multi-key sort. Author sorts by last name,
+// popularity by units sold in completed orders and rating by average review
+// rating.
+type SortKey struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
+}
+
+// ParseSort reads a comma separated list of sort keys, each optionally
+// prefixed with - for descending order, such as "-price,title".
+func ParseSort(spec string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		key := SortKey{Field: strings.ToLower(part)}
+		if strings.HasPrefix(key.Field, "-") {
+			key.Field = key.Field[1:]
+			key.Desc = true
+		}
+		if !slices.Contains(bookSortKeys, key.Field) {
+			return nil, fmt.Errorf("unknown sort key %q, expected one of %s",
+				key.Field, strings.Join(bookSortKeys, ", "))
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
 type SearchCriteria struct {
-	Title  string `json:"title"`
-	Author string `json:"author"`
-	Genre  string `json:"genre"`
+	// Query is free text matched against titles, author names and bios and
+	// genres, with results ranked by relevance.
+	Query  string   `json:"q"`
+	Title  string   `json:"title"`
+	Author string   `json:"author"`
+	Genres []string `json:"genres"`
+	// AuthorID limits results to the books crediting one author in any
+	// role, as picked from the author facet.
+	AuthorID *int `json:"author_id"`
 
 	MinPrice *float64 `json:"min_price"`
 	MaxPrice *float64 `json:"max_price"`
+	InStock  *bool    `json:"in_stock"`
+	// MinRating keeps books whose average review rating is at least this.
+	MinRating *float64 `json:"min_rating"`
+	// Series limits results to the books of one series.
+	Series *int `json:"series"`
+
+	// Sort lists the sort keys in priority order. Results sort by relevance
+	// when it is empty and Query is set, and by ID otherwise.
+	Sort []SortKey `json:"sort"`
+
+	Limit  int    `json:"limit"`
+	Cursor string `json:"cursor"`
+}
+
+// BookSearchResult is a page of books matching a search together with facet
+// counts over every match.
+type BookSearchResult struct {
+	Books      []Book     `json:"books"`
+	Facets     BookFacets `json:"facets"`
+	Total      int        `json:"total"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
+
+type BookFacets struct {
+	Genres  []FacetCount `json:"genres"`
+	Authors []FacetCount `json:"authors"`
+	Prices  []FacetCount `json:"prices"`
+	Stock   []FacetCount `json:"stock"`
+}
 
-	SortBy    string `json:"sort_by"`
-	SortOrder string `json:"sort_order"`
+// FacetCount is the number of matching books sharing a value. Label is a
+// display name when Value is an ID; author IDs are public IDs when those are
+// enabled, so they can be passed back as author_id.
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int    `json:"count"`
 }
@@ -0,0 +1,18 @@
+package models
+
+type Series struct {
+	ID          int    `json:"id"`
+	PublicID    string `json:"public_id,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// SeriesEntry places a book in a series.
+type SeriesEntry struct {
+	ID       int    `json:"id"`
+	PublicID string `json:"public_id,omitempty"`
+	Name     string `json:"name"`
+	// Position is the place of the book in reading order. Fractions fit a
+	// novella in between two volumes.
+	Position float64 `json:"position"`
+}
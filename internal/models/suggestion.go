@@ -0,0 +1,15 @@
+package models
+
+const (
+	SuggestionTitle  = "title"
+	SuggestionAuthor = "author"
+)
+
+// Suggestion is an autocomplete entry for a book title or an author name.
+// ID refers to the book or author depending on Type.
+type Suggestion struct {
+	Type     string `json:"type"`
+	ID       int    `json:"id"`
+	PublicID string `json:"public_id,omitempty"`
+	Text     string `json:"text"`
+}
@@ -0,0 +1,191 @@
+// Package pagination orders listings and splits them into pages with keyset
+// cursors. A cursor records the sort values of the last item returned, so a
+// page starts after that position and inserts elsewhere don't shift it.
+package pagination
+
+import (
+	"Book-Store/internal/models"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Field is one sort key of an Order. Value must return a string or a
+// float64.
+type Field[T any] struct {
+	Value func(T) any
+	Desc  bool
+}
+
+// Order sorts by its fields in turn and then by ID, so every item has a
+// distinct position. ID may be nil when the fields alone are unique.
+type Order[T any] struct {
+	Fields []Field[T]
+	ID     func(T) int
+}
+
+func (o Order[T]) key(item T) []any {
+	key := make([]any, 0, o.keyLen())
+	for _, field := range o.Fields {
+		key = append(key, field.Value(item))
+	}
+	if o.ID != nil {
+		key = append(key, float64(o.ID(item)))
+	}
+	return key
+}
+
+func (o Order[T]) keyLen() int {
+	if o.ID == nil {
+		return len(o.Fields)
+	}
+	return len(o.Fields) + 1
+}
+
+// compare orders two keys built by key or decoded from a cursor.
+func (o Order[T]) compare(a, b []any) int {
+	for i := range a {
+		c := compareValues(a[i], b[i])
+		if i < len(o.Fields) && o.Fields[i].Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareValues(a, b any) int {
+	switch av := a.(type) {
+	case string:
+		return strings.Compare(av, b.(string))
+	case float64:
+		bv := b.(float64)
+		switch {
+		case av < bv:
+			return -1
+		case av > bv:
+			return 1
+		}
+	}
+	return 0
+}
+
+// Sort puts items into the order, in place.
+func (o Order[T]) Sort(items []T) {
+	type keyed struct {
+		item T
+		key  []any
+	}
+	sorted := make([]keyed, len(items))
+	for i, item := range items {
+		sorted[i] = keyed{item: item, key: o.key(item)}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return o.compare(sorted[i].key, sorted[j].key) < 0
+	})
+	for i := range sorted {
+		items[i] = sorted[i].item
+	}
+}
+
+// Page returns the items of a sorted listing that follow the cursor.
+func (o Order[T]) Page(items []T, req models.PageRequest) (models.Page[T], error) {
+	start := 0
+	if req.Cursor != "" {
+		after, err := o.decode(req.Cursor)
+		if err != nil {
+			return models.Page[T]{}, err
+		}
+		if len(items) > 0 && !sameTypes(after, o.key(items[0])) {
+			return models.Page[T]{}, ErrInvalidCursor
+		}
+		start = sort.Search(len(items), func(i int) bool {
+			return o.compare(o.key(items[i]), after) > 0
+		})
+	}
+
+	page := models.Page[T]{Items: items[start:], Total: len(items)}
+	if req.Limit > 0 && len(page.Items) > req.Limit {
+		page.Items = page.Items[:req.Limit]
+		page.NextCursor = o.Cursor(page.Items[len(page.Items)-1])
+	}
+	if page.Items == nil {
+		page.Items = make([]T, 0)
+	}
+	return page, nil
+}
+
+// AfterID decodes the cursor of an order with no fields into the ID it
+// points at, for stores that page in SQL with WHERE id > ?.
+func (o Order[T]) AfterID(cursor string) (int, error) {
+	if len(o.Fields) != 0 || o.ID == nil {
+		return 0, ErrInvalidCursor
+	}
+	key, err := o.decode(cursor)
+	if err != nil {
+		return 0, err
+	}
+	id, ok := key[0].(float64)
+	if !ok {
+		return 0, ErrInvalidCursor
+	}
+	return int(id), nil
+}
+
+// PageFrom builds a page from items already read after the cursor, with one
+// item beyond limit when another page follows.
+func (o Order[T]) PageFrom(items []T, total, limit int) models.Page[T] {
+	page := models.Page[T]{Items: items, Total: total}
+	if limit > 0 && len(items) > limit {
+		page.Items = items[:limit]
+		page.NextCursor = o.Cursor(page.Items[limit-1])
+	}
+	if page.Items == nil {
+		page.Items = make([]T, 0)
+	}
+	return page
+}
+
+func sameTypes(a, b []any) bool {
+	for i := range a {
+		switch a[i].(type) {
+		case string:
+			if _, ok := b[i].(string); !ok {
+				return false
+			}
+		case float64:
+			if _, ok := b[i].(float64); !ok {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Cursor encodes the position of item for the next page to start after.
+func (o Order[T]) Cursor(item T) string {
+	data, _ := json.Marshal(o.key(item))
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decode reads a cursor, rejecting any made for a different order.
+func (o Order[T]) decode(cursor string) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var key []any
+	if err := json.Unmarshal(data, &key); err != nil || len(key) != o.keyLen() {
+		return nil, ErrInvalidCursor
+	}
+	return key, nil
+}
@@ -0,0 +1,196 @@
+package pagination
+
+import (
+	"Book-Store/internal/models"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"testing"
+)
+
+type item struct {
+	id    int
+	name  string
+	price float64
+}
+
+var byPriceDesc = Order[item]{
+	Fields: []Field[item]{{Value: func(i item) any { return i.price }, Desc: true}},
+	ID:     func(i item) int { return i.id },
+}
+
+var byName = Order[item]{
+	Fields: []Field[item]{{Value: func(i item) any { return i.name }}},
+	ID:     func(i item) int { return i.id },
+}
+
+var byID = Order[item]{ID: func(i item) int { return i.id }}
+
+func ids(items []item) []int {
+	out := make([]int, 0, len(items))
+	for _, i := range items {
+		out = append(out, i.id)
+	}
+	return out
+}
+
+func TestSort(t *testing.T) {
+	items := []item{
+		{id: 3, name: "c", price: 5},
+		{id: 1, name: "a", price: 10},
+		{id: 4, name: "b", price: 5},
+		{id: 2, name: "b", price: 1},
+	}
+
+	tests := []struct {
+		name  string
+		order Order[item]
+		want  []int
+	}{
+		{"descending field, ties by ID", byPriceDesc, []int{1, 3, 4, 2}},
+		{"ascending field, ties by ID", byName, []int{1, 2, 4, 3}},
+		{"ID only", byID, []int{1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted := slices.Clone(items)
+			tt.order.Sort(sorted)
+			if got := ids(sorted); !slices.Equal(got, tt.want) {
+				t.Errorf("Sort() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageWalk(t *testing.T) {
+	items := []item{
+		{id: 1, price: 10}, {id: 2, price: 9}, {id: 3, price: 9},
+		{id: 4, price: 7}, {id: 5, price: 1},
+	}
+	byPriceDesc.Sort(items)
+
+	tests := []struct {
+		limit int
+		pages int
+	}{
+		{limit: 0, pages: 1},
+		{limit: 1, pages: 5},
+		{limit: 2, pages: 3},
+		{limit: 5, pages: 1},
+		{limit: 10, pages: 1},
+	}
+	for _, tt := range tests {
+		var seen []int
+		cursor := ""
+		pages := 0
+		for {
+			page, err := byPriceDesc.Page(items, models.PageRequest{Limit: tt.limit, Cursor: cursor})
+			if err != nil {
+				t.Fatalf("limit %d: Page() error = %v", tt.limit, err)
+			}
+			if page.Total != len(items) {
+				t.Errorf("limit %d: Total = %d, want %d", tt.limit, page.Total, len(items))
+			}
+			pages++
+			seen = append(seen, ids(page.Items)...)
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+
+		if want := ids(items); !slices.Equal(seen, want) {
+			t.Errorf("limit %d: walked %v, want %v", tt.limit, seen, want)
+		}
+		if pages != tt.pages {
+			t.Errorf("limit %d: %d pages, want %d", tt.limit, pages, tt.pages)
+		}
+	}
+}
+
+func TestPageCursorIsStableAcrossInserts(t *testing.T) {
+	items := []item{{id: 1, name: "b"}, {id: 2, name: "d"}, {id: 3, name: "f"}}
+	byName.Sort(items)
+
+	first, err := byName.Page(items, models.PageRequest{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An item sorting before the cursor must not shift the next page.
+	items = append(items, item{id: 4, name: "a"}, item{id: 5, name: "e"})
+	byName.Sort(items)
+
+	next, err := byName.Page(items, models.PageRequest{Limit: 2, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ids(next.Items), []int{5, 3}; !slices.Equal(got, want) {
+		t.Errorf("next page = %v, want %v", got, want)
+	}
+}
+
+func TestInvalidCursor(t *testing.T) {
+	items := []item{{id: 1, name: "a"}}
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"not JSON", encode("nope")},
+		{"too short", encode(`["a"]`)},
+		{"too long", encode(`["a",1,2]`)},
+		{"wrong types", encode(`[1,"a"]`)},
+		{"cursor of another order", byPriceDesc.Cursor(item{id: 1, price: 3})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := byName.Page(items, models.PageRequest{Cursor: tt.cursor})
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Page() error = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestAfterID(t *testing.T) {
+	id, err := byID.AfterID(byID.Cursor(item{id: 42}))
+	if err != nil || id != 42 {
+		t.Errorf("AfterID() = %d, %v, want 42", id, err)
+	}
+
+	if _, err := byName.AfterID(byName.Cursor(item{id: 42})); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("AfterID() on an order with fields: error = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestPageFrom(t *testing.T) {
+	tests := []struct {
+		name       string
+		items      []item
+		limit      int
+		want       []int
+		wantCursor bool
+	}{
+		{"more follow", []item{{id: 1}, {id: 2}, {id: 3}}, 2, []int{1, 2}, true},
+		{"last page", []item{{id: 1}, {id: 2}}, 2, []int{1, 2}, false},
+		{"no limit", []item{{id: 1}, {id: 2}}, 0, []int{1, 2}, false},
+		{"empty", nil, 2, []int{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := byID.PageFrom(tt.items, 10, tt.limit)
+			if got := ids(page.Items); !slices.Equal(got, tt.want) {
+				t.Errorf("Items = %v, want %v", got, tt.want)
+			}
+			if page.Items == nil {
+				t.Error("Items is nil, want an empty slice")
+			}
+			if (page.NextCursor != "") != tt.wantCursor {
+				t.Errorf("NextCursor = %q, want one: %v", page.NextCursor, tt.wantCursor)
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package recommendations
+
+import (
+	"Book-Store/internal/models"
+	"Book-Store/internal/store"
+	"context"
+	"slices"
+	"sync"
+)
+
+// Weights of the signals blended into customer recommendations. Books bought
+// together with the customer's purchases count most; shared genres and
+// authors bring in books nobody has paired with them yet.
+const (
+	coPurchaseWeight = 0.5
+	genreWeight      = 0.3
+	authorWeight     = 0.2
+)
+
+// Recommender suggests books from what customers bought in completed orders.
+// Refresh rebuilds its model from the order store and RecordOrder folds in
+// orders completed since; recommendations are drawn from the live catalog so
+// new books are considered and deleted or out-of-stock ones never suggested.
+type Recommender struct {
+	orders store.OrderStore
+	books  store.BookStore
+
+	// refreshing serializes Refresh calls.
+	refreshing sync.Mutex
+
+	mu sync.RWMutex
+	// together counts, for each pair of books, the completed orders that
+	// contained both.
+	together map[int]map[int]int
+	// purchases holds the books each customer bought, as sold to them.
+	purchases map[int]map[int]models.Book
+	// counted holds the IDs of the orders in the model, so none is counted
+	// twice.
+	counted map[int]bool
+	// recorded collects the orders RecordOrder sees while a Refresh is
+	// reading the order store; nil otherwise.
+	recorded []models.Order
+}
+
+func NewRecommender(orders store.OrderStore, books store.BookStore) *Recommender {
+	return &Recommender{
+		orders:    orders,
+		books:     books,
+		together:  make(map[int]map[int]int),
+		purchases: make(map[int]map[int]models.Book),
+		counted:   make(map[int]bool),
+	}
+}
+
+// Refresh rebuilds the model from every completed order. Orders recorded
+// while it reads the order store are replayed onto the new model, so none
+// is lost to the swap.
+func (rec *Recommender) Refresh(ctx context.Context) error {
+	rec.refreshing.Lock()
+	defer rec.refreshing.Unlock()
+
+	rec.mu.Lock()
+	rec.recorded = make([]models.Order, 0)
+	rec.mu.Unlock()
+
+	orders, err := rec.orders.SearchOrderByStatus(ctx, "completed", models.PageRequest{})
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	recorded := rec.recorded
+	rec.recorded = nil
+	if err != nil {
+		return err
+	}
+
+	together := make(map[int]map[int]int)
+	purchases := make(map[int]map[int]models.Book)
+	counted := make(map[int]bool)
+	for _, order := range orders.Items {
+		addOrder(together, purchases, counted, order)
+	}
+	for _, order := range recorded {
+		addOrder(together, purchases, counted, order)
+	}
+
+	rec.together = together
+	rec.purchases = purchases
+	rec.counted = counted
+	return nil
+}
+
+// RecordOrder adds a newly completed order to the model without waiting for
+// the next Refresh.
+func (rec *Recommender) RecordOrder(order models.Order) {
+	if order.Status != "completed" {
+		return
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if rec.recorded != nil {
+		rec.recorded = append(rec.recorded, order)
+	}
+	addOrder(rec.together, rec.purchases, rec.counted, order)
+}
+
+// addOrder counts order into the model unless counted already holds it.
+func addOrder(together map[int]map[int]int, purchases map[int]map[int]models.Book, counted map[int]bool, order models.Order) {
+	if counted[order.ID] {
+		return
+	}
+	counted[order.ID] = true
+
+	// A book listed twice in one order still makes one co-purchase.
+	books := make(map[int]models.Book, len(order.Items))
+	for _, item := range order.Items {
+		books[item.Book.ID] = item.Book
+	}
+
+	for id := range books {
+		for other := range books {
+			if id == other {
+				continue
+			}
+			if together[id] == nil {
+				together[id] = make(map[int]int)
+			}
+			together[id][other]++
+		}
+	}
+
+	bought := purchases[order.Customer.ID]
+	if bought == nil {
+		bought = make(map[int]models.Book)
+		purchases[order.Customer.ID] = bought
+	}
+	for id, book := range books {
+		bought[id] = book
+	}
+}
+
+// ForBook returns up to limit books most often bought together with bookID.
+func (rec *Recommender) ForBook(ctx context.Context, bookID, limit int) ([]models.Recommendation, error) {
+	rec.mu.RLock()
+	scores := make(map[int]float64, len(rec.together[bookID]))
+	for id, count := range rec.together[bookID] {
+		scores[id] = float64(count)
+	}
+	rec.mu.RUnlock()
+
+	recommendations := top(scores, limit, func(id int) (models.Book, bool) {
+		book, err := rec.books.GetBook(ctx, id)
+		return book, err == nil && book.Stock > 0
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return recommendations, nil
+}
+
+// ForCustomer returns up to limit books the customer has not bought yet,
+// ranked by how often they were bought together with the customer's
+// purchases and by how well their genres and authors match them.
+func (rec *Recommender) ForCustomer(ctx context.Context, customerID, limit int) ([]models.Recommendation, error) {
+	inStock := true
+	catalog, err := rec.books.SearchBooks(ctx, models.SearchCriteria{InStock: &inStock})
+	if err != nil {
+		return nil, err
+	}
+
+	rec.mu.RLock()
+	defer rec.mu.RUnlock()
+
+	bought := rec.purchases[customerID]
+	if len(bought) == 0 {
+		return []models.Recommendation{}, nil
+	}
+
+	coPurchases := make(map[int]float64)
+	maxCoPurchases := 0.0
+	genres := make(map[string]float64)
+	authors := make(map[int]float64)
+	for id, book := range bought {
+		for other, count := range rec.together[id] {
+			coPurchases[other] += float64(count)
+			maxCoPurchases = max(maxCoPurchases, coPurchases[other])
+		}
+		for _, genre := range book.Genres {
+			genres[genre]++
+		}
+		for _, authorID := range creditedAuthors(book) {
+			authors[authorID]++
+		}
+	}
+
+	// Affinities are the share of purchases with the genre or author, so a
+	// customer who only buys one genre matches it fully.
+	scores := make(map[int]float64)
+	available := make(map[int]models.Book, len(catalog.Books))
+	for _, book := range catalog.Books {
+		if _, ok := bought[book.ID]; ok {
+			continue
+		}
+		available[book.ID] = book
+
+		var genreMatch, authorMatch float64
+		for _, genre := range book.Genres {
+			genreMatch = max(genreMatch, genres[genre]/float64(len(bought)))
+		}
+		for _, authorID := range creditedAuthors(book) {
+			authorMatch = max(authorMatch, authors[authorID]/float64(len(bought)))
+		}
+
+		score := genreWeight*genreMatch + authorWeight*authorMatch
+		if count := coPurchases[book.ID]; count > 0 {
+			score += coPurchaseWeight * count / maxCoPurchases
+		}
+		if score > 0 {
+			scores[book.ID] = score
+		}
+	}
+
+	return top(scores, limit, func(id int) (models.Book, bool) {
+		book, ok := available[id]
+		return book, ok
+	}), nil
+}
+
+// top ranks the scored books and returns the best limit of them that lookup
+// finds available, with the details it returns.
+func top(scores map[int]float64, limit int, lookup func(id int) (models.Book, bool)) []models.Recommendation {
+	ranked := make([]int, 0, len(scores))
+	for id := range scores {
+		ranked = append(ranked, id)
+	}
+	slices.SortFunc(ranked, func(a, b int) int {
+		if scores[a] != scores[b] {
+			if scores[a] > scores[b] {
+				return -1
+			}
+			return 1
+		}
+		return a - b
+	})
+
+	recommendations := make([]models.Recommendation, 0, min(limit, len(ranked)))
+	for _, id := range ranked {
+		if len(recommendations) == limit {
+			break
+		}
+		if book, ok := lookup(id); ok {
+			recommendations = append(recommendations, models.Recommendation{Book: book, Score: scores[id]})
+		}
+	}
+	return recommendations
+}
+
+// creditedAuthors lists the authors of a book in any role.
+func creditedAuthors(book models.Book) []int {
+	if len(book.Contributors) == 0 {
+		return []int{book.Author.ID}
+	}
+
+	ids := make([]int, 0, len(book.Contributors))
+	for _, contributor := range book.Contributors {
+		ids = append(ids, contributor.Author.ID)
+	}
+	return ids
+}
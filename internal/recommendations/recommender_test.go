@@ -0,0 +1,180 @@
+package recommendations
+
+import (
+	"Book-Store/internal/models"
+	"Book-Store/internal/store"
+	"Book-Store/internal/store/storetest"
+	"context"
+	"slices"
+	"testing"
+)
+
+// orderSnapshot serves a fixed list of completed orders. When reading is
+// set, a search closes it once the list is read and waits for release before
+// returning.
+type orderSnapshot struct {
+	store.OrderStore
+	orders  []models.Order
+	reading chan struct{}
+	release chan struct{}
+}
+
+func (s *orderSnapshot) SearchOrderByStatus(ctx context.Context, status string, page models.PageRequest) (models.Page[models.Order], error) {
+	orders := slices.Clone(s.orders)
+	if s.reading != nil {
+		close(s.reading)
+		<-s.release
+	}
+	return models.Page[models.Order]{Items: orders, Total: len(orders)}, nil
+}
+
+// catalog creates books with the given stock and returns their IDs in order.
+func catalog(t *testing.T, stock ...int) (store.Store, []int) {
+	t.Helper()
+	ctx := context.Background()
+	s := storetest.NewMemStore(t)
+
+	author, err := s.CreateAuthor(ctx, models.Author{FirstName: "Ursula", LastName: "Le Guin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for _, n := range stock {
+		book, err := s.CreateBook(ctx, models.Book{Title: "Book", Author: author, Stock: n})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, book.ID)
+	}
+	return s, ids
+}
+
+func completedOrder(id, customerID int, bookIDs ...int) models.Order {
+	order := models.Order{ID: id, Customer: models.Customer{ID: customerID}, Status: "completed"}
+	for _, bookID := range bookIDs {
+		order.Items = append(order.Items, models.OrderItem{Book: models.Book{ID: bookID}, Quantity: 1})
+	}
+	return order
+}
+
+func recommendedIDs(recs []models.Recommendation) []int {
+	ids := make([]int, 0, len(recs))
+	for _, r := range recs {
+		ids = append(ids, r.Book.ID)
+	}
+	return ids
+}
+
+func TestForBook(t *testing.T) {
+	ctx := context.Background()
+	// Book 4 is out of stock.
+	books, ids := catalog(t, 5, 5, 5, 0, 5)
+	orders := &orderSnapshot{orders: []models.Order{
+		completedOrder(1, 1, ids[0], ids[1], ids[2]),
+		completedOrder(2, 2, ids[0], ids[1], ids[3]),
+		completedOrder(3, 3, ids[0], ids[1], ids[1]),
+	}}
+
+	rec := NewRecommender(orders, books)
+	if err := rec.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		bookID int
+		limit  int
+		want   []int
+	}{
+		{"ranked by co-purchases", ids[0], 10, []int{ids[1], ids[2]}},
+		{"limited", ids[0], 1, []int{ids[1]}},
+		{"out of stock left out", ids[1], 10, []int{ids[0], ids[2]}},
+		{"never bought", ids[4], 10, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recs, err := rec.ForBook(ctx, tt.bookID, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := recommendedIDs(recs); !slices.Equal(got, tt.want) {
+				t.Errorf("ForBook(%d) = %v, want %v", tt.bookID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForCustomer(t *testing.T) {
+	ctx := context.Background()
+	books, ids := catalog(t, 5, 5, 5, 0)
+	orders := &orderSnapshot{orders: []models.Order{
+		completedOrder(1, 1, ids[0], ids[1]),
+		completedOrder(2, 2, ids[0], ids[2], ids[3]),
+	}}
+
+	rec := NewRecommender(orders, books)
+	if err := rec.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Customer 1 bought books 0 and 1; book 2 was bought with book 0 and
+	// book 3 is out of stock.
+	recs, err := rec.ForCustomer(ctx, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := recommendedIDs(recs), []int{ids[2]}; !slices.Equal(got, want) {
+		t.Errorf("ForCustomer(1) = %v, want %v", got, want)
+	}
+
+	recs, err = rec.ForCustomer(ctx, 3, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 0 {
+		t.Errorf("ForCustomer() for a customer without orders = %v, want none", recommendedIDs(recs))
+	}
+}
+
+func TestRecordOrderDuringRefresh(t *testing.T) {
+	ctx := context.Background()
+	books, ids := catalog(t, 5, 5, 5)
+	orders := &orderSnapshot{
+		orders:  []models.Order{completedOrder(1, 1, ids[0], ids[1])},
+		reading: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	rec := NewRecommender(orders, books)
+
+	done := make(chan error)
+	go func() { done <- rec.Refresh(ctx) }()
+
+	// The order completes after Refresh took its snapshot of the store.
+	<-orders.reading
+	rec.RecordOrder(completedOrder(2, 2, ids[0], ids[2]))
+	// Replaying an order the snapshot holds must not count it twice.
+	rec.RecordOrder(completedOrder(1, 1, ids[0], ids[1]))
+	close(orders.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := rec.ForBook(ctx, ids[0], 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := recommendedIDs(recs), []int{ids[1], ids[2]}; !slices.Equal(got, want) {
+		t.Errorf("ForBook() = %v, want %v", got, want)
+	}
+	for _, r := range recs {
+		if r.Score != 1 {
+			t.Errorf("book %d scored %v, want 1", r.Book.ID, r.Score)
+		}
+	}
+
+	// Orders recorded after the refresh are no longer kept for replay.
+	rec.RecordOrder(completedOrder(3, 3, ids[1], ids[2]))
+	if rec.recorded != nil {
+		t.Errorf("recorded = %v outside a refresh, want nil", rec.recorded)
+	}
+}
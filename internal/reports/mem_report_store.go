@@ -2,6 +2,7 @@ package reports
 
 import (
 	"Book-Store/internal/models"
+	"Book-Store/internal/pagination"
 	"fmt"
 	"time"
 )
@@ -17,7 +18,17 @@ func (rs *ReportStore) GetLatestReport() (*models.SalesReport, error) {
 	return &rs.reports[len(rs.reports)-1], nil
 }
 
-func (rs *ReportStore) GetReportsSince(since time.Time) []models.SalesReport {
+// reportOrder lists reports oldest first, the order they are saved in.
+// Timestamps are formatted at a fixed width so they compare as strings.
+var reportOrder = pagination.Order[models.SalesReport]{
+	Fields: []pagination.Field[models.SalesReport]{
+		{Value: func(r models.SalesReport) any {
+			return r.Timestamp.UTC().Format("2006-01-02T15:04:05.000000000Z")
+		}},
+	},
+}
+
+func (rs *ReportStore) GetReportsSince(since time.Time, page models.PageRequest) (models.Page[models.SalesReport], error) {
 	rs.mu.RLock()
 	defer rs.mu.RUnlock()
 
@@ -27,12 +38,12 @@ func (rs *ReportStore) GetReportsSince(since time.Time) []models.SalesReport {
 			filtered = append(filtered, report)
 		}
 	}
-	return filtered
+	return reportOrder.Page(filtered, page)
 }
 
-func (rs *ReportStore) ListAllReports() []models.SalesReport {
+func (rs *ReportStore) ListAllReports(page models.PageRequest) (models.Page[models.SalesReport], error) {
 	rs.mu.RLock()
 	defer rs.mu.RUnlock()
 
-	return rs.reports
+	return reportOrder.Page(rs.reports, page)
 }
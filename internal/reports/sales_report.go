@@ -8,7 +8,7 @@ import (
 )
 
 func GenerateSalesReport(ctx context.Context, orderStore store.OrderStore) (*models.SalesReport, error) {
-	orders, err := orderStore.ListOrders(ctx)
+	orders, err := orderStore.ListOrders(ctx, models.PageRequest{})
 	if err != nil {
 		return nil, err
 	}
@@ -20,7 +20,7 @@ func GenerateSalesReport(ctx context.Context, orderStore store.OrderStore) (*mod
 
 	bookSalesMap := make(map[int]*models.BookSales)
 
-	for _, order := range orders {
+	for _, order := range orders.Items {
 		report.TotalOrders++
 
 		if order.Status == "completed" {
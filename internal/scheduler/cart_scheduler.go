@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"Book-Store/internal/store"
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// CartScheduler deletes anonymous carts left unchanged for longer than ttl,
+// checking on an interval.
+type CartScheduler struct {
+	carts    store.CartStore
+	ttl      time.Duration
+	interval time.Duration
+	ticker   *time.Ticker
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+func NewCartScheduler(carts store.CartStore, ttl, interval time.Duration) *CartScheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &CartScheduler{
+		carts:    carts,
+		ttl:      ttl,
+		interval: interval,
+		stopChan: make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+func (cs *CartScheduler) Start() {
+	cs.ticker = time.NewTicker(cs.interval)
+
+	cs.wg.Go(func() {
+		log.Println("Cart scheduler started")
+
+		cs.expire()
+
+		for {
+			select {
+			case <-cs.ticker.C:
+				cs.expire()
+			case <-cs.stopChan:
+				log.Println("Cart scheduler stopping...")
+				return
+			case <-cs.ctx.Done():
+				log.Println("Cart scheduler context cancelled")
+				return
+			}
+		}
+	})
+}
+
+func (cs *CartScheduler) Stop() {
+	close(cs.stopChan)
+	cs.cancel()
+	if cs.ticker != nil {
+		cs.ticker.Stop()
+	}
+	cs.wg.Wait()
+	log.Println("Cart scheduler stopped")
+}
+
+func (cs *CartScheduler) expire() {
+	n, err := cs.carts.ExpireSessionCarts(cs.ctx, time.Now().Add(-cs.ttl))
+	if err != nil {
+		log.Printf("Error expiring session carts: %v", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("Expired %d session carts", n)
+	}
+}
@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"Book-Store/internal/recommendations"
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// RecommendationScheduler rebuilds the recommendation model on an interval,
+// picking up catalog changes and correcting drift from incremental updates.
+type RecommendationScheduler struct {
+	recommender *recommendations.Recommender
+	interval    time.Duration
+	ticker      *time.Ticker
+	stopChan    chan struct{}
+	wg          sync.WaitGroup
+	ctx         context.Context
+	cancel      context.CancelFunc
+}
+
+func NewRecommendationScheduler(recommender *recommendations.Recommender, interval time.Duration) *RecommendationScheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &RecommendationScheduler{
+		recommender: recommender,
+		interval:    interval,
+		stopChan:    make(chan struct{}),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+func (rs *RecommendationScheduler) Start() {
+	rs.ticker = time.NewTicker(rs.interval)
+
+	rs.wg.Go(func() {
+		log.Println("Recommendation scheduler started")
+
+		rs.refresh()
+
+		for {
+			select {
+			case <-rs.ticker.C:
+				rs.refresh()
+			case <-rs.stopChan:
+				log.Println("Recommendation scheduler stopping...")
+				return
+			case <-rs.ctx.Done():
+				log.Println("Recommendation scheduler context cancelled")
+				return
+			}
+		}
+	})
+}
+
+func (rs *RecommendationScheduler) Stop() {
+	close(rs.stopChan)
+	rs.cancel()
+	if rs.ticker != nil {
+		rs.ticker.Stop()
+	}
+	rs.wg.Wait()
+	log.Println("Recommendation scheduler stopped")
+}
+
+func (rs *RecommendationScheduler) refresh() {
+	if err := rs.recommender.Refresh(rs.ctx); err != nil {
+		log.Printf("Error refreshing recommendations: %v", err)
+	}
+}
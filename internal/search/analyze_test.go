@@ -0,0 +1,63 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", []string{}},
+		{"Harry Potter & the Half-Blood Prince, 2005", []string{"harry", "potter", "the", "half", "blood", "prince", "2005"}},
+		{"  DUNE  ", []string{"dune"}},
+		{"Cien años de soledad", []string{"cien", "años", "de", "soledad"}},
+	}
+	for _, tt := range tests {
+		if got := Tokenize(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"cats", "cat"},
+		{"caresses", "caress"},
+		{"ponies", "poni"},
+		{"running", "run"},
+		{"runs", "run"},
+		{"hopping", "hop"},
+		{"agreed", "agre"},
+		{"relational", "relat"},
+		{"generalization", "gener"},
+		{"sky", "sky"},
+		{"ran", "ran"},
+	}
+	for _, tt := range tests {
+		if got := Stem(tt.word); got != tt.want {
+			t.Errorf("Stem(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"The Wizards of the Running Dragons!", []string{"wizard", "run", "dragon"}},
+		{"the and of", []string{}},
+		{"Dragon dragons", []string{"dragon", "dragon"}},
+	}
+	for _, tt := range tests {
+		if got := Analyze(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("Analyze(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
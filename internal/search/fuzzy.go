@@ -0,0 +1,153 @@
+package search
+
+// Match is a vocabulary term close to a misspelled one.
+type Match struct {
+	Term     string
+	Distance int
+}
+
+// MaxEdits is how many typos a term of this length tolerates. Very short
+// terms must match exactly, since one edit turns them into other words.
+func MaxEdits(term string) int {
+	switch n := len([]rune(term)); {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// EditDistance is the optimal string alignment distance between a and b:
+// insertions, deletions, substitutions and swaps of adjacent letters each
+// cost one.
+func EditDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	rows := make([][]int, len(ra)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(rb)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(ra)][len(rb)]
+}
+
+// Similar filters candidates down to those within MaxEdits of term.
+func Similar(term string, candidates []string) []Match {
+	maxEdits := MaxEdits(term)
+	if maxEdits == 0 {
+		return nil
+	}
+
+	var matches []Match
+	for _, candidate := range candidates {
+		if candidate == term {
+			continue
+		}
+		if diff := len([]rune(candidate)) - len([]rune(term)); diff > maxEdits || -diff > maxEdits {
+			continue
+		}
+		if d := EditDistance(term, candidate); d <= maxEdits {
+			matches = append(matches, Match{Term: candidate, Distance: d})
+		}
+	}
+	return matches
+}
+
+// trigrams splits a term padded with spaces into overlapping three-letter
+// pieces, so "cat" gives "  c", " ca", "cat" and "at ".
+func trigrams(term string) []string {
+	r := []rune("  " + term + " ")
+	grams := make([]string, 0, len(r)-2)
+	for i := 0; i+3 <= len(r); i++ {
+		grams = append(grams, string(r[i:i+3]))
+	}
+	return grams
+}
+
+// Vocabulary indexes terms by trigram so close spellings of a term can be
+// found without comparing against every term.
+type Vocabulary struct {
+	refs     map[string]int
+	trigrams map[string]map[string]struct{}
+}
+
+func NewVocabulary() *Vocabulary {
+	return &Vocabulary{
+		refs:     make(map[string]int),
+		trigrams: make(map[string]map[string]struct{}),
+	}
+}
+
+// Add records one more use of term.
+func (v *Vocabulary) Add(term string) {
+	v.refs[term]++
+	if v.refs[term] > 1 {
+		return
+	}
+	for _, gram := range trigrams(term) {
+		terms, ok := v.trigrams[gram]
+		if !ok {
+			terms = make(map[string]struct{})
+			v.trigrams[gram] = terms
+		}
+		terms[term] = struct{}{}
+	}
+}
+
+// Remove drops one use of term, forgetting it after the last.
+func (v *Vocabulary) Remove(term string) {
+	if v.refs[term] == 0 {
+		return
+	}
+	v.refs[term]--
+	if v.refs[term] > 0 {
+		return
+	}
+	delete(v.refs, term)
+	for _, gram := range trigrams(term) {
+		delete(v.trigrams[gram], term)
+		if len(v.trigrams[gram]) == 0 {
+			delete(v.trigrams, gram)
+		}
+	}
+}
+
+func (v *Vocabulary) Contains(term string) bool {
+	return v.refs[term] > 0
+}
+
+// Similar returns the known terms within MaxEdits of term.
+func (v *Vocabulary) Similar(term string) []Match {
+	if MaxEdits(term) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var candidates []string
+	for _, gram := range trigrams(term) {
+		for candidate := range v.trigrams[gram] {
+			if !seen[candidate] {
+				seen[candidate] = true
+				candidates = append(candidates, candidate)
+			}
+		}
+	}
+	return Similar(term, candidates)
+}
@@ -0,0 +1,107 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"hobbit", "hobbit", 0},
+		{"hobbit", "hobit", 1},
+		{"hobbit", "hobbitt", 1},
+		{"hobbit", "hobbot", 1},
+		{"hobbit", "hbobit", 1},
+		{"tolkien", "tolkein", 1},
+		{"kitten", "sitting", 3},
+		{"ñandú", "nandu", 2},
+	}
+	for _, tt := range tests {
+		if got := EditDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("EditDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := EditDistance(tt.b, tt.a); got != tt.want {
+			t.Errorf("EditDistance(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestMaxEdits(t *testing.T) {
+	tests := []struct {
+		term string
+		want int
+	}{
+		{"cat", 0},
+		{"dune", 1},
+		{"hobbit", 1},
+		{"tolkien", 2},
+	}
+	for _, tt := range tests {
+		if got := MaxEdits(tt.term); got != tt.want {
+			t.Errorf("MaxEdits(%q) = %d, want %d", tt.term, got, tt.want)
+		}
+	}
+}
+
+func TestVocabularySimilar(t *testing.T) {
+	v := NewVocabulary()
+	for _, term := range []string{"hobbit", "habit", "rabbit", "tolkien", "cat"} {
+		v.Add(term)
+	}
+
+	tests := []struct {
+		term string
+		want []string
+	}{
+		{"hobit", []string{"habit", "hobbit"}},
+		{"tolkein", []string{"tolkien"}},
+		{"cot", nil},
+		{"zzzzzz", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, match := range v.Similar(tt.term) {
+			got = append(got, match.Term)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Similar(%q) = %q, want %q", tt.term, got, tt.want)
+		}
+	}
+}
+
+func TestVocabularyCountsUses(t *testing.T) {
+	v := NewVocabulary()
+	v.Add("hobbit")
+	v.Add("hobbit")
+
+	v.Remove("hobbit")
+	if !v.Contains("hobbit") {
+		t.Fatal("term forgotten while still in use")
+	}
+	v.Remove("hobbit")
+	if v.Contains("hobbit") {
+		t.Fatal("term kept after its last use")
+	}
+	if got := v.Similar("hobit"); len(got) != 0 {
+		t.Errorf("Similar after removal = %v, want nothing", got)
+	}
+}
+
+func TestIndexSearchToleratesTypos(t *testing.T) {
+	ix := newTestIndex()
+
+	exact := ix.Search("hobbit")
+	typo := ix.Search("hobit")
+	if len(typo) != 1 || typo[1] == 0 {
+		t.Fatalf("Search(%q) = %v, want document 1", "hobit", typo)
+	}
+	if typo[1] >= exact[1] {
+		t.Errorf("typo scored %v, want less than the exact match's %v", typo[1], exact[1])
+	}
+}
@@ -0,0 +1,129 @@
+package search
+
+import "math"
+
+// BM25 tuning constants.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Field is a piece of document text. Its terms count Weight times towards
+// the term frequencies used for ranking.
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+type document struct {
+	length int
+	terms  []string
+}
+
+// Index is an inverted index ranked with BM25. It is not safe for concurrent
+// use; the owning store guards it with its own lock.
+type Index struct {
+	postings    map[string]map[int]float64
+	docs        map[int]document
+	totalLength int
+	vocabulary  *Vocabulary
+}
+
+func NewIndex() *Index {
+	return &Index{
+		postings:   make(map[string]map[int]float64),
+		docs:       make(map[int]document),
+		vocabulary: NewVocabulary(),
+	}
+}
+
+// Add indexes the fields under id, replacing anything indexed for it before.
+func (ix *Index) Add(id int, fields ...Field) {
+	ix.Remove(id)
+
+	freqs := make(map[string]float64)
+	length := 0
+	for _, field := range fields {
+		for _, term := range Analyze(field.Text) {
+			freqs[term] += field.Weight
+			length++
+		}
+	}
+	if len(freqs) == 0 {
+		return
+	}
+
+	doc := document{length: length, terms: make([]string, 0, len(freqs))}
+	for term, freq := range freqs {
+		postings, ok := ix.postings[term]
+		if !ok {
+			postings = make(map[int]float64)
+			ix.postings[term] = postings
+			ix.vocabulary.Add(term)
+		}
+		postings[id] = freq
+		doc.terms = append(doc.terms, term)
+	}
+	ix.docs[id] = doc
+	ix.totalLength += length
+}
+
+func (ix *Index) Remove(id int) {
+	doc, ok := ix.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range doc.terms {
+		delete(ix.postings[term], id)
+		if len(ix.postings[term]) == 0 {
+			delete(ix.postings, term)
+			ix.vocabulary.Remove(term)
+		}
+	}
+	delete(ix.docs, id)
+	ix.totalLength -= doc.length
+}
+
+// Search scores every document containing at least one query term. A query
+// term missing from the index is replaced by similarly spelled terms, scored
+// lower the further they are from what was typed.
+func (ix *Index) Search(query string) map[int]float64 {
+	scores := make(map[int]float64)
+	if len(ix.docs) == 0 {
+		return scores
+	}
+
+	seen := make(map[string]bool)
+	for _, term := range Analyze(query) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+
+		if ix.vocabulary.Contains(term) {
+			ix.score(scores, term, 1)
+			continue
+		}
+		for _, match := range ix.vocabulary.Similar(term) {
+			ix.score(scores, match.Term, FuzzyWeight(match.Distance))
+		}
+	}
+	return scores
+}
+
+// FuzzyWeight scales the score of a term matched with distance typos.
+func FuzzyWeight(distance int) float64 {
+	return 1 / float64(1+distance)
+}
+
+func (ix *Index) score(scores map[int]float64, term string, weight float64) {
+	postings := ix.postings[term]
+	n := float64(len(ix.docs))
+	df := float64(len(postings))
+	idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+	avgLength := float64(ix.totalLength) / n
+	for id, tf := range postings {
+		norm := bm25K1 * (1 - bm25B + bm25B*float64(ix.docs[id].length)/avgLength)
+		scores[id] += weight * idf * tf * (bm25K1 + 1) / (tf + norm)
+	}
+}
@@ -0,0 +1,91 @@
+package search
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func newTestIndex() *Index {
+	ix := NewIndex()
+	ix.Add(1, Field{Text: "The Hobbit", Weight: 3}, Field{Text: "J. R. R. Tolkien", Weight: 2})
+	ix.Add(2, Field{Text: "Dragons of Autumn Twilight", Weight: 3}, Field{Text: "Margaret Weis", Weight: 2})
+	ix.Add(3, Field{Text: "A Dragon's Guide", Weight: 3}, Field{Text: "a story about running from dragons", Weight: 1})
+	return ix
+}
+
+// ranked lists the documents of scores from best to worst.
+func ranked(scores map[int]float64) []int {
+	ids := slices.Collect(maps.Keys(scores))
+	slices.SortFunc(ids, func(a, b int) int {
+		switch {
+		case scores[a] > scores[b]:
+			return -1
+		case scores[a] < scores[b]:
+			return 1
+		}
+		return a - b
+	})
+	return ids
+}
+
+func TestIndexSearch(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+		// ranked compares the order of results too; document 3 mentions
+		// dragons twice.
+		ranked bool
+	}{
+		{name: "exact term", query: "hobbit", want: []int{1}},
+		{name: "stemmed query matches other forms", query: "dragon", want: []int{3, 2}, ranked: true},
+		{name: "stop words only", query: "the of a", want: []int{}},
+		{name: "any term matches", query: "hobbit twilight", want: []int{1, 2}},
+		{name: "unknown term", query: "zzzz", want: []int{}},
+	}
+	ix := newTestIndex()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ranked(ix.Search(tt.query))
+			if !tt.ranked {
+				slices.Sort(got)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexFieldWeight(t *testing.T) {
+	ix := NewIndex()
+	ix.Add(1, Field{Text: "Tolkien", Weight: 3})
+	ix.Add(2, Field{Text: "Tolkien", Weight: 1})
+	ix.Add(3, Field{Text: "Other", Weight: 1})
+
+	if got := ranked(ix.Search("tolkien")); !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("ranked %v, want the heavier field first", got)
+	}
+}
+
+func TestIndexAddReplacesAndRemoves(t *testing.T) {
+	ix := newTestIndex()
+
+	ix.Add(1, Field{Text: "The Silmarillion", Weight: 3})
+	if scores := ix.Search("hobbit"); len(scores) != 0 {
+		t.Errorf("Search after re-adding = %v, want the old text gone", scores)
+	}
+	if scores := ix.Search("silmarillion"); len(scores) != 1 {
+		t.Errorf("Search for the new text = %v, want document 1", scores)
+	}
+
+	ix.Remove(1)
+	ix.Remove(1)
+	if scores := ix.Search("silmarillion"); len(scores) != 0 {
+		t.Errorf("Search after Remove = %v, want nothing", scores)
+	}
+	if ix.vocabulary.Contains("silmarillion") {
+		t.Error("vocabulary still holds a term no document uses")
+	}
+}
@@ -0,0 +1,221 @@
+package search
+
+import "strings"
+
+// Stem reduces an English word to its stem with the Porter algorithm, so
+// "running", "runs" and "run" all index as "run". The word must already be
+// lowercase; words that are short or not plain ASCII are returned unchanged.
+func Stem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	s := &stemmer{b: []byte(word)}
+	s.step1a()
+	s.step1b()
+	s.step1c()
+	s.step2()
+	s.step3()
+	s.step4()
+	s.step5()
+	return string(s.b)
+}
+
+type stemmer struct {
+	b []byte
+}
+
+// cons reports whether b[i] is a consonant. A 'y' after a consonant counts
+// as a vowel.
+func (s *stemmer) cons(i int) bool {
+	switch s.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !s.cons(i-1)
+	}
+	return true
+}
+
+// measure counts the vowel-consonant sequences in b[:n].
+func (s *stemmer) measure(n int) int {
+	m := 0
+	i := 0
+	for i < n && s.cons(i) {
+		i++
+	}
+	for i < n {
+		for i < n && !s.cons(i) {
+			i++
+		}
+		if i >= n {
+			break
+		}
+		for i < n && s.cons(i) {
+			i++
+		}
+		m++
+	}
+	return m
+}
+
+func (s *stemmer) hasVowel(n int) bool {
+	for i := 0; i < n; i++ {
+		if !s.cons(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// doubleCons reports whether b[:n] ends in a double consonant.
+func (s *stemmer) doubleCons(n int) bool {
+	return n >= 2 && s.b[n-1] == s.b[n-2] && s.cons(n-1)
+}
+
+// cvc reports whether b[:n] ends consonant-vowel-consonant where the last
+// consonant is not w, x or y, as in "hop" but not "snow".
+func (s *stemmer) cvc(n int) bool {
+	if n < 3 || !s.cons(n-1) || s.cons(n-2) || !s.cons(n-3) {
+		return false
+	}
+	switch s.b[n-1] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+func (s *stemmer) ends(suffix string) bool {
+	return strings.HasSuffix(string(s.b), suffix)
+}
+
+// replace swaps suffix for repl; the caller has checked the suffix matches.
+func (s *stemmer) replace(suffix, repl string) {
+	s.b = append(s.b[:len(s.b)-len(suffix)], repl...)
+}
+
+type stemRule struct {
+	suffix, repl string
+}
+
+// applyRules replaces the first matching suffix when the remaining stem has a
+// measure above minMeasure. Later rules are not tried once a suffix matches.
+func (s *stemmer) applyRules(rules []stemRule, minMeasure int) {
+	for _, rule := range rules {
+		if s.ends(rule.suffix) {
+			if s.measure(len(s.b)-len(rule.suffix)) > minMeasure {
+				s.replace(rule.suffix, rule.repl)
+			}
+			return
+		}
+	}
+}
+
+func (s *stemmer) step1a() {
+	switch {
+	case s.ends("sses"):
+		s.replace("sses", "ss")
+	case s.ends("ies"):
+		s.replace("ies", "i")
+	case s.ends("ss"):
+	case s.ends("s"):
+		s.replace("s", "")
+	}
+}
+
+func (s *stemmer) step1b() {
+	if s.ends("eed") {
+		if s.measure(len(s.b)-3) > 0 {
+			s.replace("eed", "ee")
+		}
+		return
+	}
+
+	var suffix string
+	switch {
+	case s.ends("ed"):
+		suffix = "ed"
+	case s.ends("ing"):
+		suffix = "ing"
+	default:
+		return
+	}
+	if !s.hasVowel(len(s.b) - len(suffix)) {
+		return
+	}
+	s.replace(suffix, "")
+
+	n := len(s.b)
+	switch {
+	case s.ends("at"), s.ends("bl"), s.ends("iz"):
+		s.b = append(s.b, 'e')
+	case s.doubleCons(n) && s.b[n-1] != 'l' && s.b[n-1] != 's' && s.b[n-1] != 'z':
+		s.b = s.b[:n-1]
+	case s.measure(n) == 1 && s.cvc(n):
+		s.b = append(s.b, 'e')
+	}
+}
+
+func (s *stemmer) step1c() {
+	if s.ends("y") && s.hasVowel(len(s.b)-1) {
+		s.b[len(s.b)-1] = 'i'
+	}
+}
+
+var step2Rules = []stemRule{
+	{"ational", "ate"}, {"tional", "tion"}, {"enci", "ence"}, {"anci", "ance"},
+	{"izer", "ize"}, {"bli", "ble"}, {"alli", "al"}, {"entli", "ent"}, {"eli", "e"},
+	{"ousli", "ous"}, {"ization", "ize"}, {"ation", "ate"}, {"ator", "ate"},
+	{"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"}, {"ousness", "ous"},
+	{"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"}, {"logi", "log"},
+}
+
+func (s *stemmer) step2() {
+	s.applyRules(step2Rules, 0)
+}
+
+var step3Rules = []stemRule{
+	{"icate", "ic"}, {"ative", ""}, {"alize", "al"}, {"iciti", "ic"},
+	{"ical", "ic"}, {"ful", ""}, {"ness", ""},
+}
+
+func (s *stemmer) step3() {
+	s.applyRules(step3Rules, 0)
+}
+
+var step4Rules = []stemRule{
+	{"al", ""}, {"ance", ""}, {"ence", ""}, {"er", ""}, {"ic", ""}, {"able", ""},
+	{"ible", ""}, {"ant", ""}, {"ement", ""}, {"ment", ""}, {"ent", ""},
+	{"ou", ""}, {"ism", ""}, {"ate", ""}, {"iti", ""}, {"ous", ""}, {"ive", ""},
+	{"ize", ""},
+}
+
+func (s *stemmer) step4() {
+	// "ion" is only removed after s or t, as in "adoption" but not "lion".
+	if s.ends("ion") {
+		n := len(s.b) - 3
+		if n > 0 && (s.b[n-1] == 's' || s.b[n-1] == 't') && s.measure(n) > 1 {
+			s.b = s.b[:n]
+		}
+		return
+	}
+	s.applyRules(step4Rules, 1)
+}
+
+func (s *stemmer) step5() {
+	if s.ends("e") {
+		n := len(s.b) - 1
+		if m := s.measure(n); m > 1 || (m == 1 && !s.cvc(n)) {
+			s.b = s.b[:n]
+		}
+	}
+	if n := len(s.b); s.measure(n) > 1 && s.doubleCons(n) && s.b[n-1] == 'l' {
+		s.b = s.b[:n-1]
+	}
+}
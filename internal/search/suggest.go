@@ -0,0 +1,172 @@
+package search
+
+import (
+	"sort"
+	"strings"
+)
+
+// Suggestion is an autocomplete entry: the text of a title or author name
+// and the record it belongs to.
+type Suggestion struct {
+	Kind string
+	ID   int
+	Text string
+}
+
+type suggestionRef struct {
+	kind string
+	id   int
+}
+
+// SuggestKeys lists the keys a suggestion is reachable under: its
+// normalized text starting at each word, so "The Hobbit" completes from
+// both "the h" and "hob".
+func SuggestKeys(text string) []string {
+	words := Tokenize(text)
+	keys := make([]string, 0, len(words))
+	for i := range words {
+		keys = append(keys, strings.Join(words[i:], " "))
+	}
+	return keys
+}
+
+// NormalizePrefix puts a typed prefix into the form SuggestKeys produces,
+// keeping a trailing space so "the " does not complete to "theory".
+func NormalizePrefix(prefix string) string {
+	normalized := strings.Join(Tokenize(prefix), " ")
+	if normalized != "" && strings.TrimRight(prefix, " ") != prefix {
+		normalized += " "
+	}
+	return normalized
+}
+
+type trieNode struct {
+	children map[rune]*trieNode
+	entries  map[suggestionRef]Suggestion
+}
+
+// Trie is a prefix tree of suggestions. It is not safe for concurrent use;
+// the owning store guards it with its own lock.
+type Trie struct {
+	root  *trieNode
+	byRef map[suggestionRef]Suggestion
+}
+
+func NewTrie() *Trie {
+	return &Trie{root: &trieNode{}, byRef: make(map[suggestionRef]Suggestion)}
+}
+
+// Add indexes s, replacing any earlier suggestion of the same kind and ID.
+func (t *Trie) Add(s Suggestion) {
+	ref := suggestionRef{kind: s.Kind, id: s.ID}
+	t.Remove(s.Kind, s.ID)
+	t.byRef[ref] = s
+
+	for _, key := range SuggestKeys(s.Text) {
+		node := t.root
+		for _, r := range key {
+			if node.children == nil {
+				node.children = make(map[rune]*trieNode)
+			}
+			child, ok := node.children[r]
+			if !ok {
+				child = &trieNode{}
+				node.children[r] = child
+			}
+			node = child
+		}
+		if node.entries == nil {
+			node.entries = make(map[suggestionRef]Suggestion)
+		}
+		node.entries[ref] = s
+	}
+}
+
+func (t *Trie) Remove(kind string, id int) {
+	ref := suggestionRef{kind: kind, id: id}
+	s, ok := t.byRef[ref]
+	if !ok {
+		return
+	}
+	delete(t.byRef, ref)
+	for _, key := range SuggestKeys(s.Text) {
+		t.root.remove([]rune(key), ref)
+	}
+}
+
+// remove deletes ref below n along key and reports whether n is now empty.
+func (n *trieNode) remove(key []rune, ref suggestionRef) bool {
+	if len(key) == 0 {
+		delete(n.entries, ref)
+	} else if child, ok := n.children[key[0]]; ok && child.remove(key[1:], ref) {
+		delete(n.children, key[0])
+	}
+	return len(n.entries) == 0 && len(n.children) == 0
+}
+
+// Complete returns up to limit suggestions with a key starting with prefix,
+// in key order.
+func (t *Trie) Complete(prefix string, limit int) []Suggestion {
+	results := make([]Suggestion, 0)
+	prefix = NormalizePrefix(prefix)
+	if prefix == "" || limit <= 0 {
+		return results
+	}
+
+	node := t.root
+	for _, r := range prefix {
+		node = node.children[r]
+		if node == nil {
+			return results
+		}
+	}
+
+	seen := make(map[suggestionRef]bool)
+	node.walk(func(entries map[suggestionRef]Suggestion) bool {
+		for _, s := range sortedSuggestions(entries) {
+			ref := suggestionRef{kind: s.Kind, id: s.ID}
+			if seen[ref] {
+				continue
+			}
+			seen[ref] = true
+			results = append(results, s)
+			if len(results) == limit {
+				return false
+			}
+		}
+		return true
+	})
+	return results
+}
+
+// walk visits nodes depth first in rune order until visit returns false.
+func (n *trieNode) walk(visit func(map[suggestionRef]Suggestion) bool) bool {
+	if len(n.entries) > 0 && !visit(n.entries) {
+		return false
+	}
+	runes := make([]rune, 0, len(n.children))
+	for r := range n.children {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	for _, r := range runes {
+		if !n.children[r].walk(visit) {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedSuggestions(entries map[suggestionRef]Suggestion) []Suggestion {
+	list := make([]Suggestion, 0, len(entries))
+	for _, s := range entries {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Kind != list[j].Kind {
+			return list[i].Kind < list[j].Kind
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
@@ -0,0 +1,77 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizePrefix(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{"", ""},
+		{"   ", ""},
+		{"The Hob", "the hob"},
+		{"the ", "the "},
+		{"Half-Blo", "half blo"},
+	}
+	for _, tt := range tests {
+		if got := NormalizePrefix(tt.prefix); got != tt.want {
+			t.Errorf("NormalizePrefix(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestTrieComplete(t *testing.T) {
+	trie := NewTrie()
+	trie.Add(Suggestion{Kind: "title", ID: 1, Text: "The Hobbit"})
+	trie.Add(Suggestion{Kind: "title", ID: 2, Text: "Theory of Everything"})
+	trie.Add(Suggestion{Kind: "author", ID: 1, Text: "Thomas Hardy"})
+
+	tests := []struct {
+		prefix string
+		limit  int
+		want   []string
+	}{
+		{"hob", 10, []string{"The Hobbit"}},
+		{"the", 10, []string{"The Hobbit", "Theory of Everything"}},
+		{"the ", 10, []string{"The Hobbit"}},
+		{"th", 2, []string{"The Hobbit", "Theory of Everything"}},
+		{"har", 10, []string{"Thomas Hardy"}},
+		{"every", 10, []string{"Theory of Everything"}},
+		{"x", 10, []string{}},
+		{"", 10, []string{}},
+		{"the", 0, []string{}},
+	}
+	for _, tt := range tests {
+		got := make([]string, 0)
+		for _, s := range trie.Complete(tt.prefix, tt.limit) {
+			got = append(got, s.Text)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Complete(%q, %d) = %q, want %q", tt.prefix, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestTrieAddReplacesAndRemoves(t *testing.T) {
+	trie := NewTrie()
+	trie.Add(Suggestion{Kind: "title", ID: 1, Text: "The Hobbit"})
+	trie.Add(Suggestion{Kind: "title", ID: 1, Text: "The Silmarillion"})
+
+	if got := trie.Complete("hob", 10); len(got) != 0 {
+		t.Errorf("Complete after replacing = %v, want the old title gone", got)
+	}
+	if got := trie.Complete("the", 10); len(got) != 1 || got[0].Text != "The Silmarillion" {
+		t.Errorf("Complete = %v, want only the new title", got)
+	}
+
+	trie.Remove("title", 1)
+	if got := trie.Complete("the", 10); len(got) != 0 {
+		t.Errorf("Complete after Remove = %v, want nothing", got)
+	}
+	if len(trie.root.children) != 0 {
+		t.Error("Remove left empty nodes behind")
+	}
+}
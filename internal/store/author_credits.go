@@ -0,0 +1,60 @@
+package store
+
+import "Book-Store/internal/models"
+
+// refreshCredits replaces the details of author wherever book credits them.
+func refreshCredits(book models.Book, author models.Author) models.Book {
+	contributors := make([]models.Contributor, len(book.Contributors))
+	for i, c := range book.Contributors {
+		if c.Author.ID == author.ID {
+			c.Author = author
+		}
+		contributors[i] = c
+	}
+	book.Contributors = contributors
+	if book.Author.ID == author.ID {
+		book.Author = author
+	}
+	return book
+}
+
+// uncredit drops an author from the credits of book. It reports false when
+// no contributor with the author role is left, in which case the book has to
+// go as well.
+func uncredit(book models.Book, authorID int) (models.Book, bool) {
+	contributors := make([]models.Contributor, 0, len(book.Contributors))
+	for _, c := range book.Contributors {
+		if c.Author.ID != authorID {
+			contributors = append(contributors, c)
+		}
+	}
+	if len(contributors) == 0 {
+		return book, false
+	}
+	book.Contributors = contributors
+	return book, book.NormalizeContributors() == nil
+}
+
+// recredit moves the credits of one author on book to another, dropping any
+// the new author already holds in the same role.
+func recredit(book models.Book, fromID int, to models.Author) models.Book {
+	contributors := make([]models.Contributor, 0, len(book.Contributors))
+	for _, c := range book.Contributors {
+		if c.Author.ID == fromID {
+			c.Author = to
+		}
+		duplicate := false
+		for _, other := range contributors {
+			if other.Author.ID == c.Author.ID && other.Role == c.Role {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			contributors = append(contributors, c)
+		}
+	}
+	book.Contributors = contributors
+	book.NormalizeContributors()
+	return book
+}
@@ -0,0 +1,215 @@
+package store
+
+import (
+	"Book-Store/internal/models"
+	"Book-Store/internal/pagination"
+	"Book-Store/internal/search"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// bookSearchFields is the text full-text search covers for a book, in the
+// column order of the SQLite books_fts table. Title matches rank highest.
+// Every contributor counts as an author.
+func bookSearchFields(book models.Book) []search.Field {
+	var names, bios []string
+	for _, author := range book.CreditedAuthors() {
+		names = append(names, author.FirstName, author.LastName)
+		bios = append(bios, author.Bio)
+	}
+
+	return []search.Field{
+		{Text: book.Title, Weight: 3},
+		{Text: strings.Join(names, " "), Weight: 2},
+		{Text: strings.Join(bios, " "), Weight: 1},
+		{Text: strings.Join(book.Genres, " "), Weight: 1},
+	}
+}
+
+// creditsAuthor reports whether the author with the given ID contributed to
+// book in any role.
+func creditsAuthor(book models.Book, authorID int) bool {
+	return slices.ContainsFunc(book.CreditedAuthors(), func(author models.Author) bool {
+		return author.ID == authorID
+	})
+}
+
+// creditsAuthorNamed reports whether any word of name is part of the first
+// or last name of a contributor to book.
+func creditsAuthorNamed(book models.Book, name string) bool {
+	words := strings.Fields(strings.ToLower(name))
+	for _, author := range book.CreditedAuthors() {
+		first := strings.ToLower(author.FirstName)
+		last := strings.ToLower(author.LastName)
+		for _, word := range words {
+			if strings.Contains(first, word) || strings.Contains(last, word) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func titleSuggestion(book models.Book) search.Suggestion {
+	return search.Suggestion{Kind: models.SuggestionTitle, ID: book.ID, Text: book.Title}
+}
+
+func authorSuggestion(author models.Author) search.Suggestion {
+	return search.Suggestion{
+		Kind: models.SuggestionAuthor,
+		ID:   author.ID,
+		Text: strings.TrimSpace(author.FirstName + " " + author.LastName),
+	}
+}
+
+// bookOrder is the order of search results: by the criteria's sort keys,
+// by relevance for text queries without any, and always finally by ID.
+// unitsSold holds the popularity of each book.
+func bookOrder(criteria models.SearchCriteria, unitsSold map[int]int) pagination.Order[models.Book] {
+	order := pagination.Order[models.Book]{ID: func(b models.Book) int { return b.ID }}
+
+	keys := criteria.Sort
+	if len(keys) == 0 && criteria.Query != "" {
+		keys = []models.SortKey{{Field: models.SortRelevance, Desc: true}}
+	}
+
+	for _, key := range keys {
+		var value func(models.Book) any
+		switch key.Field {
+		case models.SortTitle:
+			value = func(b models.Book) any { return b.Title }
+		case models.SortPrice:
+			value = func(b models.Book) any { return b.Price }
+		case models.SortPublishedAt:
+			value = func(b models.Book) any { return formatTime(b.PublishedAt) }
+		case models.SortStock:
+			value = func(b models.Book) any { return float64(b.Stock) }
+		case models.SortAuthor:
+			value = func(b models.Book) any { return b.Author.LastName }
+		case models.SortPopularity:
+			value = func(b models.Book) any { return float64(unitsSold[b.ID]) }
+		case models.SortRelevance:
+			value = func(b models.Book) any { return b.Score }
+		case models.SortRating:
+			value = func(b models.Book) any { return b.Rating.Average }
+		default:
+			continue
+		}
+		order.Fields = append(order.Fields, pagination.Field[models.Book]{Value: value, Desc: key.Desc})
+	}
+	return order
+}
+
+// sortsByPopularity reports whether computing units sold is needed.
+func sortsByPopularity(criteria models.SearchCriteria) bool {
+	return slices.ContainsFunc(criteria.Sort, func(key models.SortKey) bool {
+		return key.Field == models.SortPopularity
+	})
+}
+
+// pageBookResults sorts every match, counts facets over all of them and cuts
+// out the requested page.
+func pageBookResults(books []models.Book, criteria models.SearchCriteria, unitsSold map[int]int) (models.BookSearchResult, error) {
+	order := bookOrder(criteria, unitsSold)
+	order.Sort(books)
+
+	page, err := order.Page(books, models.PageRequest{Limit: criteria.Limit, Cursor: criteria.Cursor})
+	if err != nil {
+		return models.BookSearchResult{}, err
+	}
+
+	return models.BookSearchResult{
+		Books:      page.Items,
+		Facets:     bookFacets(books),
+		Total:      page.Total,
+		NextCursor: page.NextCursor,
+	}, nil
+}
+
+// priceBuckets are the ranges of the price facet. Each covers [Min, Max), and
+// the last one has no upper bound.
+var priceBuckets = []struct {
+	Value    string
+	Min, Max float64
+}{
+	{"0-10", 0, 10},
+	{"10-25", 10, 25},
+	{"25-50", 25, 50},
+	{"50-100", 50, 100},
+	{"100+", 100, math.Inf(1)},
+}
+
+// bookFacets counts genres, authors, price ranges and stock availability
+// across a search result. A book counts once for each of its contributors.
+func bookFacets(books []models.Book) models.BookFacets {
+	genres := make(map[string]int)
+	authors := make(map[int]int)
+	authorValues := make(map[int]string)
+	authorNames := make(map[int]string)
+	prices := make([]int, len(priceBuckets))
+	var inStock, outOfStock int
+
+	for _, book := range books {
+		for _, genre := range book.Genres {
+			genres[genre]++
+		}
+		for _, author := range book.CreditedAuthors() {
+			authors[author.ID]++
+			authorValues[author.ID] = author.PublicID
+			if author.PublicID == "" {
+				authorValues[author.ID] = strconv.Itoa(author.ID)
+			}
+			authorNames[author.ID] = strings.TrimSpace(author.FirstName + " " + author.LastName)
+		}
+		for i, bucket := range priceBuckets {
+			if book.Price >= bucket.Min && book.Price < bucket.Max {
+				prices[i]++
+				break
+			}
+		}
+		if book.Stock > 0 {
+			inStock++
+		} else {
+			outOfStock++
+		}
+	}
+
+	facets := models.BookFacets{
+		Genres:  make([]models.FacetCount, 0, len(genres)),
+		Authors: make([]models.FacetCount, 0, len(authors)),
+		Prices:  make([]models.FacetCount, 0, len(priceBuckets)),
+		Stock: []models.FacetCount{
+			{Value: "in_stock", Count: inStock},
+			{Value: "out_of_stock", Count: outOfStock},
+		},
+	}
+	for genre, count := range genres {
+		facets.Genres = append(facets.Genres, models.FacetCount{Value: genre, Count: count})
+	}
+	for id, count := range authors {
+		facets.Authors = append(facets.Authors, models.FacetCount{
+			Value: authorValues[id],
+			Label: authorNames[id],
+			Count: count,
+		})
+	}
+	sortFacetCounts(facets.Genres)
+	sortFacetCounts(facets.Authors)
+	for i, bucket := range priceBuckets {
+		facets.Prices = append(facets.Prices, models.FacetCount{Value: bucket.Value, Count: prices[i]})
+	}
+	return facets
+}
+
+// sortFacetCounts puts the most common values first.
+func sortFacetCounts(counts []models.FacetCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Value < counts[j].Value
+	})
+}
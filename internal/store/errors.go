@@ -0,0 +1,19 @@
+package store
+
+import "errors"
+
+var (
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrRefreshTokenExpired  = errors.New("refresh token expired")
+	ErrRefreshTokenRevoked  = errors.New("refresh token revoked")
+	ErrRefreshTokenReused   = errors.New("refresh token reused")
+
+	ErrBackupNotFound = errors.New("backup not found")
+
+	ErrDuplicateISBN = errors.New("ISBN already exists")
+
+	ErrAuthorHasBooks = errors.New("author is still credited on books")
+
+	ErrReviewNotAllowed = errors.New("only customers with a completed order for the book can review it")
+	ErrDuplicateReview  = errors.New("customer has already reviewed this book")
+)
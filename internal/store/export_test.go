@@ -0,0 +1,72 @@
+package store
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Internals used by the external store tests.
+var (
+	JournalPath         = journalPath
+	SQLiteInitialSchema = sqliteInitialSchema
+	SQLiteMigrations    = sqliteMigrations
+)
+
+// BreakJournal closes the journal file, so every later write to it fails.
+func (s *MemStore) BreakJournal() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.journal.Close()
+}
+
+// CheckIndexes compares the indexes kept up to date by every change with ones
+// rebuilt from a full scan of the maps.
+func (s *MemStore) CheckIndexes() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	live := s.idx
+	s.rebuildIndexes()
+	scanned := s.idx
+	s.idx = live
+	return s.compareIndexes(live, scanned)
+}
+
+// CheckIndexesMatch compares the indexes of s with those of other.
+func (s *MemStore) CheckIndexesMatch(other *MemStore) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	other.mu.RLock()
+	defer other.mu.RUnlock()
+
+	return s.compareIndexes(s.idx, other.idx)
+}
+
+func (s *MemStore) compareIndexes(got, want *memIndexes) error {
+	// The text index keeps terms in map order, so it is compared by the
+	// scores it gives every book's own text instead.
+	for _, book := range s.Books {
+		for _, field := range bookSearchFields(book) {
+			if g, w := got.text.Search(field.Text), want.text.Search(field.Text); !reflect.DeepEqual(g, w) {
+				return fmt.Errorf("text index scores %q as %v, want %v", field.Text, g, w)
+			}
+		}
+	}
+
+	a, b := *got, *want
+	for _, idx := range []*memIndexes{&a, &b} {
+		idx.text = nil
+		// An index emptied by removals and one never filled are the same, and
+		// times read back from disk lose their location and monotonic clock.
+		var times []orderTime
+		for _, entry := range idx.ordersByTime {
+			times = append(times, orderTime{createdAt: entry.createdAt.UTC().Round(0), id: entry.id})
+		}
+		idx.ordersByTime = times
+	}
+	if !reflect.DeepEqual(a, b) {
+		return fmt.Errorf("indexes differ:\n got %+v\nwant %+v", a, b)
+	}
+	return nil
+}
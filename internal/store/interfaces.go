@@ -9,9 +9,22 @@ import (
 type BookStore interface {
 	CreateBook(ctx context.Context, book models.Book) (models.Book, error)
 	GetBook(ctx context.Context, id int) (models.Book, error)
+	GetBookByISBN(ctx context.Context, isbn string) (models.Book, error)
+	// UpdateBook keeps the cover of the book; SetBookCover changes it, and
+	// a nil cover removes it.
 	UpdateBook(ctx context.Context, id int, book models.Book) (models.Book, error)
+	SetBookCover(ctx context.Context, id int, cover *models.BookCover) (models.Book, error)
 	DeleteBook(ctx context.Context, id int) error
-	SearchBooks(ctx context.Context, criteria models.SearchCriteria) ([]models.Book, error)
+	SearchBooks(ctx context.Context, criteria models.SearchCriteria) (models.BookSearchResult, error)
+	// ScanBooks calls fn with every book matching the filters of criteria,
+	// in ID order and up to batch books at a time, reading each batch after
+	// the last ID of the one before. It neither sorts, pages nor counts
+	// facets, so Sort, Limit and Cursor are ignored. An error from fn stops
+	// the scan and is returned.
+	ScanBooks(ctx context.Context, criteria models.SearchCriteria, batch int, fn func(books []models.Book) error) error
+	SuggestBooks(ctx context.Context, prefix string, limit int) ([]models.Suggestion, error)
+	// ListAuthorBooks lists every book the author contributed to in any role.
+	ListAuthorBooks(ctx context.Context, authorID int, page models.PageRequest) (models.Page[models.Book], error)
 	BookExists(id int) bool
 
 	BooksCount() int
@@ -22,20 +35,34 @@ type BookStore interface {
 type AuthorStore interface {
 	CreateAuthor(ctx context.Context, author models.Author) (models.Author, error)
 	GetAuthor(ctx context.Context, id int) (models.Author, error)
-	ListAuthors(ctx context.Context) ([]models.Author, error)
+	ListAuthors(ctx context.Context, page models.PageRequest) (models.Page[models.Author], error)
 	UpdateAuthor(ctx context.Context, id int, author models.Author) (models.Author, error)
-	DeleteAuthor(ctx context.Context, id int) error
+	// DeleteAuthor returns ErrAuthorHasBooks if books still credit the
+	// author and deletion has no option set for them.
+	DeleteAuthor(ctx context.Context, id int, deletion models.AuthorDeletion) error
 	AuthorExists(id int) bool
 
 	AuthorsCount() int
 	BooksPerAuthor() map[int]int
 }
 
+type SeriesStore interface {
+	CreateSeries(ctx context.Context, series models.Series) (models.Series, error)
+	GetSeries(ctx context.Context, id int) (models.Series, error)
+	ListSeries(ctx context.Context, page models.PageRequest) (models.Page[models.Series], error)
+	UpdateSeries(ctx context.Context, id int, series models.Series) (models.Series, error)
+	// DeleteSeries takes the books of the series out of it.
+	DeleteSeries(ctx context.Context, id int) error
+	// ListSeriesBooks returns the books of a series in reading order.
+	ListSeriesBooks(ctx context.Context, id int) ([]models.Book, error)
+}
+
 type CustomerStore interface {
 	CreateCustomer(ctx context.Context, customer models.Customer) (models.Customer, error)
 	GetCustomer(ctx context.Context, id int) (models.Customer, error)
+	GetCustomerByEmail(ctx context.Context, email string) (models.Customer, error)
 	UpdateCustomer(ctx context.Context, id int, customer models.Customer) (models.Customer, error)
-	ListCustomers(ctx context.Context) ([]models.Customer, error)
+	ListCustomers(ctx context.Context, page models.PageRequest) (models.Page[models.Customer], error)
 	DeleteCustomer(ctx context.Context, id int) error
 	CustomerExists(id int) bool
 	CustomersCount() int
@@ -44,9 +71,89 @@ type CustomerStore interface {
 type OrderStore interface {
 	CreateOrder(ctx context.Context, order models.Order) (models.Order, error)
 	GetOrder(ctx context.Context, id int) (models.Order, error)
-	ListOrders(ctx context.Context) ([]models.Order, error)
-	SearchOrderByStatus(ctx context.Context, status string) ([]models.Order, error)
+	ListOrders(ctx context.Context, page models.PageRequest) (models.Page[models.Order], error)
+	SearchOrderByStatus(ctx context.Context, status string, page models.PageRequest) (models.Page[models.Order], error)
 	CompleteOrder(ctx context.Context, id int) (bool, error)
 	CancelOrder(ctx context.Context, id int) (bool, error)
-	GetOrdersInTimeRange(ctx context.Context, start, end time.Time) ([]models.Order, error)
+	GetOrdersInTimeRange(ctx context.Context, start, end time.Time, page models.PageRequest) (models.Page[models.Order], error)
+}
+
+// CartStore keeps carts under the keys built by models.CustomerCartKey and
+// models.SessionCartKey. A cart nobody has filled yet reads as empty.
+type CartStore interface {
+	GetCart(ctx context.Context, key string) (models.Cart, error)
+	// AddCartItem adds quantity copies of a book to the cart, and
+	// SetCartItem replaces its quantity; a quantity of zero removes it.
+	AddCartItem(ctx context.Context, key string, bookID, quantity int) (models.Cart, error)
+	SetCartItem(ctx context.Context, key string, bookID, quantity int) (models.Cart, error)
+	// MergeCart moves the items of the cart under from into the one under
+	// to, adding up quantities of the same book, and deletes from.
+	MergeCart(ctx context.Context, from, to string) (models.Cart, error)
+	DeleteCart(ctx context.Context, key string) error
+	// ExpireSessionCarts deletes the anonymous carts last changed before
+	// before and returns how many it deleted.
+	ExpireSessionCarts(ctx context.Context, before time.Time) (int, error)
+}
+
+type RefreshTokenStore interface {
+	CreateRefreshToken(ctx context.Context, token models.RefreshToken) (models.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, oldHash string, next models.RefreshToken) (models.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, tokenHash string) error
+}
+
+// BackupStore is implemented by backends that keep snapshot backups.
+type BackupStore interface {
+	ListBackups(ctx context.Context) ([]models.Backup, error)
+	RestoreBackup(ctx context.Context, name string) error
+}
+
+type ReviewStore interface {
+	// CreateReview returns ErrReviewNotAllowed unless the customer has a
+	// completed order containing the book, and ErrDuplicateReview if they
+	// reviewed it before. Every change to reviews updates the rating of
+	// their book.
+	CreateReview(ctx context.Context, review models.Review) (models.Review, error)
+	GetReview(ctx context.Context, id int) (models.Review, error)
+	ListBookReviews(ctx context.Context, bookID int, page models.PageRequest) (models.Page[models.Review], error)
+	UpdateReview(ctx context.Context, id int, review models.Review) (models.Review, error)
+	DeleteReview(ctx context.Context, id int) error
+}
+
+// Stores groups the entity stores; inside WithTx they all act on one
+// transaction, and WithTx on them nests as a savepoint.
+type Stores interface {
+	Transactor
+	BookStore
+	AuthorStore
+	SeriesStore
+	ReviewStore
+	CustomerStore
+	OrderStore
+	CartStore
+	RefreshTokenStore
+}
+
+// Transactor runs fn atomically: every change made through tx is kept if fn
+// returns nil and rolled back otherwise.
+type Transactor interface {
+	WithTx(ctx context.Context, fn func(tx Stores) error) error
+}
+
+// PublicIDResolver maps the opaque public ID of a book, author, series,
+// review, customer or order (entity is "books", "authors", ...) back to its
+// internal key.
+type PublicIDResolver interface {
+	ResolvePublicID(ctx context.Context, entity, publicID string) (int, bool)
+}
+
+type PublicIDStore interface {
+	PublicIDResolver
+	EnablePublicIDs(ctx context.Context) error
+}
+
+// Store is everything a storage backend has to provide.
+type Store interface {
+	Stores
+	Transactor
+	PublicIDStore
 }
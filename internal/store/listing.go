@@ -0,0 +1,31 @@
+package store
+
+import (
+	"Book-Store/internal/models"
+	"Book-Store/internal/pagination"
+)
+
+// Listings are ordered by ID. IDs only ever grow, so a cursor keeps its
+// place while records are added.
+var (
+	bookListOrder = pagination.Order[models.Book]{ID: func(b models.Book) int { return b.ID }}
+	authorOrder   = pagination.Order[models.Author]{ID: func(a models.Author) int { return a.ID }}
+	seriesOrder   = pagination.Order[models.Series]{ID: func(s models.Series) int { return s.ID }}
+	reviewOrder   = pagination.Order[models.Review]{ID: func(r models.Review) int { return r.ID }}
+	customerOrder = pagination.Order[models.Customer]{ID: func(c models.Customer) int { return c.ID }}
+	orderOrder    = pagination.Order[models.Order]{ID: func(o models.Order) int { return o.ID }}
+)
+
+// readingOrder sorts the books of one series by their position in it.
+var readingOrder = pagination.Order[models.Book]{
+	Fields: []pagination.Field[models.Book]{
+		{Value: func(b models.Book) any { return b.Series.Position }},
+	},
+	ID: func(b models.Book) int { return b.ID },
+}
+
+// pageOf sorts items into order and returns the requested page.
+func pageOf[T any](order pagination.Order[T], items []T, page models.PageRequest) (models.Page[T], error) {
+	order.Sort(items)
+	return order.Page(items, page)
+}
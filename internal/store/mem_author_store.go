@@ -16,17 +16,10 @@ func (s *MemStore) CreateAuthor(ctx context.Context, author models.Author) (mode
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	maxID := -1
-	for id := range s.Authors {
-		if id > maxID {
-			maxID = id
-		}
-	}
-
-	author.ID = maxID + 1
-	s.Authors[author.ID] = author
-
-	if err := s.SaveToFile(); err != nil {
+	id, sequence := nextID(s, "authors", s.Authors)
+	author.ID = id
+	author.PublicID = s.publicID()
+	if err := s.commit(sequence, setEntry(s, "authors", s.Authors, author.ID, author)); err != nil {
 		return models.Author{}, err
 	}
 
@@ -50,21 +43,21 @@ func (s *MemStore) GetAuthor(ctx context.Context, id int) (models.Author, error)
 	return author, nil
 }
 
-func (s *MemStore) ListAuthors(ctx context.Context) ([]models.Author, error) {
+func (s *MemStore) ListAuthors(ctx context.Context, page models.PageRequest) (models.Page[models.Author], error) {
 	select {
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		return models.Page[models.Author]{}, ctx.Err()
 	default:
 	}
 
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	authors := make([]models.Author, 0)
+	authors := make([]models.Author, 0, len(s.Authors))
 	for _, a := range s.Authors {
 		authors = append(authors, a)
 	}
-	return authors, nil
+	return pageOf(authorOrder, authors, page)
 }
 
 func (s *MemStore) UpdateAuthor(ctx context.Context, id int, author models.Author) (models.Author, error) {
@@ -77,21 +70,29 @@ func (s *MemStore) UpdateAuthor(ctx context.Context, id int, author models.Autho
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.Authors[id]; !exists {
+	existing, exists := s.Authors[id]
+	if !exists {
 		return models.Author{}, errors.New("Author not found")
 	}
 
 	author.ID = id
-	s.Authors[id] = author
+	author.PublicID = existing.PublicID
+	changes := []journalChange{setEntry(s, "authors", s.Authors, id, author)}
+
+	// Books hold copies of their contributors, which follow the author.
+	for _, bookID := range s.authorBookIDs(id) {
+		book := refreshCredits(s.Books[bookID], author)
+		changes = append(changes, setEntry(s, "books", s.Books, bookID, book))
+	}
 
-	if err := s.SaveToFile(); err != nil {
+	if err := s.commit(changes...); err != nil {
 		return models.Author{}, err
 	}
 
 	return author, nil
 }
 
-func (s *MemStore) DeleteAuthor(ctx context.Context, id int) error {
+func (s *MemStore) DeleteAuthor(ctx context.Context, id int, deletion models.AuthorDeletion) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -105,15 +106,51 @@ func (s *MemStore) DeleteAuthor(ctx context.Context, id int) error {
 		return errors.New("Author not found")
 	}
 
-	delete(s.Authors, id)
+	var changes []journalChange
+	bookIDs := s.authorBookIDs(id)
+	switch {
+	case len(bookIDs) == 0:
+	case deletion.ReassignTo != nil:
+		target, exists := s.Authors[*deletion.ReassignTo]
+		if !exists || target.ID == id {
+			return errors.New("reassignment author not found")
+		}
+		for _, bookID := range bookIDs {
+			book := recredit(s.Books[bookID], id, target)
+			changes = append(changes, setEntry(s, "books", s.Books, bookID, book))
+		}
+	case deletion.Cascade:
+		for _, bookID := range bookIDs {
+			if book, kept := uncredit(s.Books[bookID], id); kept {
+				changes = append(changes, setEntry(s, "books", s.Books, bookID, book))
+			} else {
+				changes = append(changes, s.deleteBookReviews(bookID)...)
+				changes = append(changes, deleteEntry(s, "books", s.Books, bookID))
+			}
+		}
+	default:
+		return ErrAuthorHasBooks
+	}
 
-	if err := s.SaveToFile(); err != nil {
+	changes = append(changes, deleteEntry(s, "authors", s.Authors, id))
+	if err := s.commit(changes...); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// authorBookIDs lists the books crediting an author, copied out of the
+// index so the books can be rewritten while going through them. Callers
+// hold s.mu.
+func (s *MemStore) authorBookIDs(authorID int) []int {
+	ids := make([]int, 0, len(s.idx.booksByAuthor[authorID]))
+	for id := range s.idx.booksByAuthor[authorID] {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
 func (s *MemStore) AuthorExists(id int) bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -133,9 +170,9 @@ func (s *MemStore) BooksPerAuthor() map[int]int {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	result := make(map[int]int)
-	for _, book := range s.Books {
-		result[book.Author.ID]++
+	result := make(map[int]int, len(s.idx.booksByAuthor))
+	for authorID, books := range s.idx.booksByAuthor {
+		result[authorID] = len(books)
 	}
 	return result
 }
@@ -0,0 +1,249 @@
+package store
+
+import (
+	"Book-Store/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupTimeFormat sorts lexicographically in chronological order.
+const backupTimeFormat = "20060102T150405.000000000Z"
+
+// ConfigureBackups keeps up to keep copies of the previous snapshot in dir,
+// taken every time a new snapshot replaces it. keep <= 0 disables backups.
+func (s *MemStore) ConfigureBackups(dir string, keep int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.backupDir = dir
+	s.backupKeep = keep
+}
+
+// writeFileAtomic writes data to a temp file beside path, fsyncs it and
+// renames it over path, so readers see either the old or the new file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+// syncDir makes a rename in dir durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+func (s *MemStore) backupPrefix(path string) (string, string) {
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// backupSnapshot preserves the snapshot at path before it is replaced and
+// drops the oldest backups beyond backupKeep. Callers hold s.mu.
+func (s *MemStore) backupSnapshot(path string) error {
+	if s.backupDir == "" || s.backupKeep <= 0 {
+		return nil
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	if err := os.MkdirAll(s.backupDir, 0755); err != nil {
+		return err
+	}
+
+	prefix, ext := s.backupPrefix(path)
+	name := prefix + time.Now().UTC().Format(backupTimeFormat) + ext
+	if err := copyFile(path, filepath.Join(s.backupDir, name)); err != nil {
+		return err
+	}
+
+	backups, err := s.listBackups(path)
+	if err != nil {
+		return err
+	}
+	for len(backups) > s.backupKeep {
+		if err := os.Remove(filepath.Join(s.backupDir, backups[0].Name)); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// listBackups returns the backups of path, oldest first.
+func (s *MemStore) listBackups(path string) ([]models.Backup, error) {
+	backups := make([]models.Backup, 0)
+	if s.backupDir == "" {
+		return backups, nil
+	}
+
+	entries, err := os.ReadDir(s.backupDir)
+	if os.IsNotExist(err) {
+		return backups, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, ext := s.backupPrefix(path)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		createdAt, err := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext))
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, models.Backup{Name: name, Size: info.Size(), CreatedAt: createdAt})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.Before(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+func (s *MemStore) ListBackups(ctx context.Context) ([]models.Backup, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.listBackups(s.snapshotPath())
+}
+
+// RestoreBackup replaces the whole store with the contents of the named
+// backup. The state being replaced is itself backed up by the snapshot write,
+// so a restore can be undone. Sequences never move backwards, so IDs issued
+// since the backup are not handed out again, and records from before public
+// IDs were enabled get theirs.
+func (s *MemStore) RestoreBackup(ctx context.Context, name string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	backups, err := s.listBackups(s.snapshotPath())
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, b := range backups {
+		if b.Name == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ErrBackupNotFound
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.backupDir, name))
+	if err != nil {
+		return err
+	}
+
+	restored := NewMemStore()
+	if err := json.Unmarshal(data, restored); err != nil {
+		return fmt.Errorf("backup %s is not a valid snapshot: %w", name, err)
+	}
+
+	last := s.lastIDs()
+
+	s.Books = restored.Books
+	s.Authors = restored.Authors
+	s.Series = restored.Series
+	s.Reviews = restored.Reviews
+	s.Customers = restored.Customers
+	s.Orders = restored.Orders
+	s.Carts = restored.Carts
+	s.RefreshTokens = restored.RefreshTokens
+	s.Sequences = restored.Sequences
+	if s.Sequences == nil {
+		s.Sequences = make(map[string]int)
+	}
+	for entity, id := range s.lastIDs() {
+		s.Sequences[entity] = max(id, last[entity])
+	}
+	s.upgradeBooks()
+	s.rebuildIndexes()
+	if s.publicIDs {
+		s.backfillPublicIDs()
+	}
+
+	return s.compact()
+}
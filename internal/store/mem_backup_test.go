@@ -0,0 +1,158 @@
+package store_test
+
+import (
+	"Book-Store/internal/models"
+	"Book-Store/internal/store"
+	"Book-Store/internal/store/storetest"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackupsRotateAndRestore(t *testing.T) {
+	ctx := context.Background()
+	s := storetest.NewMemStore(t)
+	s.ConfigureBackups(filepath.Join(t.TempDir(), "backups"), 2)
+
+	save := func() {
+		t.Helper()
+		if err := s.SaveToFile(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	kept, err := s.CreateAuthor(ctx, models.Author{FirstName: "Ursula", LastName: "Le Guin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	save()
+	deleted, err := s.CreateAuthor(ctx, models.Author{FirstName: "Frank", LastName: "Herbert"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		save()
+	}
+
+	backups, err := s.ListBackups(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("ListBackups() = %d backups, want the 2 newest", len(backups))
+	}
+	if !backups[0].CreatedAt.Before(backups[1].CreatedAt) {
+		t.Errorf("ListBackups() = %+v, want oldest first", backups)
+	}
+
+	if err := s.DeleteAuthor(ctx, deleted.ID, models.AuthorDeletion{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RestoreBackup(ctx, backups[0].Name); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int{kept.ID, deleted.ID} {
+		if _, err := s.GetAuthor(ctx, id); err != nil {
+			t.Errorf("GetAuthor(%d) after restoring: %v", id, err)
+		}
+	}
+
+	if err := s.RestoreBackup(ctx, "database-missing.json"); !errors.Is(err, store.ErrBackupNotFound) {
+		t.Errorf("RestoreBackup(unknown) error = %v, want %v", err, store.ErrBackupNotFound)
+	}
+}
+
+func TestBackupsDisabled(t *testing.T) {
+	s := storetest.NewMemStore(t)
+	for range 2 {
+		if err := s.SaveToFile(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backups, err := s.ListBackups(context.Background())
+	if err != nil || len(backups) != 0 {
+		t.Errorf("ListBackups() without a backup directory = %+v, %v", backups, err)
+	}
+}
+
+func TestRestoreBackupKeepsSequences(t *testing.T) {
+	ctx := context.Background()
+	s := storetest.NewMemStore(t)
+	s.ConfigureBackups(filepath.Join(t.TempDir(), "backups"), 5)
+
+	if _, err := s.CreateAuthor(ctx, models.Author{FirstName: "Ursula", LastName: "Le Guin"}); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if err := s.SaveToFile(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backups, err := s.ListBackups(ctx)
+	if err != nil || len(backups) == 0 {
+		t.Fatalf("ListBackups() = %+v, %v", backups, err)
+	}
+
+	issued, err := s.CreateAuthor(ctx, models.Author{FirstName: "Frank", LastName: "Herbert"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RestoreBackup(ctx, backups[len(backups)-1].Name); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetAuthor(ctx, issued.ID); err == nil {
+		t.Fatalf("author %d created after the backup survived the restore", issued.ID)
+	}
+
+	next, err := s.CreateAuthor(ctx, models.Author{FirstName: "Octavia E.", LastName: "Butler"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.ID <= issued.ID {
+		t.Errorf("CreateAuthor() after restoring = ID %d, want after %d", next.ID, issued.ID)
+	}
+}
+
+func TestRestoreBackupBackfillsPublicIDs(t *testing.T) {
+	ctx := context.Background()
+	s := storetest.NewMemStore(t)
+	s.ConfigureBackups(filepath.Join(t.TempDir(), "backups"), 5)
+
+	author := mustAuthor(t, s, "Ursula", "Le Guin")
+	book := mustBook(t, s, models.Book{Title: "The Dispossessed", Author: author, Price: 10})
+	for range 2 {
+		if err := s.SaveToFile(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backups, err := s.ListBackups(ctx)
+	if err != nil || len(backups) == 0 {
+		t.Fatalf("ListBackups() = %+v, %v", backups, err)
+	}
+
+	if err := s.EnablePublicIDs(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RestoreBackup(ctx, backups[len(backups)-1].Name); err != nil {
+		t.Fatal(err)
+	}
+
+	restoredAuthor, err := s.GetAuthor(ctx, author.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restoredBook, err := s.GetBook(ctx, book.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restoredAuthor.PublicID == "" || restoredBook.PublicID == "" {
+		t.Fatalf("restore left author %q and book %q without public IDs", restoredAuthor.PublicID, restoredBook.PublicID)
+	}
+	if restoredBook.Author.PublicID != restoredAuthor.PublicID {
+		t.Errorf("book author public_id = %q, want %q", restoredBook.Author.PublicID, restoredAuthor.PublicID)
+	}
+	if id, ok := s.ResolvePublicID(ctx, "books", restoredBook.PublicID); !ok || id != book.ID {
+		t.Errorf("ResolvePublicID(books, %q) = %d, %v, want %d", restoredBook.PublicID, id, ok, book.ID)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"context"
 	"errors"
 	"slices"
-	"sort"
 	"strings"
 )
 
@@ -19,26 +18,24 @@ func (s *MemStore) CreateBook(ctx context.Context, book models.Book) (models.Boo
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	author, ok := s.Authors[book.Author.ID]
-	if !ok {
-		return models.Book{}, errors.New("author not found")
+	if err := s.creditBook(&book); err != nil {
+		return models.Book{}, err
 	}
 
-	book.Author.FirstName = author.FirstName
-	book.Author.LastName = author.LastName
-	book.Author.Bio = author.Bio
-
-	maxID := -1
-	for id := range s.Books {
-		if id > maxID {
-			maxID = id
-		}
+	if err := s.placeInSeries(&book); err != nil {
+		return models.Book{}, err
 	}
 
-	book.ID = maxID + 1
-	s.Books[book.ID] = book
+	if _, taken := s.idx.bookByISBN[book.ISBN]; taken && book.ISBN != "" {
+		return models.Book{}, ErrDuplicateISBN
+	}
 
-	if err := s.SaveToFile(); err != nil {
+	id, sequence := nextID(s, "books", s.Books)
+	book.ID = id
+	book.PublicID = s.publicID()
+	book.Cover = nil
+	book.Rating = models.RatingSummary{}
+	if err := s.commit(sequence, setEntry(s, "books", s.Books, book.ID, book)); err != nil {
 		return models.Book{}, err
 	}
 
@@ -62,6 +59,23 @@ func (s *MemStore) GetBook(ctx context.Context, id int) (models.Book, error) {
 	return book, nil
 }
 
+func (s *MemStore) GetBookByISBN(ctx context.Context, isbn string) (models.Book, error) {
+	select {
+	case <-ctx.Done():
+		return models.Book{}, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, exists := s.idx.bookByISBN[isbn]
+	if !exists {
+		return models.Book{}, errors.New("book not found")
+	}
+	return s.Books[id], nil
+}
+
 func (s *MemStore) UpdateBook(ctx context.Context, id int, book models.Book) (models.Book, error) {
 	select {
 	case <-ctx.Done():
@@ -72,23 +86,51 @@ func (s *MemStore) UpdateBook(ctx context.Context, id int, book models.Book) (mo
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.Books[id]; !exists {
+	existing, exists := s.Books[id]
+	if !exists {
 		return models.Book{}, errors.New("book not found")
 	}
 
-	author, ok := s.Authors[book.Author.ID]
-	if !ok {
-		return models.Book{}, errors.New("author not found")
+	if err := s.creditBook(&book); err != nil {
+		return models.Book{}, err
 	}
 
-	book.Author.FirstName = author.FirstName
-	book.Author.LastName = author.LastName
-	book.Author.Bio = author.Bio
+	if err := s.placeInSeries(&book); err != nil {
+		return models.Book{}, err
+	}
+
+	if other, taken := s.idx.bookByISBN[book.ISBN]; taken && other != id && book.ISBN != "" {
+		return models.Book{}, ErrDuplicateISBN
+	}
 
 	book.ID = id
-	s.Books[id] = book
+	book.PublicID = existing.PublicID
+	book.Cover = existing.Cover
+	book.Rating = existing.Rating
+	if err := s.commit(setEntry(s, "books", s.Books, id, book)); err != nil {
+		return models.Book{}, err
+	}
+
+	return book, nil
+}
+
+func (s *MemStore) SetBookCover(ctx context.Context, id int, cover *models.BookCover) (models.Book, error) {
+	select {
+	case <-ctx.Done():
+		return models.Book{}, ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	book, exists := s.Books[id]
+	if !exists {
+		return models.Book{}, errors.New("book not found")
+	}
 
-	if err := s.SaveToFile(); err != nil {
+	book.Cover = cover
+	if err := s.commit(setEntry(s, "books", s.Books, id, book)); err != nil {
 		return models.Book{}, err
 	}
 
@@ -109,54 +151,129 @@ func (s *MemStore) DeleteBook(ctx context.Context, id int) error {
 		return errors.New("book not found")
 	}
 
-	delete(s.Books, id)
-
-	if err := s.SaveToFile(); err != nil {
+	changes := append(s.deleteBookReviews(id), deleteEntry(s, "books", s.Books, id))
+	if err := s.commit(changes...); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (s *MemStore) SearchBooks(ctx context.Context, criteria models.SearchCriteria) ([]models.Book, error) {
+func (s *MemStore) SearchBooks(ctx context.Context, criteria models.SearchCriteria) (models.BookSearchResult, error) {
 	select {
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		return models.BookSearchResult{}, ctx.Err()
 	default:
 	}
 
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	results, err := s.matchBooks(ctx, criteria)
+	if err != nil {
+		return models.BookSearchResult{}, err
+	}
+	return pageBookResults(results, criteria, s.idx.unitsSold)
+}
+
+// ScanBooks takes the read lock for one batch at a time, so writers are not
+// held up while fn streams a batch out.
+func (s *MemStore) ScanBooks(ctx context.Context, criteria models.SearchCriteria, batch int, fn func(books []models.Book) error) error {
+	after := 0
+	for {
+		books, err := s.booksAfter(ctx, criteria, after, batch)
+		if err != nil {
+			return err
+		}
+		if len(books) == 0 {
+			return nil
+		}
+
+		if err := fn(books); err != nil {
+			return err
+		}
+		if len(books) < batch {
+			return nil
+		}
+		after = books[len(books)-1].ID
+	}
+}
+
+// booksAfter returns the first limit matches of criteria with IDs above
+// after, in ID order.
+func (s *MemStore) booksAfter(ctx context.Context, criteria models.SearchCriteria, after, limit int) ([]models.Book, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matches, err := s.matchBooks(ctx, criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	books := make([]models.Book, 0, limit)
+	for _, b := range matches {
+		if b.ID > after {
+			books = append(books, b)
+		}
+	}
+	slices.SortFunc(books, func(a, b models.Book) int { return a.ID - b.ID })
+	return books[:min(limit, len(books))], nil
+}
+
+// matchBooks returns the books matching the filters of criteria, scored
+// against its text query, in no particular order. Callers hold s.mu.
+func (s *MemStore) matchBooks(ctx context.Context, criteria models.SearchCriteria) ([]models.Book, error) {
+	candidates := s.Books
+	if criteria.Series != nil {
+		candidates = s.booksIn(s.idx.booksBySeries[*criteria.Series])
+	} else if criteria.AuthorID != nil {
+		candidates = s.booksIn(s.idx.booksByAuthor[*criteria.AuthorID])
+	} else if len(criteria.Genres) > 0 {
+		ids := make(idSet)
+		for _, genre := range criteria.Genres {
+			for id := range s.idx.booksByGenre[genre] {
+				ids[id] = struct{}{}
+			}
+		}
+		candidates = s.booksIn(ids)
+	}
+
+	var scores map[int]float64
+	if criteria.Query != "" {
+		scores = s.idx.text.Search(criteria.Query)
+	}
+
 	results := make([]models.Book, 0)
-	for _, b := range s.Books {
+	for _, b := range candidates {
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		default:
 		}
 
+		if scores != nil {
+			score, matched := scores[b.ID]
+			if !matched {
+				continue
+			}
+			b.Score = score
+		}
+
 		if criteria.Title != "" && !strings.Contains(strings.ToLower(b.Title), strings.ToLower(criteria.Title)) {
 			continue
 		}
 
-		if criteria.Author != "" {
-			searchWords := strings.Fields(strings.ToLower(criteria.Author))
-			first := strings.ToLower(b.Author.FirstName)
-			last := strings.ToLower(b.Author.LastName)
-			matched := false
-			for _, word := range searchWords {
-				if strings.Contains(first, word) || strings.Contains(last, word) {
-					matched = true
-					break
-				}
-			}
-			if !matched {
-				continue
-			}
+		if criteria.Author != "" && !creditsAuthorNamed(b, criteria.Author) {
+			continue
 		}
 
-		if criteria.Genre != "" && !slices.Contains(b.Genres, criteria.Genre) {
+		if criteria.AuthorID != nil && !creditsAuthor(b, *criteria.AuthorID) {
+			continue
+		}
+
+		if len(criteria.Genres) > 0 && !slices.ContainsFunc(b.Genres, func(genre string) bool {
+			return slices.Contains(criteria.Genres, genre)
+		}) {
 			continue
 		}
 
@@ -168,29 +285,62 @@ func (s *MemStore) SearchBooks(ctx context.Context, criteria models.SearchCriter
 			continue
 		}
 
+		if criteria.InStock != nil && (b.Stock > 0) != *criteria.InStock {
+			continue
+		}
+
+		if criteria.Series != nil && (b.Series == nil || b.Series.ID != *criteria.Series) {
+			continue
+		}
+
+		if criteria.MinRating != nil && b.Rating.Average < *criteria.MinRating {
+			continue
+		}
+
 		results = append(results, b)
 	}
+	return results, nil
+}
 
-	if criteria.SortBy != "" {
-		switch strings.ToLower(criteria.SortBy) {
-		case "title":
-			sort.Slice(results, func(i, j int) bool {
-				if strings.ToLower(criteria.SortOrder) == "desc" {
-					return results[i].Title > results[j].Title
-				}
-				return results[i].Title < results[j].Title
-			})
-		case "price":
-			sort.Slice(results, func(i, j int) bool {
-				if strings.ToLower(criteria.SortOrder) == "desc" {
-					return results[i].Price > results[j].Price
-				}
-				return results[i].Price < results[j].Price
-			})
-		}
+func (s *MemStore) ListAuthorBooks(ctx context.Context, authorID int, page models.PageRequest) (models.Page[models.Book], error) {
+	select {
+	case <-ctx.Done():
+		return models.Page[models.Book]{}, ctx.Err()
+	default:
 	}
 
-	return results, nil
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	books := make([]models.Book, 0, len(s.idx.booksByAuthor[authorID]))
+	for id := range s.idx.booksByAuthor[authorID] {
+		books = append(books, s.Books[id])
+	}
+	return pageOf(bookListOrder, books, page)
+}
+
+func (s *MemStore) SuggestBooks(ctx context.Context, prefix string, limit int) ([]models.Suggestion, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	completions := s.idx.suggestions.Complete(prefix, limit)
+	suggestions := make([]models.Suggestion, 0, len(completions))
+	for _, c := range completions {
+		suggestion := models.Suggestion{Type: c.Kind, ID: c.ID, Text: c.Text}
+		if c.Kind == models.SuggestionTitle {
+			suggestion.PublicID = s.Books[c.ID].PublicID
+		} else {
+			suggestion.PublicID = s.Authors[c.ID].PublicID
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, nil
 }
 
 func (s *MemStore) BookExists(id int) bool {
@@ -224,11 +374,68 @@ func (s *MemStore) OutOfStock() []models.Book {
 func (s *MemStore) GetBooksPerGenre(genre string) []models.Book {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	books := make([]models.Book, 0)
-	for _, book := range s.Books {
-		if slices.Contains(book.Genres, genre) {
-			books = append(books, book)
+	books := make([]models.Book, 0, len(s.idx.booksByGenre[genre]))
+	for id := range s.idx.booksByGenre[genre] {
+		books = append(books, s.Books[id])
+	}
+	return books
+}
+
+// creditBook checks that every contributor of book is a known author and
+// copies in their details. Callers hold s.mu.
+func (s *MemStore) creditBook(book *models.Book) error {
+	if err := book.NormalizeContributors(); err != nil {
+		return err
+	}
+
+	contributors := make([]models.Contributor, len(book.Contributors))
+	for i, c := range book.Contributors {
+		author, ok := s.Authors[c.Author.ID]
+		if !ok {
+			return errors.New("author not found")
 		}
+		contributors[i] = models.Contributor{Author: author, Role: c.Role}
+	}
+	book.Contributors = contributors
+	return book.NormalizeContributors()
+}
+
+// placeInSeries checks that the series of book exists and copies in its
+// name. Callers hold s.mu.
+func (s *MemStore) placeInSeries(book *models.Book) error {
+	if book.Series == nil {
+		return nil
+	}
+
+	series, exists := s.Series[book.Series.ID]
+	if !exists {
+		return errors.New("series not found")
+	}
+	book.Series = &models.SeriesEntry{
+		ID:       series.ID,
+		PublicID: series.PublicID,
+		Name:     series.Name,
+		Position: book.Series.Position,
+	}
+	return nil
+}
+
+// upgradeBooks credits the single author of books written before books had
+// contributors. Callers hold s.mu.
+func (s *MemStore) upgradeBooks() {
+	for id, book := range s.Books {
+		if len(book.Contributors) == 0 {
+			book.NormalizeContributors()
+			s.Books[id] = book
+		}
+	}
+}
+
+// booksIn returns the books whose IDs are in ids. Callers hold s.mu.
+func (s *MemStore) booksIn(ids idSet) map[int]models.Book {
+	books := make(map[int]models.Book, len(ids))
+	for id := range ids {
+		books[id] = s.Books[id]
 	}
 	return books
 }
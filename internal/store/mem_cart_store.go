@@ -0,0 +1,172 @@
+package store
+
+import (
+	"Book-Store/internal/models"
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+func (s *MemStore) GetCart(ctx context.Context, key string) (models.Cart, error) {
+	select {
+	case <-ctx.Done():
+		return models.Cart{}, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.cart(key), nil
+}
+
+func (s *MemStore) AddCartItem(ctx context.Context, key string, bookID, quantity int) (models.Cart, error) {
+	return s.changeCartItem(ctx, key, bookID, func(current int) int { return current + quantity })
+}
+
+func (s *MemStore) SetCartItem(ctx context.Context, key string, bookID, quantity int) (models.Cart, error) {
+	return s.changeCartItem(ctx, key, bookID, func(int) int { return quantity })
+}
+
+func (s *MemStore) changeCartItem(ctx context.Context, key string, bookID int, quantity func(current int) int) (models.Cart, error) {
+	select {
+	case <-ctx.Done():
+		return models.Cart{}, ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cart := s.cart(key)
+	current := 0
+	for _, item := range cart.Items {
+		if item.BookID == bookID {
+			current = item.Quantity
+		}
+	}
+
+	next := quantity(current)
+	if next < 0 {
+		return models.Cart{}, errors.New("quantity must not be negative")
+	}
+	// Books deleted from the catalog can still be taken out of the cart.
+	if _, exists := s.Books[bookID]; !exists && next > 0 {
+		return models.Cart{}, errors.New("book not found")
+	}
+	cart.Items = setCartQuantity(cart.Items, bookID, next)
+
+	return s.saveCart(key, cart)
+}
+
+func (s *MemStore) MergeCart(ctx context.Context, from, to string) (models.Cart, error) {
+	select {
+	case <-ctx.Done():
+		return models.Cart{}, ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	source, exists := s.Carts[from]
+	if !exists || from == to {
+		return s.cart(to), nil
+	}
+
+	return s.saveCart(to, mergeCartItems(s.cart(to), source), deleteEntry(s, "carts", s.Carts, from))
+}
+
+func (s *MemStore) DeleteCart(ctx context.Context, key string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.Carts[key]; !exists {
+		return nil
+	}
+	return s.commit(deleteEntry(s, "carts", s.Carts, key))
+}
+
+func (s *MemStore) ExpireSessionCarts(ctx context.Context, before time.Time) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var changes []journalChange
+	for key, cart := range s.Carts {
+		if strings.HasPrefix(key, models.SessionCartKey("")) && cart.UpdatedAt.Before(before) {
+			changes = append(changes, deleteEntry(s, "carts", s.Carts, key))
+		}
+	}
+	if len(changes) == 0 {
+		return 0, nil
+	}
+	return len(changes), s.commit(changes...)
+}
+
+// cart returns a copy of the cart under key. Callers hold s.mu.
+func (s *MemStore) cart(key string) models.Cart {
+	cart := s.Carts[key]
+	cart.Items = append(make([]models.CartItem, 0, len(cart.Items)), cart.Items...)
+	return cart
+}
+
+// saveCart stores cart under key, dropping it once it is empty. It commits
+// along with changes already made. Callers hold s.mu.
+func (s *MemStore) saveCart(key string, cart models.Cart, changes ...journalChange) (models.Cart, error) {
+	cart.UpdatedAt = time.Now().UTC()
+
+	if len(cart.Items) > 0 {
+		changes = append(changes, setEntry(s, "carts", s.Carts, key, cart))
+	} else if _, exists := s.Carts[key]; exists {
+		changes = append(changes, deleteEntry(s, "carts", s.Carts, key))
+	}
+	if err := s.commit(changes...); err != nil {
+		return models.Cart{}, err
+	}
+	return cart, nil
+}
+
+// setCartQuantity sets the quantity of a book in items, appending it if it
+// is new and removing it at zero.
+func setCartQuantity(items []models.CartItem, bookID, quantity int) []models.CartItem {
+	for i, item := range items {
+		if item.BookID == bookID {
+			if quantity == 0 {
+				return append(items[:i], items[i+1:]...)
+			}
+			items[i].Quantity = quantity
+			return items
+		}
+	}
+	if quantity == 0 {
+		return items
+	}
+	return append(items, models.CartItem{BookID: bookID, Quantity: quantity})
+}
+
+// mergeCartItems adds the items of source to cart.
+func mergeCartItems(cart, source models.Cart) models.Cart {
+	for _, item := range source.Items {
+		current := 0
+		for _, existing := range cart.Items {
+			if existing.BookID == item.BookID {
+				current = existing.Quantity
+			}
+		}
+		cart.Items = setCartQuantity(cart.Items, item.BookID, current+item.Quantity)
+	}
+	return cart
+}
@@ -17,27 +17,18 @@ func (s *MemStore) CreateCustomer(ctx context.Context, customer models.Customer)
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	maxID := -1
-	for id := range s.Customers {
-		if id > maxID {
-			maxID = id
-		}
+	if _, taken := s.idx.customerByEmail[customer.Email]; taken {
+		return models.Customer{}, errors.New("Email already exists")
 	}
 
-	customer.ID = maxID + 1
+	id, sequence := nextID(s, "customers", s.Customers)
+	customer.ID = id
+	customer.PublicID = s.publicID()
 	if customer.CreatedAt.IsZero() {
 		customer.CreatedAt = time.Now()
 	}
 
-	for _, c := range s.Customers {
-		if c.Email == customer.Email {
-			return models.Customer{}, errors.New("Email already exists")
-		}
-	}
-
-	s.Customers[customer.ID] = customer
-
-	if err := s.SaveToFile(); err != nil {
+	if err := s.commit(sequence, setEntry(s, "customers", s.Customers, customer.ID, customer)); err != nil {
 		return models.Customer{}, err
 	}
 
@@ -62,6 +53,24 @@ func (s *MemStore) GetCustomer(ctx context.Context, id int) (models.Customer, er
 	return customer, nil
 }
 
+func (s *MemStore) GetCustomerByEmail(ctx context.Context, email string) (models.Customer, error) {
+	select {
+	case <-ctx.Done():
+		return models.Customer{}, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, ok := s.idx.customerByEmail[email]
+	if !ok {
+		return models.Customer{}, errors.New("Customer not found")
+	}
+
+	return s.Customers[id], nil
+}
+
 func (s *MemStore) UpdateCustomer(ctx context.Context, id int, customer models.Customer) (models.Customer, error) {
 	select {
 	case <-ctx.Done():
@@ -82,33 +91,37 @@ func (s *MemStore) UpdateCustomer(ctx context.Context, id int, customer models.C
 		existing.Name = customer.Name
 	}
 	if customer.Email != "" {
+		if other, taken := s.idx.customerByEmail[customer.Email]; taken && other != id {
+			return models.Customer{}, errors.New("Email already exists")
+		}
 		existing.Email = customer.Email
 	}
+	if customer.Role != "" {
+		existing.Role = customer.Role
+	}
 
-	s.Customers[id] = existing
-
-	if err := s.SaveToFile(); err != nil {
+	if err := s.commit(setEntry(s, "customers", s.Customers, id, existing)); err != nil {
 		return models.Customer{}, err
 	}
 
-	return customer, nil
+	return existing, nil
 }
 
-func (s *MemStore) ListCustomers(ctx context.Context) ([]models.Customer, error) {
+func (s *MemStore) ListCustomers(ctx context.Context, page models.PageRequest) (models.Page[models.Customer], error) {
 	select {
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		return models.Page[models.Customer]{}, ctx.Err()
 	default:
 	}
 
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	customers := make([]models.Customer, 0)
+	customers := make([]models.Customer, 0, len(s.Customers))
 	for _, customer := range s.Customers {
 		customers = append(customers, customer)
 	}
-	return customers, nil
+	return pageOf(customerOrder, customers, page)
 }
 
 func (s *MemStore) DeleteCustomer(ctx context.Context, id int) error {
@@ -126,9 +139,7 @@ func (s *MemStore) DeleteCustomer(ctx context.Context, id int) error {
 		return errors.New("Customer not found")
 	}
 
-	delete(s.Customers, id)
-
-	if err := s.SaveToFile(); err != nil {
+	if err := s.commit(deleteEntry(s, "customers", s.Customers, id)); err != nil {
 		return err
 	}
 
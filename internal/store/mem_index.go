@@ -0,0 +1,244 @@
+package store
+
+import (
+	"Book-Store/internal/models"
+	"Book-Store/internal/search"
+	"sort"
+	"time"
+)
+
+// idSet is a set of record IDs held by an index entry.
+type idSet map[int]struct{}
+
+// orderTime positions an order in the time-ordered order index.
+type orderTime struct {
+	createdAt time.Time
+	id        int
+}
+
+func (o orderTime) before(other orderTime) bool {
+	if !o.createdAt.Equal(other.createdAt) {
+		return o.createdAt.Before(other.createdAt)
+	}
+	return o.id < other.id
+}
+
+// memIndexes holds lookups derived from the MemStore maps. They are never
+// persisted: setEntry and deleteEntry keep them current and rebuildIndexes
+// recreates them after a load or restore.
+type memIndexes struct {
+	publicIDs map[string]map[string]int
+
+	booksByGenre     map[string]idSet
+	booksByAuthor    map[int]idSet
+	bookByISBN       map[string]int
+	booksBySeries    map[int]idSet
+	reviewsByBook    map[int]idSet
+	ordersByStatus   map[string]idSet
+	ordersByCustomer map[int]idSet
+	customerByEmail  map[string]int
+	// unitsSold is the quantity of each book across completed orders.
+	unitsSold map[int]int
+	// ordersByTime is sorted by creation time, then ID.
+	ordersByTime []orderTime
+	text         *search.Index
+	suggestions  *search.Trie
+}
+
+func newMemIndexes() *memIndexes {
+	return &memIndexes{
+		publicIDs: map[string]map[string]int{
+			"books":     {},
+			"authors":   {},
+			"series":    {},
+			"reviews":   {},
+			"customers": {},
+			"orders":    {},
+		},
+		booksByGenre:     make(map[string]idSet),
+		booksByAuthor:    make(map[int]idSet),
+		bookByISBN:       make(map[string]int),
+		booksBySeries:    make(map[int]idSet),
+		reviewsByBook:    make(map[int]idSet),
+		ordersByStatus:   make(map[string]idSet),
+		ordersByCustomer: make(map[int]idSet),
+		customerByEmail:  make(map[string]int),
+		unitsSold:        make(map[int]int),
+		text:             search.NewIndex(),
+		suggestions:      search.NewTrie(),
+	}
+}
+
+func (idx *memIndexes) add(value any) {
+	switch v := value.(type) {
+	case models.Book:
+		idx.addPublicID("books", v.PublicID, v.ID)
+		for _, genre := range v.Genres {
+			addToSet(idx.booksByGenre, genre, v.ID)
+		}
+		for _, author := range v.CreditedAuthors() {
+			addToSet(idx.booksByAuthor, author.ID, v.ID)
+		}
+		if v.ISBN != "" {
+			idx.bookByISBN[v.ISBN] = v.ID
+		}
+		if v.Series != nil {
+			addToSet(idx.booksBySeries, v.Series.ID, v.ID)
+		}
+		idx.text.Add(v.ID, bookSearchFields(v)...)
+		idx.suggestions.Add(titleSuggestion(v))
+	case models.Author:
+		idx.addPublicID("authors", v.PublicID, v.ID)
+		idx.suggestions.Add(authorSuggestion(v))
+	case models.Series:
+		idx.addPublicID("series", v.PublicID, v.ID)
+	case models.Review:
+		idx.addPublicID("reviews", v.PublicID, v.ID)
+		addToSet(idx.reviewsByBook, v.BookID, v.ID)
+	case models.Customer:
+		idx.addPublicID("customers", v.PublicID, v.ID)
+		idx.customerByEmail[v.Email] = v.ID
+	case models.Order:
+		idx.addPublicID("orders", v.PublicID, v.ID)
+		addToSet(idx.ordersByStatus, v.Status, v.ID)
+		addToSet(idx.ordersByCustomer, v.Customer.ID, v.ID)
+		idx.addOrderTime(orderTime{createdAt: v.CreatedAt, id: v.ID})
+		idx.countSold(v, 1)
+	}
+}
+
+func (idx *memIndexes) remove(value any) {
+	switch v := value.(type) {
+	case models.Book:
+		delete(idx.publicIDs["books"], v.PublicID)
+		for _, genre := range v.Genres {
+			removeFromSet(idx.booksByGenre, genre, v.ID)
+		}
+		for _, author := range v.CreditedAuthors() {
+			removeFromSet(idx.booksByAuthor, author.ID, v.ID)
+		}
+		if idx.bookByISBN[v.ISBN] == v.ID {
+			delete(idx.bookByISBN, v.ISBN)
+		}
+		if v.Series != nil {
+			removeFromSet(idx.booksBySeries, v.Series.ID, v.ID)
+		}
+		idx.text.Remove(v.ID)
+		idx.suggestions.Remove(models.SuggestionTitle, v.ID)
+	case models.Author:
+		delete(idx.publicIDs["authors"], v.PublicID)
+		idx.suggestions.Remove(models.SuggestionAuthor, v.ID)
+	case models.Series:
+		delete(idx.publicIDs["series"], v.PublicID)
+	case models.Review:
+		delete(idx.publicIDs["reviews"], v.PublicID)
+		removeFromSet(idx.reviewsByBook, v.BookID, v.ID)
+	case models.Customer:
+		delete(idx.publicIDs["customers"], v.PublicID)
+		if idx.customerByEmail[v.Email] == v.ID {
+			delete(idx.customerByEmail, v.Email)
+		}
+	case models.Order:
+		delete(idx.publicIDs["orders"], v.PublicID)
+		removeFromSet(idx.ordersByStatus, v.Status, v.ID)
+		removeFromSet(idx.ordersByCustomer, v.Customer.ID, v.ID)
+		idx.removeOrderTime(orderTime{createdAt: v.CreatedAt, id: v.ID})
+		idx.countSold(v, -1)
+	}
+}
+
+func (idx *memIndexes) addPublicID(entity, publicID string, id int) {
+	if publicID != "" {
+		idx.publicIDs[entity][publicID] = id
+	}
+}
+
+// countSold adds (sign 1) or takes back (sign -1) the items of a completed
+// order in unitsSold.
+func (idx *memIndexes) countSold(order models.Order, sign int) {
+	if order.Status != "completed" {
+		return
+	}
+	for _, item := range order.Items {
+		idx.unitsSold[item.Book.ID] += sign * item.Quantity
+		if idx.unitsSold[item.Book.ID] == 0 {
+			delete(idx.unitsSold, item.Book.ID)
+		}
+	}
+}
+
+func addToSet[K comparable](index map[K]idSet, key K, id int) {
+	set, ok := index[key]
+	if !ok {
+		set = make(idSet)
+		index[key] = set
+	}
+	set[id] = struct{}{}
+}
+
+func removeFromSet[K comparable](index map[K]idSet, key K, id int) {
+	set, ok := index[key]
+	if !ok {
+		return
+	}
+	delete(set, id)
+	if len(set) == 0 {
+		delete(index, key)
+	}
+}
+
+func (idx *memIndexes) addOrderTime(entry orderTime) {
+	i := sort.Search(len(idx.ordersByTime), func(i int) bool {
+		return !idx.ordersByTime[i].before(entry)
+	})
+	idx.ordersByTime = append(idx.ordersByTime, orderTime{})
+	copy(idx.ordersByTime[i+1:], idx.ordersByTime[i:])
+	idx.ordersByTime[i] = entry
+}
+
+func (idx *memIndexes) removeOrderTime(entry orderTime) {
+	i := sort.Search(len(idx.ordersByTime), func(i int) bool {
+		return !idx.ordersByTime[i].before(entry)
+	})
+	if i < len(idx.ordersByTime) && idx.ordersByTime[i].id == entry.id {
+		idx.ordersByTime = append(idx.ordersByTime[:i], idx.ordersByTime[i+1:]...)
+	}
+}
+
+// ordersBetween returns the IDs of orders created within [start, end], compared
+// at second precision, oldest first.
+func (idx *memIndexes) ordersBetween(start, end time.Time) []int {
+	startUnix, endUnix := start.Unix(), end.Unix()
+	i := sort.Search(len(idx.ordersByTime), func(i int) bool {
+		return idx.ordersByTime[i].createdAt.Unix() >= startUnix
+	})
+
+	ids := make([]int, 0)
+	for ; i < len(idx.ordersByTime) && idx.ordersByTime[i].createdAt.Unix() <= endUnix; i++ {
+		ids = append(ids, idx.ordersByTime[i].id)
+	}
+	return ids
+}
+
+// rebuildIndexes recreates every index from the maps. Callers hold s.mu.
+func (s *MemStore) rebuildIndexes() {
+	s.idx = newMemIndexes()
+	for _, book := range s.Books {
+		s.idx.add(book)
+	}
+	for _, author := range s.Authors {
+		s.idx.add(author)
+	}
+	for _, series := range s.Series {
+		s.idx.add(series)
+	}
+	for _, review := range s.Reviews {
+		s.idx.add(review)
+	}
+	for _, customer := range s.Customers {
+		s.idx.add(customer)
+	}
+	for _, order := range s.Orders {
+		s.idx.add(order)
+	}
+}
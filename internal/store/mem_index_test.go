@@ -0,0 +1,174 @@
+package store_test
+
+import (
+	"Book-Store/internal/models"
+	"Book-Store/internal/store"
+	"Book-Store/internal/store/storetest"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIndexesFollowChanges(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "database.json")
+	s := storetest.OpenMemStore(t, path)
+
+	check := func(step string) {
+		t.Helper()
+		if err := s.CheckIndexes(); err != nil {
+			t.Fatalf("after %s: %v", step, err)
+		}
+	}
+
+	if err := s.EnablePublicIDs(ctx); err != nil {
+		t.Fatal(err)
+	}
+	leGuin, err := s.CreateAuthor(ctx, models.Author{FirstName: "Ursula", LastName: "Le Guin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	herbert, err := s.CreateAuthor(ctx, models.Author{FirstName: "Frank", LastName: "Herbert"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hainish, err := s.CreateSeries(ctx, models.Series{Name: "Hainish Cycle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	book, err := s.CreateBook(ctx, models.Book{
+		Title:  "The Dispossessed",
+		Author: models.Author{ID: leGuin.ID},
+		Genres: []string{"sf", "utopia"},
+		ISBN:   "9780060512750",
+		Series: &models.SeriesEntry{ID: hainish.ID, Position: 5},
+		Stock:  5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := s.CreateBook(ctx, models.Book{Title: "Dune", Author: models.Author{ID: herbert.ID}, Genres: []string{"sf"}, Stock: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	customer, err := s.CreateCustomer(ctx, models.Customer{Name: "Shevek", Email: "shevek@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaving, err := s.CreateCustomer(ctx, models.Customer{Name: "Takver", Email: "takver@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var orders []models.Order
+	for _, c := range []models.Customer{customer, customer, leaving} {
+		o, err := s.CreateOrder(ctx, models.Order{
+			Customer: models.Customer{ID: c.ID},
+			Items:    []models.OrderItem{{Book: models.Book{ID: other.ID}, Quantity: 1}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		orders = append(orders, o)
+	}
+	check("creating records")
+
+	if _, err := s.UpdateBook(ctx, book.ID, models.Book{
+		Title:        "The Dispossessed",
+		Contributors: []models.Contributor{{Author: models.Author{ID: herbert.ID}}, {Author: models.Author{ID: leGuin.ID}, Role: models.ContributorEditor}},
+		Genres:       []string{"fantasy"},
+		ISBN:         "9780061054884",
+		Stock:        5,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UpdateCustomer(ctx, customer.ID, models.Customer{Email: "shevek@anarres.example"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CompleteOrder(ctx, orders[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CancelOrder(ctx, orders[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateReview(ctx, models.Review{BookID: other.ID, CustomerID: customer.ID, Rating: 4}); err != nil {
+		t.Fatal(err)
+	}
+	check("updating records")
+
+	if err := s.DeleteBook(ctx, other.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteCustomer(ctx, leaving.ID); err != nil {
+		t.Fatal(err)
+	}
+	check("deleting records")
+
+	err = s.WithTx(ctx, func(tx store.Stores) error {
+		dune, err := tx.CreateSeries(ctx, models.Series{Name: "Dune"})
+		if err != nil {
+			return err
+		}
+		if _, err := tx.CreateBook(ctx, models.Book{
+			Title:  "Dune Messiah",
+			Author: models.Author{ID: herbert.ID},
+			Genres: []string{"sf"},
+			ISBN:   "9780593098233",
+			Series: &models.SeriesEntry{ID: dune.ID, Position: 2},
+		}); err != nil {
+			return err
+		}
+		if _, err := tx.UpdateCustomer(ctx, customer.ID, models.Customer{Email: "rolled-back@example.com"}); err != nil {
+			return err
+		}
+		if _, err := tx.CompleteOrder(ctx, orders[2].ID); err != nil {
+			return err
+		}
+		bought, err := tx.CreateOrder(ctx, models.Order{
+			Customer: models.Customer{ID: customer.ID},
+			Items:    []models.OrderItem{{Book: models.Book{ID: book.ID}, Quantity: 1}},
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := tx.CompleteOrder(ctx, bought.ID); err != nil {
+			return err
+		}
+		if _, err := tx.CreateReview(ctx, models.Review{BookID: book.ID, CustomerID: customer.ID, Rating: 2}); err != nil {
+			return err
+		}
+		if err := tx.DeleteBook(ctx, book.ID); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if err != errAbort {
+		t.Fatalf("WithTx() error = %v, want %v", err, errAbort)
+	}
+	check("rolling back a transaction")
+
+	// The indexed queries still answer from the restored state.
+	if _, err := s.GetCustomerByEmail(ctx, "rolled-back@example.com"); err == nil {
+		t.Error("email index kept a rolled back change")
+	}
+	if got, err := s.GetBookByISBN(ctx, "9780061054884"); err != nil || got.ID != book.ID {
+		t.Errorf("GetBookByISBN() = %+v, %v, want the restored book", got, err)
+	}
+	if got := s.GetBooksPerGenre("fantasy"); len(got) != 1 || got[0].ID != book.ID {
+		t.Errorf("GetBooksPerGenre(fantasy) = %+v, want the restored book", got)
+	}
+	if created, err := s.SearchOrderByStatus(ctx, "created", models.PageRequest{}); err != nil || len(created.Items) != 1 || created.Items[0].ID != orders[2].ID {
+		t.Errorf("SearchOrderByStatus(created) = %+v, %v", created, err)
+	}
+
+	// Replaying the journal after a crash ends with the same indexes.
+	reopened := storetest.OpenMemStore(t, path)
+	if err := reopened.CheckIndexesMatch(s); err != nil {
+		t.Fatalf("after replaying the journal: %v", err)
+	}
+	now := time.Now()
+	inRange, err := reopened.GetOrdersInTimeRange(ctx, now.Add(-time.Hour), now.Add(time.Hour), models.PageRequest{})
+	if err != nil || len(inRange.Items) != len(orders) {
+		t.Errorf("GetOrdersInTimeRange() after replay found %d orders, %v; want %d", len(inRange.Items), err, len(orders))
+	}
+}
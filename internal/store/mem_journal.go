@@ -0,0 +1,258 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// journalCompactThreshold is how many records the journal may hold before it
+// is folded into a fresh snapshot of database.json.
+const journalCompactThreshold = 1000
+
+const (
+	journalPut    = "put"
+	journalDelete = "delete"
+)
+
+type journalChange struct {
+	Entity string          `json:"entity"`
+	Op     string          `json:"op"`
+	Key    string          `json:"key"`
+	Value  json.RawMessage `json:"value,omitempty"`
+}
+
+// journalRecord is one line of the journal. All changes made by a single store
+// call share a record, so a torn write can never apply half an order.
+type journalRecord struct {
+	Changes []journalChange `json:"changes"`
+}
+
+func putChange(entity string, key any, value any) journalChange {
+	data, err := json.Marshal(value)
+	if err != nil {
+		// Models are plain structs; this can't fail.
+		panic(err)
+	}
+	return journalChange{Entity: entity, Op: journalPut, Key: fmt.Sprint(key), Value: data}
+}
+
+func deleteChange(entity string, key any) journalChange {
+	return journalChange{Entity: entity, Op: journalDelete, Key: fmt.Sprint(key)}
+}
+
+func journalPath(dbPath string) string {
+	return dbPath + ".journal"
+}
+
+// commit makes changes already applied to the maps durable. If they can't be
+// written they are undone, so the store never serves data a restart would
+// lose. Callers hold s.mu.
+func (s *MemStore) commit(changes ...journalChange) error {
+	if s.inTx {
+		s.txChanges = append(s.txChanges, changes...)
+		return nil
+	}
+	if err := s.persist(changes); err != nil {
+		s.rollback()
+		return err
+	}
+	s.undo = nil
+
+	if s.journalRecords >= journalCompactThreshold {
+		return s.compact()
+	}
+	return nil
+}
+
+// persist appends changes to the journal as one record, or writes a snapshot
+// when there is no journal. A record that fails to write is cut off again so
+// the next one doesn't follow a torn line.
+func (s *MemStore) persist(changes []journalChange) error {
+	if s.journal == nil {
+		return s.SaveToFile()
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	line, err := json.Marshal(journalRecord{Changes: changes})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	offset, err := s.journal.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := s.journal.Write(line); err != nil {
+		return errors.Join(err, s.truncateJournal(offset))
+	}
+	if err := s.journal.Sync(); err != nil {
+		return errors.Join(err, s.truncateJournal(offset))
+	}
+
+	s.journalRecords++
+	return nil
+}
+
+// truncateJournal cuts the journal back to size and appends from there.
+func (s *MemStore) truncateJournal(size int64) error {
+	if err := s.journal.Truncate(size); err != nil {
+		return err
+	}
+	_, err := s.journal.Seek(size, io.SeekStart)
+	return err
+}
+
+// compact writes a snapshot of the current state and empties the journal.
+// Replaying a journal over a snapshot that already contains its changes is
+// harmless, so a crash between the two steps loses nothing.
+func (s *MemStore) compact() error {
+	if err := s.SaveToFile(); err != nil {
+		return err
+	}
+	// The snapshot holds every change so far; none is left to undo.
+	s.undo = nil
+
+	if s.journal == nil {
+		return nil
+	}
+	if err := s.truncateJournal(0); err != nil {
+		return err
+	}
+	s.journalRecords = 0
+	return s.journal.Sync()
+}
+
+// openJournal replays the journal next to the snapshot and keeps it open for
+// appending. A final line that doesn't parse is a write torn by a crash and is
+// cut off; a bad line anywhere else is real corruption.
+func (s *MemStore) openJournal(path string) error {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(file)
+	var offset int64
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var record journalRecord
+			if err := json.Unmarshal(line, &record); err != nil {
+				if readErr == io.EOF {
+					break
+				}
+				file.Close()
+				return fmt.Errorf("corrupt journal record at offset %d: %w", offset, err)
+			}
+			if err := s.applyRecord(record); err != nil {
+				file.Close()
+				return err
+			}
+			s.journalRecords++
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			file.Close()
+			return readErr
+		}
+		offset += int64(len(line))
+	}
+
+	if err := file.Truncate(offset); err != nil {
+		file.Close()
+		return err
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return err
+	}
+
+	s.journal = file
+	return nil
+}
+
+func (s *MemStore) applyRecord(record journalRecord) error {
+	for _, change := range record.Changes {
+		if err := s.applyChange(change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemStore) applyChange(change journalChange) error {
+	switch change.Entity {
+	case "carts":
+		return applyToMap(s.Carts, change.Key, change)
+	case "refresh_tokens":
+		return applyToMap(s.RefreshTokens, change.Key, change)
+	case "sequences":
+		return applyToMap(s.Sequences, change.Key, change)
+	}
+
+	id, err := strconv.Atoi(change.Key)
+	if err != nil {
+		return fmt.Errorf("bad journal key %q for %s: %w", change.Key, change.Entity, err)
+	}
+
+	switch change.Entity {
+	case "books":
+		return applyToMap(s.Books, id, change)
+	case "authors":
+		return applyToMap(s.Authors, id, change)
+	case "series":
+		return applyToMap(s.Series, id, change)
+	case "reviews":
+		return applyToMap(s.Reviews, id, change)
+	case "customers":
+		return applyToMap(s.Customers, id, change)
+	case "orders":
+		return applyToMap(s.Orders, id, change)
+	default:
+		return fmt.Errorf("unknown journal entity %q", change.Entity)
+	}
+}
+
+func applyToMap[K comparable, V any](m map[K]V, key K, change journalChange) error {
+	switch change.Op {
+	case journalPut:
+		var value V
+		if err := json.Unmarshal(change.Value, &value); err != nil {
+			return err
+		}
+		m[key] = value
+	case journalDelete:
+		delete(m, key)
+	default:
+		return fmt.Errorf("unknown journal op %q", change.Op)
+	}
+	return nil
+}
+
+// Close folds the journal into the snapshot so the next start has nothing to
+// replay.
+func (s *MemStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.journal == nil {
+		return nil
+	}
+	if err := s.compact(); err != nil {
+		return err
+	}
+	err := s.journal.Close()
+	s.journal = nil
+	return err
+}
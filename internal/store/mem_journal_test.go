@@ -0,0 +1,134 @@
+package store_test
+
+import (
+	"Book-Store/internal/models"
+	"Book-Store/internal/store"
+	"Book-Store/internal/store/storetest"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJournalReplay(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "database.json")
+
+	s := storetest.OpenMemStore(t, path)
+	kept, err := s.CreateAuthor(ctx, models.Author{FirstName: "Ursula", LastName: "Le Guin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dropped, err := s.CreateAuthor(ctx, models.Author{FirstName: "Frank", LastName: "Herbert"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteAuthor(ctx, dropped.ID, models.AuthorDeletion{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash: the journal was never folded into the snapshot.
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("snapshot written before compaction: %v", err)
+	}
+
+	reopened := storetest.OpenMemStore(t, path)
+	if got, err := reopened.GetAuthor(ctx, kept.ID); err != nil || got.LastName != "Le Guin" {
+		t.Errorf("GetAuthor(%d) = %+v, %v, want the replayed author", kept.ID, got, err)
+	}
+	if _, err := reopened.GetAuthor(ctx, dropped.ID); err == nil {
+		t.Errorf("GetAuthor(%d) found an author deleted before the crash", dropped.ID)
+	}
+}
+
+func TestJournalTornAndCorruptRecords(t *testing.T) {
+	const record = `{"changes":[{"entity":"authors","op":"put","key":"7","value":{"id":7,"first_name":"Ann","last_name":"Leckie"}}]}`
+
+	tests := []struct {
+		name      string
+		journal   string
+		wantErr   bool
+		wantAuths int
+	}{
+		{name: "complete records", journal: record + "\n" + record + "\n", wantAuths: 1},
+		{name: "torn final record", journal: record + "\n" + `{"changes":[{"entity":"auth`, wantAuths: 1},
+		{name: "blank lines", journal: "\n" + record + "\n\n", wantAuths: 1},
+		{name: "corrupt record before others", journal: `{"changes":` + "\n" + record + "\n", wantErr: true},
+		{name: "unknown entity", journal: `{"changes":[{"entity":"planets","op":"put","key":"1","value":{}}]}` + "\n", wantErr: true},
+		{name: "unknown op", journal: `{"changes":[{"entity":"authors","op":"merge","key":"1","value":{}}]}` + "\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "database.json")
+			if err := os.WriteFile(store.JournalPath(path), []byte(tt.journal), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			s := store.NewMemStore()
+			err := s.LoadFromFile(path)
+			defer s.Close()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("LoadFromFile() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFromFile() error = %v", err)
+			}
+			if n := s.AuthorsCount(); n != tt.wantAuths {
+				t.Errorf("loaded %d authors, want %d", n, tt.wantAuths)
+			}
+
+			// Loading folds the journal into the snapshot and empties it.
+			data, err := os.ReadFile(store.JournalPath(path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(data) != 0 {
+				t.Errorf("journal holds %q after loading, want it empty", data)
+			}
+			snapshot, err := os.ReadFile(path)
+			if err != nil || !strings.Contains(string(snapshot), "Leckie") {
+				t.Errorf("snapshot = %q, %v, want the replayed author", snapshot, err)
+			}
+		})
+	}
+}
+
+func TestFailedJournalWriteIsUndone(t *testing.T) {
+	ctx := context.Background()
+	s := storetest.NewMemStore(t)
+	kept := mustAuthor(t, s, "Ursula", "Le Guin")
+	book := mustBook(t, s, models.Book{Title: "The Dispossessed", Author: kept, Price: 10, Stock: 3})
+
+	if err := s.BreakJournal(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.CreateAuthor(ctx, models.Author{FirstName: "Frank", LastName: "Herbert"}); err == nil {
+		t.Error("CreateAuthor() with a broken journal succeeded")
+	}
+	book.Stock = 0
+	if _, err := s.UpdateBook(ctx, book.ID, book); err == nil {
+		t.Error("UpdateBook() with a broken journal succeeded")
+	}
+	err := s.WithTx(ctx, func(tx store.Stores) error {
+		_, err := tx.CreateAuthor(ctx, models.Author{FirstName: "Ann", LastName: "Leckie"})
+		return err
+	})
+	if err == nil {
+		t.Error("WithTx() with a broken journal succeeded")
+	}
+
+	if n := s.AuthorsCount(); n != 1 {
+		t.Errorf("AuthorsCount() = %d after failed writes, want 1", n)
+	}
+	if got, err := s.GetBook(ctx, book.ID); err != nil || got.Stock != 3 {
+		t.Errorf("GetBook(%d) = stock %d, %v, want the stock before the failed update", book.ID, got.Stock, err)
+	}
+	if err := s.CheckIndexes(); err != nil {
+		t.Error(err)
+	}
+}
@@ -17,11 +17,16 @@ func (s *MemStore) CreateOrder(ctx context.Context, order models.Order) (models.
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.Customers[order.Customer.ID]; !exists {
+	customer, exists := s.Customers[order.Customer.ID]
+	if !exists {
 		return models.Order{}, errors.New("customer not found")
 	}
+	order.Customer.PublicID = customer.PublicID
 
+	// Stock is worked out on copies first so a failing item leaves the
+	// store untouched.
 	var totalPrice float64
+	updated := make(map[int]models.Book)
 	for i, item := range order.Items {
 		select {
 		case <-ctx.Done():
@@ -29,7 +34,10 @@ func (s *MemStore) CreateOrder(ctx context.Context, order models.Order) (models.
 		default:
 		}
 
-		book, exists := s.Books[item.Book.ID]
+		book, exists := updated[item.Book.ID]
+		if !exists {
+			book, exists = s.Books[item.Book.ID]
+		}
 		if !exists {
 			return models.Order{}, errors.New("book not found in order")
 		}
@@ -37,25 +45,27 @@ func (s *MemStore) CreateOrder(ctx context.Context, order models.Order) (models.
 			return models.Order{}, errors.New("insufficient stock")
 		}
 		book.Stock -= item.Quantity
-		s.Books[book.ID] = book
+		updated[book.ID] = book
 		order.Items[i].Book = book
 		totalPrice += book.Price * float64(item.Quantity)
 	}
 
-	maxID := -1
-	for id := range s.Orders {
-		if id > maxID {
-			maxID = id
-		}
+	changes := make([]journalChange, 0, len(updated)+1)
+	for id, book := range updated {
+		changes = append(changes, setEntry(s, "books", s.Books, id, book))
 	}
 
-	order.ID = maxID + 1
+	id, sequence := nextID(s, "orders", s.Orders)
+	changes = append(changes, sequence)
+
+	order.ID = id
+	order.PublicID = s.publicID()
 	order.Status = "created"
 	order.TotalPrice = totalPrice
 	order.CreatedAt = time.Now()
-	s.Orders[order.ID] = order
+	changes = append(changes, setEntry(s, "orders", s.Orders, order.ID, order))
 
-	if err := s.SaveToFile(); err != nil {
+	if err := s.commit(changes...); err != nil {
 		return models.Order{}, err
 	}
 
@@ -97,8 +107,7 @@ func (s *MemStore) CompleteOrder(ctx context.Context, id int) (bool, error) {
 		return false, errors.New("order cannot be completed")
 	}
 	order.Status = "completed"
-	s.Orders[id] = order
-	return true, s.SaveToFile()
+	return true, s.commit(setEntry(s, "orders", s.Orders, id, order))
 }
 
 func (s *MemStore) CancelOrder(ctx context.Context, id int) (bool, error) {
@@ -118,74 +127,67 @@ func (s *MemStore) CancelOrder(ctx context.Context, id int) (bool, error) {
 	if order.Status != "created" {
 		return false, errors.New("order cannot be cancelled")
 	}
+	changes := make([]journalChange, 0, len(order.Items)+1)
 	for _, item := range order.Items {
 		if book, exists := s.Books[item.Book.ID]; exists {
 			book.Stock += item.Quantity
-			s.Books[book.ID] = book
+			changes = append(changes, setEntry(s, "books", s.Books, book.ID, book))
 		}
 	}
 	order.Status = "cancelled"
-	s.Orders[id] = order
-	return true, s.SaveToFile()
+	changes = append(changes, setEntry(s, "orders", s.Orders, id, order))
+	return true, s.commit(changes...)
 }
 
-func (s *MemStore) SearchOrderByStatus(ctx context.Context, status string) ([]models.Order, error) {
+func (s *MemStore) SearchOrderByStatus(ctx context.Context, status string, page models.PageRequest) (models.Page[models.Order], error) {
 	select {
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		return models.Page[models.Order]{}, ctx.Err()
 	default:
 	}
 
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	orders := make([]models.Order, 0)
-	for _, order := range s.Orders {
-		if order.Status == status {
-			orders = append(orders, order)
-		}
+	orders := make([]models.Order, 0, len(s.idx.ordersByStatus[status]))
+	for id := range s.idx.ordersByStatus[status] {
+		orders = append(orders, s.Orders[id])
 	}
-	return orders, nil
+	return pageOf(orderOrder, orders, page)
 }
 
-func (s *MemStore) ListOrders(ctx context.Context) ([]models.Order, error) {
+func (s *MemStore) ListOrders(ctx context.Context, page models.PageRequest) (models.Page[models.Order], error) {
 	select {
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		return models.Page[models.Order]{}, ctx.Err()
 	default:
 	}
 
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	orders := make([]models.Order, 0)
+	orders := make([]models.Order, 0, len(s.Orders))
 	for _, order := range s.Orders {
 		orders = append(orders, order)
 	}
-	return orders, nil
+	return pageOf(orderOrder, orders, page)
 }
 
-func (s *MemStore) GetOrdersInTimeRange(ctx context.Context, start, end time.Time) ([]models.Order, error) {
+func (s *MemStore) GetOrdersInTimeRange(ctx context.Context, start, end time.Time, page models.PageRequest) (models.Page[models.Order], error) {
 	select {
 	case <-ctx.Done():
-		return nil, ctx.Err()
+		return models.Page[models.Order]{}, ctx.Err()
 	default:
 	}
 
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	orders := make([]models.Order, 0)
-	startUnix := start.Unix()
-	endUnix := end.Unix()
-
-	for _, order := range s.Orders {
-		orderUnix := order.CreatedAt.Unix()
-		if orderUnix >= startUnix && orderUnix <= endUnix {
-			orders = append(orders, order)
-		}
+	ids := s.idx.ordersBetween(start, end)
+	orders := make([]models.Order, 0, len(ids))
+	for _, id := range ids {
+		orders = append(orders, s.Orders[id])
 	}
-
-	return orders, nil
+	return pageOf(orderOrder, orders, page)
 }
 
@@ -0,0 +1,122 @@
+package store
+
+import (
+	"Book-Store/internal/models"
+	"context"
+	"time"
+)
+
+func (s *MemStore) CreateRefreshToken(ctx context.Context, token models.RefreshToken) (models.RefreshToken, error) {
+	select {
+	case <-ctx.Done():
+		return models.RefreshToken{}, ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	changes := s.pruneExpiredRefreshTokens(now)
+
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = now
+	}
+	changes = append(changes, setEntry(s, "refresh_tokens", s.RefreshTokens, token.TokenHash, token))
+
+	if err := s.commit(changes...); err != nil {
+		return models.RefreshToken{}, err
+	}
+
+	return token, nil
+}
+
+// RotateRefreshToken swaps the token identified by oldHash for next, keeping it
+// in the same family. Presenting a token that was already rotated means it
+// leaked, so the whole family is revoked.
+func (s *MemStore) RotateRefreshToken(ctx context.Context, oldHash string, next models.RefreshToken) (models.RefreshToken, error) {
+	select {
+	case <-ctx.Done():
+		return models.RefreshToken{}, ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, exists := s.RefreshTokens[oldHash]
+	if !exists {
+		return models.RefreshToken{}, ErrRefreshTokenNotFound
+	}
+
+	now := time.Now()
+	if old.RevokedAt != nil {
+		if old.ReplacedBy == "" {
+			return models.RefreshToken{}, ErrRefreshTokenRevoked
+		}
+		if err := s.commit(s.revokeRefreshTokenFamily(old.FamilyID, now)...); err != nil {
+			return models.RefreshToken{}, err
+		}
+		return models.RefreshToken{}, ErrRefreshTokenReused
+	}
+	if now.After(old.ExpiresAt) {
+		return models.RefreshToken{}, ErrRefreshTokenExpired
+	}
+
+	old.RevokedAt = &now
+	old.ReplacedBy = next.TokenHash
+
+	next.CustomerID = old.CustomerID
+	next.FamilyID = old.FamilyID
+	next.CreatedAt = now
+
+	if err := s.commit(
+		setEntry(s, "refresh_tokens", s.RefreshTokens, oldHash, old),
+		setEntry(s, "refresh_tokens", s.RefreshTokens, next.TokenHash, next),
+	); err != nil {
+		return models.RefreshToken{}, err
+	}
+
+	return next, nil
+}
+
+func (s *MemStore) RevokeRefreshToken(ctx context.Context, tokenHash string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, exists := s.RefreshTokens[tokenHash]
+	if !exists {
+		return ErrRefreshTokenNotFound
+	}
+
+	return s.commit(s.revokeRefreshTokenFamily(token.FamilyID, time.Now())...)
+}
+
+func (s *MemStore) revokeRefreshTokenFamily(familyID string, now time.Time) []journalChange {
+	var changes []journalChange
+	for hash, token := range s.RefreshTokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+			changes = append(changes, setEntry(s, "refresh_tokens", s.RefreshTokens, hash, token))
+		}
+	}
+	return changes
+}
+
+// pruneExpiredRefreshTokens drops tokens past their expiry. Rotated tokens are
+// kept until then so reuse can still be detected.
+func (s *MemStore) pruneExpiredRefreshTokens(now time.Time) []journalChange {
+	var changes []journalChange
+	for hash, token := range s.RefreshTokens {
+		if now.After(token.ExpiresAt) {
+			changes = append(changes, deleteEntry(s, "refresh_tokens", s.RefreshTokens, hash))
+		}
+	}
+	return changes
+}
@@ -0,0 +1,177 @@
+package store
+
+import (
+	"Book-Store/internal/models"
+	"context"
+	"errors"
+	"time"
+)
+
+func (s *MemStore) CreateReview(ctx context.Context, review models.Review) (models.Review, error) {
+	select {
+	case <-ctx.Done():
+		return models.Review{}, ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.Books[review.BookID]; !exists {
+		return models.Review{}, errors.New("book not found")
+	}
+
+	customer, exists := s.Customers[review.CustomerID]
+	if !exists || !s.hasPurchased(review.CustomerID, review.BookID) {
+		return models.Review{}, ErrReviewNotAllowed
+	}
+
+	for id := range s.idx.reviewsByBook[review.BookID] {
+		if s.Reviews[id].CustomerID == review.CustomerID {
+			return models.Review{}, ErrDuplicateReview
+		}
+	}
+
+	id, sequence := nextID(s, "reviews", s.Reviews)
+	review.ID = id
+	review.PublicID = s.publicID()
+	review.CustomerName = customer.Name
+	review.CreatedAt = time.Now().UTC()
+	review.UpdatedAt = review.CreatedAt
+
+	changes := []journalChange{sequence, setEntry(s, "reviews", s.Reviews, id, review)}
+	changes = append(changes, s.rateBook(review.BookID))
+	if err := s.commit(changes...); err != nil {
+		return models.Review{}, err
+	}
+
+	return review, nil
+}
+
+func (s *MemStore) GetReview(ctx context.Context, id int) (models.Review, error) {
+	select {
+	case <-ctx.Done():
+		return models.Review{}, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	review, exists := s.Reviews[id]
+	if !exists {
+		return models.Review{}, errors.New("Review not found")
+	}
+	return review, nil
+}
+
+func (s *MemStore) ListBookReviews(ctx context.Context, bookID int, page models.PageRequest) (models.Page[models.Review], error) {
+	select {
+	case <-ctx.Done():
+		return models.Page[models.Review]{}, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reviews := make([]models.Review, 0, len(s.idx.reviewsByBook[bookID]))
+	for id := range s.idx.reviewsByBook[bookID] {
+		reviews = append(reviews, s.Reviews[id])
+	}
+	return pageOf(reviewOrder, reviews, page)
+}
+
+// UpdateReview changes the rating and text of a review; who wrote it about
+// which book stays fixed.
+func (s *MemStore) UpdateReview(ctx context.Context, id int, review models.Review) (models.Review, error) {
+	select {
+	case <-ctx.Done():
+		return models.Review{}, ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.Reviews[id]
+	if !exists {
+		return models.Review{}, errors.New("Review not found")
+	}
+
+	existing.Rating = review.Rating
+	existing.Title = review.Title
+	existing.Body = review.Body
+	existing.UpdatedAt = time.Now().UTC()
+
+	changes := []journalChange{setEntry(s, "reviews", s.Reviews, id, existing)}
+	changes = append(changes, s.rateBook(existing.BookID))
+	if err := s.commit(changes...); err != nil {
+		return models.Review{}, err
+	}
+
+	return existing, nil
+}
+
+func (s *MemStore) DeleteReview(ctx context.Context, id int) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	review, exists := s.Reviews[id]
+	if !exists {
+		return errors.New("Review not found")
+	}
+
+	changes := []journalChange{deleteEntry(s, "reviews", s.Reviews, id)}
+	changes = append(changes, s.rateBook(review.BookID))
+	return s.commit(changes...)
+}
+
+// hasPurchased reports whether the customer has a completed order
+// containing the book. Callers hold s.mu.
+func (s *MemStore) hasPurchased(customerID, bookID int) bool {
+	for orderID := range s.idx.ordersByCustomer[customerID] {
+		order := s.Orders[orderID]
+		if order.Status != "completed" {
+			continue
+		}
+		for _, item := range order.Items {
+			if item.Book.ID == bookID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rateBook recomputes the rating of a book from its reviews. Callers hold
+// s.mu.
+func (s *MemStore) rateBook(bookID int) journalChange {
+	book := s.Books[bookID]
+	book.Rating = models.RatingSummary{}
+	for id := range s.idx.reviewsByBook[bookID] {
+		book.Rating.Add(s.Reviews[id].Rating)
+	}
+	return setEntry(s, "books", s.Books, bookID, book)
+}
+
+// deleteBookReviews removes the reviews of a book that is being deleted.
+// Callers hold s.mu.
+func (s *MemStore) deleteBookReviews(bookID int) []journalChange {
+	ids := make([]int, 0, len(s.idx.reviewsByBook[bookID]))
+	for id := range s.idx.reviewsByBook[bookID] {
+		ids = append(ids, id)
+	}
+
+	changes := make([]journalChange, 0, len(ids))
+	for _, id := range ids {
+		changes = append(changes, deleteEntry(s, "reviews", s.Reviews, id))
+	}
+	return changes
+}
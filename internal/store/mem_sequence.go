@@ -0,0 +1,173 @@
+package store
+
+import (
+	"Book-Store/internal/models"
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// nextID hands out the next ID for entity. Sequences are persisted, so the ID
+// of a deleted record is never issued again; a sequence missing from an older
+// snapshot starts after the highest existing key, and IDs start at 1 like
+// the SQLite store's. Callers hold s.mu and must commit the returned change
+// with the record it numbers.
+func nextID[V any](s *MemStore, entity string, existing map[int]V) (int, journalChange) {
+	id := lastID(s, entity, existing) + 1
+	return id, setEntry(s, "sequences", s.Sequences, entity, id)
+}
+
+// lastID is the last ID handed out for entity. Callers hold s.mu.
+func lastID[V any](s *MemStore, entity string, existing map[int]V) int {
+	if last, ok := s.Sequences[entity]; ok {
+		return last
+	}
+	last := 0
+	for id := range existing {
+		last = max(last, id)
+	}
+	return last
+}
+
+// lastIDs is the last ID handed out for every numbered entity. Callers hold
+// s.mu.
+func (s *MemStore) lastIDs() map[string]int {
+	return map[string]int{
+		"books":     lastID(s, "books", s.Books),
+		"authors":   lastID(s, "authors", s.Authors),
+		"series":    lastID(s, "series", s.Series),
+		"reviews":   lastID(s, "reviews", s.Reviews),
+		"customers": lastID(s, "customers", s.Customers),
+		"orders":    lastID(s, "orders", s.Orders),
+	}
+}
+
+func newPublicID() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// publicID returns a fresh public ID when public IDs are enabled.
+func (s *MemStore) publicID() string {
+	if !s.publicIDs {
+		return ""
+	}
+	return newPublicID()
+}
+
+// EnablePublicIDs makes the store give every book, author, series, review,
+// customer and order an opaque public ID, backfilling records created before
+// it was turned on.
+func (s *MemStore) EnablePublicIDs(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.publicIDs = true
+	return s.commit(s.backfillPublicIDs()...)
+}
+
+// backfillPublicIDs gives every record without a public ID one and copies
+// the new IDs into the records embedded in books and orders. Callers hold
+// s.mu and commit the returned changes.
+func (s *MemStore) backfillPublicIDs() []journalChange {
+	var changes []journalChange
+	for id, book := range s.Books {
+		if book.PublicID == "" {
+			book.PublicID = newPublicID()
+			changes = append(changes, setEntry(s, "books", s.Books, id, book))
+		}
+	}
+	for id, author := range s.Authors {
+		if author.PublicID == "" {
+			author.PublicID = newPublicID()
+			changes = append(changes, setEntry(s, "authors", s.Authors, id, author))
+		}
+	}
+	for id, series := range s.Series {
+		if series.PublicID == "" {
+			series.PublicID = newPublicID()
+			changes = append(changes, setEntry(s, "series", s.Series, id, series))
+		}
+	}
+	for id, review := range s.Reviews {
+		if review.PublicID == "" {
+			review.PublicID = newPublicID()
+			changes = append(changes, setEntry(s, "reviews", s.Reviews, id, review))
+		}
+	}
+	for id, customer := range s.Customers {
+		if customer.PublicID == "" {
+			customer.PublicID = newPublicID()
+			changes = append(changes, setEntry(s, "customers", s.Customers, id, customer))
+		}
+	}
+	for id, order := range s.Orders {
+		if order.PublicID == "" {
+			order.PublicID = newPublicID()
+			changes = append(changes, setEntry(s, "orders", s.Orders, id, order))
+		}
+	}
+
+	// Books and orders carry copies of the records they refer to, which
+	// need the new public IDs as well.
+	for id, book := range s.Books {
+		if book, changed := linkBookPublicIDs(book, s.lookupPublicID); changed {
+			changes = append(changes, setEntry(s, "books", s.Books, id, book))
+		}
+	}
+	for id, order := range s.Orders {
+		if order, changed := s.linkOrderPublicIDs(order); changed {
+			changes = append(changes, setEntry(s, "orders", s.Orders, id, order))
+		}
+	}
+	return changes
+}
+
+// lookupPublicID is the publicIDLookup of the store. Callers hold s.mu.
+func (s *MemStore) lookupPublicID(entity string, id int) string {
+	switch entity {
+	case "books":
+		return s.Books[id].PublicID
+	case "authors":
+		return s.Authors[id].PublicID
+	case "series":
+		return s.Series[id].PublicID
+	case "customers":
+		return s.Customers[id].PublicID
+	}
+	return ""
+}
+
+// linkOrderPublicIDs fills in the public IDs missing from the customer and
+// the books an order holds copies of. Callers hold s.mu.
+func (s *MemStore) linkOrderPublicIDs(order models.Order) (models.Order, bool) {
+	changed := false
+	if order.Customer.PublicID == "" {
+		if order.Customer.PublicID = s.lookupPublicID("customers", order.Customer.ID); order.Customer.PublicID != "" {
+			changed = true
+		}
+	}
+
+	order.Items = slices.Clone(order.Items)
+	for i, item := range order.Items {
+		if book, linked := linkBookPublicIDs(item.Book, s.lookupPublicID); linked {
+			order.Items[i].Book = book
+			changed = true
+		}
+	}
+	return order, changed
+}
+
+func (s *MemStore) ResolvePublicID(ctx context.Context, entity, publicID string) (int, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, ok := s.idx.publicIDs[entity][publicID]
+	return id, ok
+}
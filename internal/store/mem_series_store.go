@@ -0,0 +1,153 @@
+package store
+
+import (
+	"Book-Store/internal/models"
+	"context"
+	"errors"
+)
+
+func (s *MemStore) CreateSeries(ctx context.Context, series models.Series) (models.Series, error) {
+	select {
+	case <-ctx.Done():
+		return models.Series{}, ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, sequence := nextID(s, "series", s.Series)
+	series.ID = id
+	series.PublicID = s.publicID()
+	if err := s.commit(sequence, setEntry(s, "series", s.Series, series.ID, series)); err != nil {
+		return models.Series{}, err
+	}
+
+	return series, nil
+}
+
+func (s *MemStore) GetSeries(ctx context.Context, id int) (models.Series, error) {
+	select {
+	case <-ctx.Done():
+		return models.Series{}, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	series, exists := s.Series[id]
+	if !exists {
+		return models.Series{}, errors.New("Series not found")
+	}
+	return series, nil
+}
+
+func (s *MemStore) ListSeries(ctx context.Context, page models.PageRequest) (models.Page[models.Series], error) {
+	select {
+	case <-ctx.Done():
+		return models.Page[models.Series]{}, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	series := make([]models.Series, 0, len(s.Series))
+	for _, ser := range s.Series {
+		series = append(series, ser)
+	}
+	return pageOf(seriesOrder, series, page)
+}
+
+func (s *MemStore) UpdateSeries(ctx context.Context, id int, series models.Series) (models.Series, error) {
+	select {
+	case <-ctx.Done():
+		return models.Series{}, ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.Series[id]
+	if !exists {
+		return models.Series{}, errors.New("Series not found")
+	}
+
+	series.ID = id
+	series.PublicID = existing.PublicID
+	changes := []journalChange{setEntry(s, "series", s.Series, id, series)}
+
+	// Books carry the series name along with their position.
+	for _, bookID := range s.seriesBookIDs(id) {
+		book := s.Books[bookID]
+		entry := *book.Series
+		entry.Name = series.Name
+		book.Series = &entry
+		changes = append(changes, setEntry(s, "books", s.Books, bookID, book))
+	}
+
+	if err := s.commit(changes...); err != nil {
+		return models.Series{}, err
+	}
+
+	return series, nil
+}
+
+func (s *MemStore) DeleteSeries(ctx context.Context, id int) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.Series[id]; !exists {
+		return errors.New("Series not found")
+	}
+
+	var changes []journalChange
+	for _, bookID := range s.seriesBookIDs(id) {
+		book := s.Books[bookID]
+		book.Series = nil
+		changes = append(changes, setEntry(s, "books", s.Books, bookID, book))
+	}
+	changes = append(changes, deleteEntry(s, "series", s.Series, id))
+
+	return s.commit(changes...)
+}
+
+func (s *MemStore) ListSeriesBooks(ctx context.Context, id int) ([]models.Book, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, exists := s.Series[id]; !exists {
+		return nil, errors.New("Series not found")
+	}
+
+	books := make([]models.Book, 0, len(s.idx.booksBySeries[id]))
+	for bookID := range s.idx.booksBySeries[id] {
+		books = append(books, s.Books[bookID])
+	}
+	readingOrder.Sort(books)
+	return books, nil
+}
+
+// seriesBookIDs lists the books of a series, copied out of the index so the
+// books can be rewritten while going through them. Callers hold s.mu.
+func (s *MemStore) seriesBookIDs(seriesID int) []int {
+	ids := make([]int, 0, len(s.idx.booksBySeries[seriesID]))
+	for id := range s.idx.booksBySeries[seriesID] {
+		ids = append(ids, id)
+	}
+	return ids
+}
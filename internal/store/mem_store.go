@@ -8,55 +8,109 @@ import (
 )
 
 type MemStore struct {
-	mu        sync.RWMutex
-	dbPath    string
+	mu     sync.RWMutex
+	dbPath string
+
+	journal        *os.File
+	journalRecords int
+
+	backupDir  string
+	backupKeep int
+
+	// Set on the view handed to WithTx callbacks.
+	inTx      bool
+	undo      []func()
+	txChanges []journalChange
+
 	Books     map[int]models.Book     `json:"books"`
 	Authors   map[int]models.Author   `json:"authors"`
+	Series    map[int]models.Series   `json:"series"`
+	Reviews   map[int]models.Review   `json:"reviews"`
 	Customers map[int]models.Customer `json:"customers"`
 	Orders    map[int]models.Order    `json:"orders"`
+
+	// Carts are keyed by models.CustomerCartKey or models.SessionCartKey.
+	Carts         map[string]models.Cart         `json:"carts"`
+	RefreshTokens map[string]models.RefreshToken `json:"refresh_tokens"`
+
+	// Sequences holds the last ID issued per entity.
+	Sequences map[string]int `json:"sequences"`
+
+	idx       *memIndexes
+	publicIDs bool
 }
 
 func NewMemStore() *MemStore {
 	return &MemStore{
 		Books:     make(map[int]models.Book),
 		Authors:   make(map[int]models.Author),
+		Series:    make(map[int]models.Series),
+		Reviews:   make(map[int]models.Review),
 		Customers: make(map[int]models.Customer),
 		Orders:    make(map[int]models.Order),
+
+		Carts:         make(map[string]models.Cart),
+		RefreshTokens: make(map[string]models.RefreshToken),
+		Sequences:     make(map[string]int),
+
+		idx: newMemIndexes(),
 	}
 }
 
+// SaveToFile writes a full snapshot. The previous snapshot is backed up first
+// and the new one replaces it atomically, so a crash mid-write leaves the old
+// file intact.
 func (s *MemStore) SaveToFile() error {
-	path := s.dbPath
-
-	if path == "" {
-		path = getDBPath()
-
-	}
+	path := s.snapshotPath()
 
 	data, err := json.MarshalIndent(s, "", "    ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0644)
+
+	if err := s.backupSnapshot(path); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, data, 0644)
+}
+
+func (s *MemStore) snapshotPath() string {
+	if s.dbPath == "" {
+		return getDBPath()
+	}
+	return s.dbPath
 }
 
+// LoadFromFile loads the snapshot at path, replays the journal written since
+// it and keeps the journal open so later mutations are appended to it.
 func (s *MemStore) LoadFromFile(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dbPath = path
+
 	data, err := os.ReadFile(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			s.mu.Lock()
-			s.dbPath = path
-			s.mu.Unlock()
-			return nil
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, s); err != nil {
+			return err
 		}
+	}
+
+	if err := s.openJournal(journalPath(path)); err != nil {
 		return err
 	}
 
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.upgradeBooks()
+	s.rebuildIndexes()
 
-	s.dbPath = path
-	return json.Unmarshal(data, s)
+	if s.journalRecords > 0 {
+		return s.compact()
+	}
+	return nil
 }
 
 func getDBPath() string {
@@ -0,0 +1,130 @@
+package store
+
+import (
+	"context"
+	"fmt"
+)
+
+// WithTx runs fn against a view of the store that shares its data but holds
+// the write lock for the whole call. Changes become visible to fn
+// immediately; if fn returns an error or panics they are undone, otherwise
+// they are written to the journal as one record, and undone if that write
+// fails. Called on that view, WithTx nests: a failing fn undoes only its own
+// changes.
+func (s *MemStore) WithTx(ctx context.Context, fn func(tx Stores) error) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	// The view of an enclosing transaction already holds the lock.
+	if s.inTx {
+		return s.savepoint(fn)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx := &MemStore{
+		Books:         s.Books,
+		Authors:       s.Authors,
+		Series:        s.Series,
+		Reviews:       s.Reviews,
+		Customers:     s.Customers,
+		Orders:        s.Orders,
+		Carts:         s.Carts,
+		RefreshTokens: s.RefreshTokens,
+		Sequences:     s.Sequences,
+		idx:           s.idx,
+		publicIDs:     s.publicIDs,
+		inTx:          true,
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.rollback()
+			panic(r)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		tx.rollback()
+		return err
+	}
+
+	if err := s.commit(tx.txChanges...); err != nil {
+		tx.rollback()
+		return fmt.Errorf("transaction not persisted: %w", err)
+	}
+	return nil
+}
+
+// savepoint runs fn inside the transaction of the view s, undoing only the
+// changes fn made if it fails.
+func (s *MemStore) savepoint(fn func(tx Stores) error) error {
+	undo, changes := len(s.undo), len(s.txChanges)
+
+	defer func() {
+		if r := recover(); r != nil {
+			s.rollbackTo(undo, changes)
+			panic(r)
+		}
+	}()
+
+	if err := fn(s); err != nil {
+		s.rollbackTo(undo, changes)
+		return err
+	}
+	return nil
+}
+
+func (s *MemStore) rollback() {
+	s.rollbackTo(0, 0)
+}
+
+// rollbackTo undoes the transaction back to where it held the given number of
+// undo steps and journal changes.
+func (s *MemStore) rollbackTo(undo, changes int) {
+	for i := len(s.undo) - 1; i >= undo; i-- {
+		s.undo[i]()
+	}
+	s.undo = s.undo[:undo]
+	s.txChanges = s.txChanges[:changes]
+}
+
+// setEntry stores value under key in m and returns the journal change for it.
+// The previous entry is remembered so that a failing transaction or commit
+// can put it back. Callers hold s.mu.
+func setEntry[K comparable, V any](s *MemStore, entity string, m map[K]V, key K, value V) journalChange {
+	rememberEntry(s, m, key)
+	replaceEntry(s, m, key, value, true)
+	return putChange(entity, key, value)
+}
+
+func deleteEntry[K comparable, V any](s *MemStore, entity string, m map[K]V, key K) journalChange {
+	rememberEntry(s, m, key)
+	var zero V
+	replaceEntry(s, m, key, zero, false)
+	return deleteChange(entity, key)
+}
+
+// replaceEntry swaps the entry under key, keeping the indexes in step.
+func replaceEntry[K comparable, V any](s *MemStore, m map[K]V, key K, value V, exists bool) {
+	if old, existed := m[key]; existed {
+		s.idx.remove(old)
+	}
+	if exists {
+		m[key] = value
+		s.idx.add(value)
+	} else {
+		delete(m, key)
+	}
+}
+
+func rememberEntry[K comparable, V any](s *MemStore, m map[K]V, key K) {
+	old, existed := m[key]
+	s.undo = append(s.undo, func() {
+		replaceEntry(s, m, key, old, existed)
+	})
+}
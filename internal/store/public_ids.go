@@ -0,0 +1,39 @@
+package store
+
+import (
+	"Book-Store/internal/models"
+	"slices"
+)
+
+// publicIDLookup returns the public ID of the record of entity with the given
+// ID, or "" when it has none or no longer exists.
+type publicIDLookup func(entity string, id int) string
+
+// linkBookPublicIDs fills in the public IDs missing from a book and from the
+// copies of its authors and series it carries, so a book saved before public
+// IDs were enabled refers to them the way a new one does. It reports whether
+// anything changed.
+func linkBookPublicIDs(book models.Book, lookup publicIDLookup) (models.Book, bool) {
+	changed := false
+	link := func(publicID *string, entity string, id int) {
+		if *publicID != "" {
+			return
+		}
+		if *publicID = lookup(entity, id); *publicID != "" {
+			changed = true
+		}
+	}
+
+	link(&book.PublicID, "books", book.ID)
+	link(&book.Author.PublicID, "authors", book.Author.ID)
+	book.Contributors = slices.Clone(book.Contributors)
+	for i := range book.Contributors {
+		link(&book.Contributors[i].Author.PublicID, "authors", book.Contributors[i].Author.ID)
+	}
+	if book.Series != nil {
+		series := *book.Series
+		link(&series.PublicID, "series", series.ID)
+		book.Series = &series
+	}
+	return book, changed
+}
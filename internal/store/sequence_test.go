@@ -0,0 +1,161 @@
+package store_test
+
+import (
+	"Book-Store/internal/models"
+	"Book-Store/internal/store"
+	"context"
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDeletedIDsAreNotReissued(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s store.Store) {
+		ctx := context.Background()
+
+		var ids []int
+		for _, name := range []string{"Le Guin", "Herbert", "Banks"} {
+			a, err := s.CreateAuthor(ctx, models.Author{FirstName: "A", LastName: name})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, a.ID)
+		}
+		if err := s.DeleteAuthor(ctx, ids[2], models.AuthorDeletion{}); err != nil {
+			t.Fatal(err)
+		}
+
+		next, err := s.CreateAuthor(ctx, models.Author{FirstName: "A", LastName: "Butler"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Both backends start at 1 and skip the deleted ID.
+		want := []int{1, 2, 3, 4}
+		if got := append(ids, next.ID); !slices.Equal(got, want) {
+			t.Errorf("IDs = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestEnablePublicIDsBackfillsCopies(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s store.Store) {
+		ctx := context.Background()
+
+		author := mustAuthor(t, s, "Octavia E.", "Butler")
+		series, err := s.CreateSeries(ctx, models.Series{Name: "Xenogenesis"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		book := mustBook(t, s, models.Book{
+			Title: "Dawn", Author: author, Stock: 2,
+			Series: &models.SeriesEntry{ID: series.ID, Position: 1},
+		})
+		customer := mustCustomer(t, s, "lilith@example.com")
+		order, err := s.CreateOrder(ctx, models.Order{
+			Customer: models.Customer{ID: customer.ID},
+			Items:    []models.OrderItem{{Book: models.Book{ID: book.ID}, Quantity: 1}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := s.EnablePublicIDs(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if author, err = s.GetAuthor(ctx, author.ID); err != nil {
+			t.Fatal(err)
+		}
+		if customer, err = s.GetCustomer(ctx, customer.ID); err != nil {
+			t.Fatal(err)
+		}
+		if series, err = s.GetSeries(ctx, series.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := s.GetBook(ctx, book.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.PublicID == "" || got.Author.PublicID != author.PublicID ||
+			got.Contributors[0].Author.PublicID != author.PublicID || got.Series.PublicID != series.PublicID {
+			t.Errorf("book = %q by %q (credited %q) in %q, want %q in %q",
+				got.PublicID, got.Author.PublicID, got.Contributors[0].Author.PublicID, got.Series.PublicID,
+				author.PublicID, series.PublicID)
+		}
+
+		order, err = s.GetOrder(ctx, order.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if order.Customer.PublicID != customer.PublicID {
+			t.Errorf("order customer = %q, want %q", order.Customer.PublicID, customer.PublicID)
+		}
+		sold := order.Items[0].Book
+		if sold.PublicID != got.PublicID || sold.Author.PublicID != author.PublicID ||
+			sold.Contributors[0].Author.PublicID != author.PublicID {
+			t.Errorf("sold book = %q by %q, want %q by %q", sold.PublicID, sold.Author.PublicID, got.PublicID, author.PublicID)
+		}
+	})
+}
+
+func TestSQLiteMigratesUnversionedDatabase(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "bookstore.db")
+
+	// A database written before migrations existed: the first schema and
+	// user_version left at 0.
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(store.SQLiteInitialSchema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO authors (id, first_name, last_name) VALUES (5, 'Ursula', 'Le Guin')`); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	s, err := store.NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore() error = %v", err)
+	}
+	if err := s.EnablePublicIDs(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.GetAuthor(ctx, 5)
+	if err != nil || got.LastName != "Le Guin" {
+		t.Errorf("GetAuthor(5) after migrating = %+v, %v", got, err)
+	}
+	if got.PublicID == "" {
+		t.Error("existing author was not given a public ID")
+	}
+	// The sequence added by the migration starts after the existing rows.
+	next, err := s.CreateAuthor(ctx, models.Author{FirstName: "Frank", LastName: "Herbert"})
+	if err != nil || next.ID != 6 {
+		t.Errorf("CreateAuthor() after migrating = %+v, %v, want ID 6", next, err)
+	}
+	s.Close()
+
+	// Reopening runs nothing twice.
+	db, err = sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version != len(store.SQLiteMigrations) {
+		t.Errorf("user_version = %d, want %d", version, len(store.SQLiteMigrations))
+	}
+	s, err = store.NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("reopening a migrated database: %v", err)
+	}
+	s.Close()
+}
@@ -0,0 +1,215 @@
+package store
+
+import (
+	"Book-Store/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+func (s *SQLiteStore) CreateAuthor(ctx context.Context, author models.Author) (models.Author, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return models.Author{}, err
+	}
+	defer tx.Rollback()
+
+	author.ID, err = nextSQLiteID(ctx, tx, "authors")
+	if err != nil {
+		return models.Author{}, err
+	}
+
+	publicID := s.publicID()
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO authors (id, public_id, first_name, last_name, bio) VALUES (?, ?, ?, ?, ?)`,
+		author.ID, publicID, author.FirstName, author.LastName, author.Bio); err != nil {
+		return models.Author{}, err
+	}
+
+	if err := setSuggestion(ctx, tx, authorSuggestion(author)); err != nil {
+		return models.Author{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Author{}, err
+	}
+
+	author.PublicID, _ = publicID.(string)
+	return author, nil
+}
+
+func (s *SQLiteStore) GetAuthor(ctx context.Context, id int) (models.Author, error) {
+	return getAuthor(ctx, s.q, id)
+}
+
+func getAuthor(ctx context.Context, q sqlQuerier, id int) (models.Author, error) {
+	var author models.Author
+	err := q.QueryRowContext(ctx,
+		`SELECT id, COALESCE(public_id, ''), first_name, last_name, bio FROM authors WHERE id = ?`, id).
+		Scan(&author.ID, &author.PublicID, &author.FirstName, &author.LastName, &author.Bio)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Author{}, errors.New("Author not found")
+	}
+	if err != nil {
+		return models.Author{}, err
+	}
+	return author, nil
+}
+
+func (s *SQLiteStore) ListAuthors(ctx context.Context, page models.PageRequest) (models.Page[models.Author], error) {
+	total, err := s.countRows(ctx, "authors", nil)
+	if err != nil {
+		return models.Page[models.Author]{}, err
+	}
+
+	p, err := pageQuery(authorOrder, page, nil, nil)
+	if err != nil {
+		return models.Page[models.Author]{}, err
+	}
+
+	rows, err := s.q.QueryContext(ctx,
+		`SELECT id, COALESCE(public_id, ''), first_name, last_name, bio FROM authors`+p.where+` ORDER BY id`+p.limit,
+		p.args...)
+	if err != nil {
+		return models.Page[models.Author]{}, err
+	}
+	defer rows.Close()
+
+	authors := make([]models.Author, 0)
+	for rows.Next() {
+		var a models.Author
+		if err := rows.Scan(&a.ID, &a.PublicID, &a.FirstName, &a.LastName, &a.Bio); err != nil {
+			return models.Page[models.Author]{}, err
+		}
+		authors = append(authors, a)
+	}
+	if err := rows.Err(); err != nil {
+		return models.Page[models.Author]{}, err
+	}
+	return authorOrder.PageFrom(authors, total, page.Limit), nil
+}
+
+func (s *SQLiteStore) UpdateAuthor(ctx context.Context, id int, author models.Author) (models.Author, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return models.Author{}, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE authors SET first_name = ?, last_name = ?, bio = ? WHERE id = ?`,
+		author.FirstName, author.LastName, author.Bio, id)
+	if err != nil {
+		return models.Author{}, err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.Author{}, errors.New("Author not found")
+	}
+
+	// Books read author details through a join, so their search text
+	// changes with the author.
+	if err := indexAuthorBooks(ctx, tx, id); err != nil {
+		return models.Author{}, err
+	}
+
+	author.ID = id
+	if err := setSuggestion(ctx, tx, authorSuggestion(author)); err != nil {
+		return models.Author{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Author{}, err
+	}
+
+	return s.GetAuthor(ctx, id)
+}
+
+// DeleteAuthor rewrites the credits of the author's books in Go, the same way
+// MemStore does, and saves each book back.
+func (s *SQLiteStore) DeleteAuthor(ctx context.Context, id int, deletion models.AuthorDeletion) error {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	books, err := loadBooks(ctx, tx, sqliteBookSelect+
+		` WHERE b.id IN (SELECT book_id FROM book_contributors WHERE author_id = ?)`, id)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(books) == 0:
+	case deletion.ReassignTo != nil:
+		target, err := getAuthor(ctx, tx, *deletion.ReassignTo)
+		if err != nil || target.ID == id {
+			return errors.New("reassignment author not found")
+		}
+		for _, book := range books {
+			if err := saveCredits(ctx, tx, recredit(book, id, target)); err != nil {
+				return err
+			}
+		}
+	case deletion.Cascade:
+		for _, book := range books {
+			if book, kept := uncredit(book, id); kept {
+				err = saveCredits(ctx, tx, book)
+			} else {
+				_, err = removeBook(ctx, tx, book.ID)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return ErrAuthorHasBooks
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM authors WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("Author not found")
+	}
+
+	if err := deleteSuggestion(ctx, tx, models.SuggestionAuthor, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) AuthorExists(id int) bool {
+	var exists bool
+	s.q.QueryRowContext(context.Background(), `SELECT EXISTS (SELECT 1 FROM authors WHERE id = ?)`, id).Scan(&exists)
+	return exists
+}
+
+func (s *SQLiteStore) AuthorsCount() int {
+	var count int
+	s.q.QueryRowContext(context.Background(), `SELECT COUNT(*) FROM authors`).Scan(&count)
+	return count
+}
+
+func (s *SQLiteStore) BooksPerAuthor() map[int]int {
+	result := make(map[int]int)
+
+	rows, err := s.q.QueryContext(context.Background(),
+		`SELECT author_id, COUNT(DISTINCT book_id) FROM book_contributors GROUP BY author_id`)
+	if err != nil {
+		return result
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var authorID, count int
+		if err := rows.Scan(&authorID, &count); err != nil {
+			return result
+		}
+		result[authorID] = count
+	}
+	return result
+}
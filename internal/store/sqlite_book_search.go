@@ -0,0 +1,270 @@
+package store
+
+import (
+	"Book-Store/internal/models"
+	"Book-Store/internal/search"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// indexBookText refreshes the books_fts row and title suggestion of a book.
+// Terms are analyzed in Go so both backends stem and drop stop words the same
+// way.
+func indexBookText(ctx context.Context, q sqlQuerier, id int) error {
+	if _, err := q.ExecContext(ctx, `DELETE FROM books_fts WHERE rowid = ?`, id); err != nil {
+		return err
+	}
+
+	book, err := loadBook(ctx, q, sqliteBookSelect+` WHERE b.id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	fields := bookSearchFields(book)
+	args := []any{id}
+	for _, field := range fields {
+		args = append(args, strings.Join(search.Analyze(field.Text), " "))
+	}
+	if _, err := q.ExecContext(ctx,
+		`INSERT INTO books_fts (rowid, title, author, bio, genres) VALUES (?, ?, ?, ?, ?)`, args...); err != nil {
+		return err
+	}
+
+	return setSuggestion(ctx, q, titleSuggestion(book))
+}
+
+// unindexBook removes a deleted book from full-text search and autocomplete.
+func unindexBook(ctx context.Context, q sqlQuerier, id int) error {
+	if _, err := q.ExecContext(ctx, `DELETE FROM books_fts WHERE rowid = ?`, id); err != nil {
+		return err
+	}
+	return deleteSuggestion(ctx, q, models.SuggestionTitle, id)
+}
+
+// setSuggestion replaces the autocomplete rows of a title or author name.
+func setSuggestion(ctx context.Context, q sqlQuerier, s search.Suggestion) error {
+	if err := deleteSuggestion(ctx, q, s.Kind, s.ID); err != nil {
+		return err
+	}
+	for _, key := range search.SuggestKeys(s.Text) {
+		if _, err := q.ExecContext(ctx,
+			`INSERT INTO suggestions (kind, ref_id, text, key) VALUES (?, ?, ?, ?)`,
+			s.Kind, s.ID, s.Text, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteSuggestion(ctx context.Context, q sqlQuerier, kind string, id int) error {
+	_, err := q.ExecContext(ctx, `DELETE FROM suggestions WHERE kind = ? AND ref_id = ?`, kind, id)
+	return err
+}
+
+// indexAuthorBooks refreshes the full-text rows of every book an author
+// contributed to.
+func indexAuthorBooks(ctx context.Context, q sqlQuerier, authorID int) error {
+	ids, err := queryIDs(ctx, q, `SELECT DISTINCT book_id FROM book_contributors WHERE author_id = ?`, authorID)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := indexBookText(ctx, q, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncBookSearch indexes books and authors missing from search, such as
+// those written before the search tables existed.
+func (s *SQLiteStore) syncBookSearch(ctx context.Context) error {
+	ids, err := queryIDs(ctx, s.q, `
+		SELECT id FROM books
+		WHERE id NOT IN (SELECT rowid FROM books_fts)
+			OR id NOT IN (SELECT ref_id FROM suggestions WHERE kind = ?)`,
+		models.SuggestionTitle)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := indexBookText(ctx, s.q, id); err != nil {
+			return err
+		}
+	}
+
+	authors, err := s.ListAuthors(ctx, models.PageRequest{})
+	if err != nil {
+		return err
+	}
+	indexed, err := queryIDs(ctx, s.q, `SELECT DISTINCT ref_id FROM suggestions WHERE kind = ?`,
+		models.SuggestionAuthor)
+	if err != nil {
+		return err
+	}
+	for _, author := range authors.Items {
+		if !slices.Contains(indexed, author.ID) {
+			if err := setSuggestion(ctx, s.q, authorSuggestion(author)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// bookScores runs a full-text query and returns the BM25 score of each
+// matching book. Terms missing from the index are swapped for similarly
+// spelled ones, scored as the in-memory index does. A query made only of
+// stop words matches nothing.
+func (s *SQLiteStore) bookScores(ctx context.Context, query string) (map[int]float64, error) {
+	scores := make(map[int]float64)
+
+	byWeight := make(map[float64][]string)
+	seen := make(map[string]bool)
+	for _, term := range search.Analyze(query) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+
+		var known bool
+		if err := s.q.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM books_fts_vocab WHERE term = ?)`, term).Scan(&known); err != nil {
+			return nil, err
+		}
+		if known {
+			byWeight[1] = append(byWeight[1], term)
+			continue
+		}
+
+		maxEdits := search.MaxEdits(term)
+		if maxEdits == 0 {
+			continue
+		}
+		length := len([]rune(term))
+		candidates, err := queryStrings(ctx, s.q,
+			`SELECT term FROM books_fts_vocab WHERE length(term) BETWEEN ? AND ?`,
+			length-maxEdits, length+maxEdits)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range search.Similar(term, candidates) {
+			weight := search.FuzzyWeight(match.Distance)
+			byWeight[weight] = append(byWeight[weight], match.Term)
+		}
+	}
+
+	for weight, terms := range byWeight {
+		if err := s.addBookScores(ctx, scores, terms, weight); err != nil {
+			return nil, err
+		}
+	}
+	return scores, nil
+}
+
+func (s *SQLiteStore) addBookScores(ctx context.Context, scores map[int]float64, terms []string, weight float64) error {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + term + `"`
+	}
+
+	var weights []string
+	for _, field := range bookSearchFields(models.Book{}) {
+		weights = append(weights, fmt.Sprint(field.Weight))
+	}
+
+	// bm25() is lower for better matches, so it is negated to rank like the
+	// in-memory index.
+	rows, err := s.q.QueryContext(ctx,
+		`SELECT rowid, -bm25(books_fts, `+strings.Join(weights, ", ")+`) FROM books_fts WHERE books_fts MATCH ?`,
+		strings.Join(quoted, " OR "))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id    int
+			score float64
+		)
+		if err := rows.Scan(&id, &score); err != nil {
+			return err
+		}
+		scores[id] += weight * score
+	}
+	return rows.Err()
+}
+
+func (s *SQLiteStore) SuggestBooks(ctx context.Context, prefix string, limit int) ([]models.Suggestion, error) {
+	suggestions := make([]models.Suggestion, 0)
+	prefix = search.NormalizePrefix(prefix)
+	if prefix == "" || limit <= 0 {
+		return suggestions, nil
+	}
+
+	// Every key starting with prefix sorts between prefix and prefix followed
+	// by the highest code point, so the key index answers this as a range.
+	rows, err := s.q.QueryContext(ctx, `
+		SELECT kind, ref_id, COALESCE(CASE kind
+			WHEN ? THEN (SELECT public_id FROM books WHERE id = ref_id)
+			ELSE (SELECT public_id FROM authors WHERE id = ref_id)
+		END, ''), text, MIN(key) AS first_key FROM suggestions
+		WHERE key >= ? AND key < ?
+		GROUP BY kind, ref_id
+		ORDER BY first_key, kind, ref_id
+		LIMIT ?`,
+		models.SuggestionTitle, prefix, prefix+string(utf8.MaxRune), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var suggestion models.Suggestion
+		if err := rows.Scan(&suggestion.Type, &suggestion.ID, &suggestion.PublicID, &suggestion.Text, new(string)); err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, rows.Err()
+}
+
+func queryStrings(ctx context.Context, q sqlQuerier, query string, args ...any) ([]string, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+func queryIDs(ctx context.Context, q sqlQuerier, query string, args ...any) ([]int, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,627 @@
+package store
+
+import (
+	"Book-Store/internal/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const sqliteBookSelect = `
+SELECT b.id, COALESCE(b.public_id, ''), b.title, b.author_id, COALESCE(a.public_id, ''),
+	COALESCE(a.first_name, ''), COALESCE(a.last_name, ''), COALESCE(a.bio, ''),
+	b.genres, b.published_at, b.price, b.stock,
+	COALESCE(b.isbn, ''), b.publisher, b.language, b.page_count, b.format, b.edition,
+	b.series_id, COALESCE(s.public_id, ''), COALESCE(s.name, ''), b.series_position,
+	b.cover_format, b.cover_width, b.cover_height, b.cover_updated_at,
+	b.rating_average, b.rating_count, b.rating_histogram
+FROM books b
+LEFT JOIN authors a ON a.id = b.author_id
+LEFT JOIN series s ON s.id = b.series_id`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanBook reads a row produced by sqliteBookSelect. Author details come from
+// the join rather than being copied into the book row, and contributors are
+// left to loadContributors.
+func scanBook(row rowScanner) (models.Book, error) {
+	var (
+		book        models.Book
+		genres      string
+		publishedAt string
+		seriesID    sql.NullInt64
+		series      models.SeriesEntry
+		cover       models.BookCover
+		coverTime   string
+		histogram   string
+	)
+	err := row.Scan(&book.ID, &book.PublicID, &book.Title, &book.Author.ID, &book.Author.PublicID,
+		&book.Author.FirstName, &book.Author.LastName, &book.Author.Bio,
+		&genres, &publishedAt, &book.Price, &book.Stock,
+		&book.ISBN, &book.Publisher, &book.Language, &book.PageCount, &book.Format, &book.Edition,
+		&seriesID, &series.PublicID, &series.Name, &series.Position,
+		&cover.Format, &cover.Width, &cover.Height, &coverTime,
+		&book.Rating.Average, &book.Rating.Count, &histogram)
+	if err != nil {
+		return models.Book{}, err
+	}
+
+	if seriesID.Valid {
+		series.ID = int(seriesID.Int64)
+		book.Series = &series
+	}
+
+	if cover.Format != "" {
+		cover.UpdatedAt = parseTime(coverTime)
+		book.Cover = &cover
+	}
+
+	if err := json.Unmarshal([]byte(genres), &book.Genres); err != nil {
+		return models.Book{}, err
+	}
+	if err := json.Unmarshal([]byte(histogram), &book.Rating.Histogram); err != nil {
+		return models.Book{}, err
+	}
+	book.PublishedAt = parseTime(publishedAt)
+	return book, nil
+}
+
+func (s *SQLiteStore) queryBooks(ctx context.Context, query string, args ...any) ([]models.Book, error) {
+	return loadBooks(ctx, s.q, query, args...)
+}
+
+// loadBooks runs a query built on sqliteBookSelect and returns the books
+// with their contributors.
+func loadBooks(ctx context.Context, q sqlQuerier, query string, args ...any) ([]models.Book, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	books := make([]models.Book, 0)
+	for rows.Next() {
+		book, err := scanBook(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		books = append(books, book)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := loadContributors(ctx, q, books); err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// loadBook is loadBooks for a query matching at most one book. It returns
+// sql.ErrNoRows when there is none.
+func loadBook(ctx context.Context, q sqlQuerier, query string, args ...any) (models.Book, error) {
+	books, err := loadBooks(ctx, q, query, args...)
+	if err != nil {
+		return models.Book{}, err
+	}
+	if len(books) == 0 {
+		return models.Book{}, sql.ErrNoRows
+	}
+	return books[0], nil
+}
+
+// loadContributors fills in the credits of books, in credit order.
+func loadContributors(ctx context.Context, q sqlQuerier, books []models.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+
+	positions := make(map[int]int, len(books))
+	ids := make([]string, 0, len(books))
+	for i, book := range books {
+		positions[book.ID] = i
+		ids = append(ids, strconv.Itoa(book.ID))
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT c.book_id, c.role, c.author_id, COALESCE(a.public_id, ''),
+			COALESCE(a.first_name, ''), COALESCE(a.last_name, ''), COALESCE(a.bio, '')
+		FROM book_contributors c LEFT JOIN authors a ON a.id = c.author_id
+		WHERE c.book_id IN (`+strings.Join(ids, ", ")+`)
+		ORDER BY c.book_id, c.position`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			bookID int
+			c      models.Contributor
+		)
+		if err := rows.Scan(&bookID, &c.Role, &c.Author.ID, &c.Author.PublicID,
+			&c.Author.FirstName, &c.Author.LastName, &c.Author.Bio); err != nil {
+			return err
+		}
+		i := positions[bookID]
+		books[i].Contributors = append(books[i].Contributors, c)
+	}
+	return rows.Err()
+}
+
+// setContributors replaces the credits of a book.
+func setContributors(ctx context.Context, q sqlQuerier, bookID int, contributors []models.Contributor) error {
+	if _, err := q.ExecContext(ctx, `DELETE FROM book_contributors WHERE book_id = ?`, bookID); err != nil {
+		return err
+	}
+	for position, c := range contributors {
+		if _, err := q.ExecContext(ctx,
+			`INSERT INTO book_contributors (book_id, position, author_id, role) VALUES (?, ?, ?, ?)`,
+			bookID, position, c.Author.ID, c.Role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// creditBook checks that every contributor of book is a known author, and
+// that its series exists.
+func (s *SQLiteStore) creditBook(ctx context.Context, book *models.Book) error {
+	if err := book.NormalizeContributors(); err != nil {
+		return err
+	}
+	for _, c := range book.Contributors {
+		if !s.AuthorExists(c.Author.ID) {
+			return errors.New("author not found")
+		}
+	}
+
+	if book.Series != nil {
+		var exists bool
+		if err := s.q.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM series WHERE id = ?)`, book.Series.ID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return errors.New("series not found")
+		}
+	}
+	return nil
+}
+
+// seriesColumns are the series_id and series_position values of a book.
+func seriesColumns(book models.Book) (any, float64) {
+	if book.Series == nil {
+		return nil, 0
+	}
+	return book.Series.ID, book.Series.Position
+}
+
+// isbnValue stores a missing ISBN as NULL so it stays out of the unique
+// index.
+func isbnValue(isbn string) any {
+	if isbn == "" {
+		return nil
+	}
+	return isbn
+}
+
+func (s *SQLiteStore) CreateBook(ctx context.Context, book models.Book) (models.Book, error) {
+	if err := s.creditBook(ctx, &book); err != nil {
+		return models.Book{}, err
+	}
+
+	genres, err := json.Marshal(book.Genres)
+	if err != nil {
+		return models.Book{}, err
+	}
+
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return models.Book{}, err
+	}
+	defer tx.Rollback()
+
+	id, err := nextSQLiteID(ctx, tx, "books")
+	if err != nil {
+		return models.Book{}, err
+	}
+
+	seriesID, seriesPosition := seriesColumns(book)
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO books (id, public_id, title, author_id, genres, published_at, price, stock,
+			isbn, publisher, language, page_count, format, edition, series_id, series_position)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		id, s.publicID(), book.Title, book.Author.ID, string(genres), formatTime(book.PublishedAt),
+		book.Price, book.Stock,
+		isbnValue(book.ISBN), book.Publisher, book.Language, book.PageCount, book.Format, book.Edition,
+		seriesID, seriesPosition)
+	if isUniqueViolation(err) {
+		return models.Book{}, ErrDuplicateISBN
+	}
+	if err != nil {
+		return models.Book{}, err
+	}
+
+	if err := setContributors(ctx, tx, id, book.Contributors); err != nil {
+		return models.Book{}, err
+	}
+
+	if err := indexBookText(ctx, tx, id); err != nil {
+		return models.Book{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Book{}, err
+	}
+
+	return s.GetBook(ctx, id)
+}
+
+func (s *SQLiteStore) GetBook(ctx context.Context, id int) (models.Book, error) {
+	book, err := loadBook(ctx, s.q, sqliteBookSelect+` WHERE b.id = ?`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Book{}, errors.New("book not found")
+	}
+	if err != nil {
+		return models.Book{}, err
+	}
+	return book, nil
+}
+
+func (s *SQLiteStore) GetBookByISBN(ctx context.Context, isbn string) (models.Book, error) {
+	book, err := loadBook(ctx, s.q, sqliteBookSelect+` WHERE b.isbn = ?`, isbn)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Book{}, errors.New("book not found")
+	}
+	if err != nil {
+		return models.Book{}, err
+	}
+	return book, nil
+}
+
+func (s *SQLiteStore) UpdateBook(ctx context.Context, id int, book models.Book) (models.Book, error) {
+	if !s.BookExists(id) {
+		return models.Book{}, errors.New("book not found")
+	}
+	if err := s.creditBook(ctx, &book); err != nil {
+		return models.Book{}, err
+	}
+
+	genres, err := json.Marshal(book.Genres)
+	if err != nil {
+		return models.Book{}, err
+	}
+
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return models.Book{}, err
+	}
+	defer tx.Rollback()
+
+	seriesID, seriesPosition := seriesColumns(book)
+	_, err = tx.ExecContext(ctx, `
+		UPDATE books SET title = ?, author_id = ?, genres = ?, published_at = ?, price = ?, stock = ?,
+			isbn = ?, publisher = ?, language = ?, page_count = ?, format = ?, edition = ?,
+			series_id = ?, series_position = ?
+		WHERE id = ?`,
+		book.Title, book.Author.ID, string(genres), formatTime(book.PublishedAt), book.Price, book.Stock,
+		isbnValue(book.ISBN), book.Publisher, book.Language, book.PageCount, book.Format, book.Edition,
+		seriesID, seriesPosition, id)
+	if isUniqueViolation(err) {
+		return models.Book{}, ErrDuplicateISBN
+	}
+	if err != nil {
+		return models.Book{}, err
+	}
+
+	if err := setContributors(ctx, tx, id, book.Contributors); err != nil {
+		return models.Book{}, err
+	}
+
+	if err := indexBookText(ctx, tx, id); err != nil {
+		return models.Book{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Book{}, err
+	}
+
+	return s.GetBook(ctx, id)
+}
+
+func (s *SQLiteStore) SetBookCover(ctx context.Context, id int, cover *models.BookCover) (models.Book, error) {
+	var (
+		format        string
+		width, height int
+		updatedAt     string
+	)
+	if cover != nil {
+		format, width, height = cover.Format, cover.Width, cover.Height
+		updatedAt = formatTime(cover.UpdatedAt)
+	}
+
+	res, err := s.q.ExecContext(ctx, `
+		UPDATE books SET cover_format = ?, cover_width = ?, cover_height = ?, cover_updated_at = ?
+		WHERE id = ?`, format, width, height, updatedAt, id)
+	if err != nil {
+		return models.Book{}, err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.Book{}, errors.New("book not found")
+	}
+
+	return s.GetBook(ctx, id)
+}
+
+func (s *SQLiteStore) DeleteBook(ctx context.Context, id int) error {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	found, err := removeBook(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.New("book not found")
+	}
+	return tx.Commit()
+}
+
+// removeBook deletes a book with its credits and search entries. It reports
+// false if there was no such book.
+func removeBook(ctx context.Context, q sqlQuerier, id int) (bool, error) {
+	res, err := q.ExecContext(ctx, `DELETE FROM books WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	if err := setContributors(ctx, q, id, nil); err != nil {
+		return false, err
+	}
+	if _, err := q.ExecContext(ctx, `DELETE FROM reviews WHERE book_id = ?`, id); err != nil {
+		return false, err
+	}
+	return true, unindexBook(ctx, q, id)
+}
+
+// saveCredits writes back the contributors of a book, and its primary author,
+// after they were changed on behalf of an author.
+func saveCredits(ctx context.Context, q sqlQuerier, book models.Book) error {
+	if _, err := q.ExecContext(ctx, `UPDATE books SET author_id = ? WHERE id = ?`, book.Author.ID, book.ID); err != nil {
+		return err
+	}
+	if err := setContributors(ctx, q, book.ID, book.Contributors); err != nil {
+		return err
+	}
+	return indexBookText(ctx, q, book.ID)
+}
+
+func (s *SQLiteStore) SearchBooks(ctx context.Context, criteria models.SearchCriteria) (models.BookSearchResult, error) {
+	where, args, scores, err := s.bookFilter(ctx, criteria)
+	if err != nil {
+		return models.BookSearchResult{}, err
+	}
+
+	query := sqliteBookSelect
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	books, err := s.queryBooks(ctx, query, args...)
+	if err != nil {
+		return models.BookSearchResult{}, err
+	}
+
+	for i := range books {
+		books[i].Score = scores[books[i].ID]
+	}
+
+	var unitsSold map[int]int
+	if sortsByPopularity(criteria) {
+		if unitsSold, err = s.unitsSold(ctx); err != nil {
+			return models.BookSearchResult{}, err
+		}
+	}
+	return pageBookResults(books, criteria, unitsSold)
+}
+
+// ScanBooks reads the matches a batch at a time with LIMIT, each batch
+// starting after the last ID of the one before.
+func (s *SQLiteStore) ScanBooks(ctx context.Context, criteria models.SearchCriteria, batch int, fn func(books []models.Book) error) error {
+	where, args, scores, err := s.bookFilter(ctx, criteria)
+	if err != nil {
+		return err
+	}
+
+	query := sqliteBookSelect + " WHERE " + strings.Join(append(where, "b.id > ?"), " AND ") +
+		fmt.Sprintf(" ORDER BY b.id LIMIT %d", batch)
+	after := 0
+	for {
+		books, err := s.queryBooks(ctx, query, append(args, after)...)
+		if err != nil {
+			return err
+		}
+		if len(books) == 0 {
+			return nil
+		}
+
+		for i := range books {
+			books[i].Score = scores[books[i].ID]
+		}
+		if err := fn(books); err != nil {
+			return err
+		}
+		if len(books) < batch {
+			return nil
+		}
+		after = books[len(books)-1].ID
+	}
+}
+
+// bookFilter turns the filters of criteria into conditions on the books of
+// sqliteBookSelect, along with the relevance of each match to a text query.
+func (s *SQLiteStore) bookFilter(ctx context.Context, criteria models.SearchCriteria) (where []string, args []any, scores map[int]float64, err error) {
+	if criteria.Query != "" {
+		scores, err = s.bookScores(ctx, criteria.Query)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		ids := make([]string, 0, len(scores))
+		for id := range scores {
+			ids = append(ids, strconv.Itoa(id))
+		}
+		where = append(where, `b.id IN (`+strings.Join(ids, ", ")+`)`)
+	}
+
+	if criteria.Title != "" {
+		where = append(where, `instr(lower(b.title), lower(?)) > 0`)
+		args = append(args, criteria.Title)
+	}
+
+	if criteria.Author != "" {
+		var authorMatches []string
+		for _, word := range strings.Fields(strings.ToLower(criteria.Author)) {
+			authorMatches = append(authorMatches,
+				`instr(lower(ca.first_name), ?) > 0 OR instr(lower(ca.last_name), ?) > 0`)
+			args = append(args, word, word)
+		}
+		if len(authorMatches) > 0 {
+			where = append(where, `EXISTS (
+				SELECT 1 FROM book_contributors c JOIN authors ca ON ca.id = c.author_id
+				WHERE c.book_id = b.id AND (`+strings.Join(authorMatches, " OR ")+`))`)
+		}
+	}
+
+	if criteria.AuthorID != nil {
+		where = append(where, `EXISTS (SELECT 1 FROM book_contributors c WHERE c.book_id = b.id AND c.author_id = ?)`)
+		args = append(args, *criteria.AuthorID)
+	}
+
+	if len(criteria.Genres) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(criteria.Genres)), ", ")
+		where = append(where, `EXISTS (SELECT 1 FROM json_each(b.genres) WHERE json_each.value IN (`+placeholders+`))`)
+		for _, genre := range criteria.Genres {
+			args = append(args, genre)
+		}
+	}
+
+	if criteria.MinPrice != nil {
+		where = append(where, `b.price >= ?`)
+		args = append(args, *criteria.MinPrice)
+	}
+
+	if criteria.MaxPrice != nil {
+		where = append(where, `b.price <= ?`)
+		args = append(args, *criteria.MaxPrice)
+	}
+
+	if criteria.InStock != nil {
+		if *criteria.InStock {
+			where = append(where, `b.stock > 0`)
+		} else {
+			where = append(where, `b.stock <= 0`)
+		}
+	}
+
+	if criteria.Series != nil {
+		where = append(where, `b.series_id = ?`)
+		args = append(args, *criteria.Series)
+	}
+
+	if criteria.MinRating != nil {
+		where = append(where, `b.rating_average >= ?`)
+		args = append(args, *criteria.MinRating)
+	}
+
+	return where, args, scores, nil
+}
+
+// ListAuthorBooks pages through books wrapped in a subquery, so the id
+// column pageQuery filters on is the book's.
+func (s *SQLiteStore) ListAuthorBooks(ctx context.Context, authorID int, page models.PageRequest) (models.Page[models.Book], error) {
+	total, err := s.countRows(ctx, `(SELECT DISTINCT book_id, author_id FROM book_contributors)`,
+		[]string{"author_id = ?"}, authorID)
+	if err != nil {
+		return models.Page[models.Book]{}, err
+	}
+
+	p, err := pageQuery(bookListOrder, page,
+		[]string{"id IN (SELECT book_id FROM book_contributors WHERE author_id = ?)"}, []any{authorID})
+	if err != nil {
+		return models.Page[models.Book]{}, err
+	}
+
+	books, err := s.queryBooks(ctx, `SELECT * FROM (`+sqliteBookSelect+`)`+p.where+` ORDER BY id`+p.limit, p.args...)
+	if err != nil {
+		return models.Page[models.Book]{}, err
+	}
+	return bookListOrder.PageFrom(books, total, page.Limit), nil
+}
+
+// unitsSold totals the quantity of each book across completed orders.
+func (s *SQLiteStore) unitsSold(ctx context.Context) (map[int]int, error) {
+	rows, err := s.q.QueryContext(ctx, `
+		SELECT i.book_id, SUM(i.quantity) FROM order_items i
+		JOIN orders o ON o.id = i.order_id
+		WHERE o.status = 'completed'
+		GROUP BY i.book_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sold := make(map[int]int)
+	for rows.Next() {
+		var id, quantity int
+		if err := rows.Scan(&id, &quantity); err != nil {
+			return nil, err
+		}
+		sold[id] = quantity
+	}
+	return sold, rows.Err()
+}
+
+func (s *SQLiteStore) BookExists(id int) bool {
+	var exists bool
+	s.q.QueryRowContext(context.Background(), `SELECT EXISTS (SELECT 1 FROM books WHERE id = ?)`, id).Scan(&exists)
+	return exists
+}
+
+func (s *SQLiteStore) BooksCount() int {
+	var count int
+	s.q.QueryRowContext(context.Background(), `SELECT COUNT(*) FROM books`).Scan(&count)
+	return count
+}
+
+func (s *SQLiteStore) OutOfStock() []models.Book {
+	books, err := s.queryBooks(context.Background(), sqliteBookSelect+` WHERE b.stock = 0 ORDER BY b.id`)
+	if err != nil {
+		return make([]models.Book, 0)
+	}
+	return books
+}
+
+func (s *SQLiteStore) GetBooksPerGenre(genre string) []models.Book {
+	books, err := s.queryBooks(context.Background(), sqliteBookSelect+
+		` WHERE EXISTS (SELECT 1 FROM json_each(b.genres) WHERE json_each.value = ?) ORDER BY b.id`, genre)
+	if err != nil {
+		return make([]models.Book, 0)
+	}
+	return books
+}
@@ -0,0 +1,184 @@
+package store
+
+import (
+	"Book-Store/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+func (s *SQLiteStore) GetCart(ctx context.Context, key string) (models.Cart, error) {
+	return loadCart(ctx, s.q, key)
+}
+
+func (s *SQLiteStore) AddCartItem(ctx context.Context, key string, bookID, quantity int) (models.Cart, error) {
+	return s.changeCartItem(ctx, key, bookID, quantity, `quantity + excluded.quantity`)
+}
+
+func (s *SQLiteStore) SetCartItem(ctx context.Context, key string, bookID, quantity int) (models.Cart, error) {
+	return s.changeCartItem(ctx, key, bookID, quantity, `excluded.quantity`)
+}
+
+// changeCartItem upserts the item of a book, with update computing the new
+// quantity of an existing one.
+func (s *SQLiteStore) changeCartItem(ctx context.Context, key string, bookID, quantity int, update string) (models.Cart, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return models.Cart{}, err
+	}
+	defer tx.Rollback()
+
+	if err := touchCart(ctx, tx, key); err != nil {
+		return models.Cart{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO cart_items (cart, book_id, quantity) VALUES (?, ?, ?)
+		ON CONFLICT (cart, book_id) DO UPDATE SET quantity = `+update,
+		key, bookID, quantity); err != nil {
+		return models.Cart{}, err
+	}
+
+	var next int
+	if err := tx.QueryRowContext(ctx,
+		`SELECT quantity FROM cart_items WHERE cart = ? AND book_id = ?`, key, bookID).Scan(&next); err != nil {
+		return models.Cart{}, err
+	}
+	if next < 0 {
+		return models.Cart{}, errors.New("quantity must not be negative")
+	}
+
+	// Books deleted from the catalog can still be taken out of the cart.
+	var exists bool
+	if err := tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM books WHERE id = ?)`, bookID).Scan(&exists); err != nil {
+		return models.Cart{}, err
+	}
+	if !exists && next > 0 {
+		return models.Cart{}, errors.New("book not found")
+	}
+	if next == 0 {
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM cart_items WHERE cart = ? AND book_id = ?`, key, bookID); err != nil {
+			return models.Cart{}, err
+		}
+	}
+
+	cart, err := finishCart(ctx, tx, key)
+	if err != nil {
+		return models.Cart{}, err
+	}
+	return cart, tx.Commit()
+}
+
+func (s *SQLiteStore) MergeCart(ctx context.Context, from, to string) (models.Cart, error) {
+	if from == to {
+		return s.GetCart(ctx, to)
+	}
+
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return models.Cart{}, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM carts WHERE key = ?)`, from).Scan(&exists); err != nil {
+		return models.Cart{}, err
+	}
+	if !exists {
+		return loadCart(ctx, tx, to)
+	}
+
+	if err := touchCart(ctx, tx, to); err != nil {
+		return models.Cart{}, err
+	}
+
+	// The WHERE clause keeps SQLite from reading ON CONFLICT as a join.
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO cart_items (cart, book_id, quantity)
+		SELECT ?, book_id, quantity FROM cart_items WHERE cart = ? ORDER BY rowid
+		ON CONFLICT (cart, book_id) DO UPDATE SET quantity = quantity + excluded.quantity`,
+		to, from); err != nil {
+		return models.Cart{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM carts WHERE key = ?`, from); err != nil {
+		return models.Cart{}, err
+	}
+
+	cart, err := finishCart(ctx, tx, to)
+	if err != nil {
+		return models.Cart{}, err
+	}
+	return cart, tx.Commit()
+}
+
+func (s *SQLiteStore) DeleteCart(ctx context.Context, key string) error {
+	_, err := s.q.ExecContext(ctx, `DELETE FROM carts WHERE key = ?`, key)
+	return err
+}
+
+func (s *SQLiteStore) ExpireSessionCarts(ctx context.Context, before time.Time) (int, error) {
+	result, err := s.q.ExecContext(ctx,
+		`DELETE FROM carts WHERE key LIKE ? AND updated_at < ?`,
+		models.SessionCartKey("")+"%", formatTime(before))
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+// touchCart creates the cart under key if needed and marks it as changed.
+func touchCart(ctx context.Context, q sqlQuerier, key string) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO carts (key, updated_at) VALUES (?, ?)
+		ON CONFLICT (key) DO UPDATE SET updated_at = excluded.updated_at`,
+		key, formatTime(time.Now()))
+	return err
+}
+
+// finishCart drops the cart under key once it is empty, returning what is
+// left of it.
+func finishCart(ctx context.Context, q sqlQuerier, key string) (models.Cart, error) {
+	if _, err := q.ExecContext(ctx, `
+		DELETE FROM carts WHERE key = ? AND NOT EXISTS (SELECT 1 FROM cart_items WHERE cart = carts.key)`,
+		key); err != nil {
+		return models.Cart{}, err
+	}
+
+	return loadCart(ctx, q, key)
+}
+
+func loadCart(ctx context.Context, q sqlQuerier, key string) (models.Cart, error) {
+	cart := models.Cart{Items: make([]models.CartItem, 0)}
+
+	var updatedAt string
+	err := q.QueryRowContext(ctx, `SELECT updated_at FROM carts WHERE key = ?`, key).Scan(&updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return cart, nil
+	}
+	if err != nil {
+		return models.Cart{}, err
+	}
+	cart.UpdatedAt = parseTime(updatedAt)
+
+	rows, err := q.QueryContext(ctx,
+		`SELECT book_id, quantity FROM cart_items WHERE cart = ? ORDER BY rowid`, key)
+	if err != nil {
+		return models.Cart{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.CartItem
+		if err := rows.Scan(&item.BookID, &item.Quantity); err != nil {
+			return models.Cart{}, err
+		}
+		cart.Items = append(cart.Items, item)
+	}
+	return cart, rows.Err()
+}
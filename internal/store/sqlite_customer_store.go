@@ -0,0 +1,164 @@
+package store
+
+import (
+	"Book-Store/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+const sqliteCustomerSelect = `
+SELECT id, COALESCE(public_id, ''), name, email, password, role, street, city, state, postal_code, country, created_at
+FROM customers`
+
+func scanCustomer(row rowScanner) (models.Customer, error) {
+	var (
+		customer  models.Customer
+		createdAt string
+	)
+	err := row.Scan(&customer.ID, &customer.PublicID, &customer.Name, &customer.Email, &customer.Password, &customer.Role,
+		&customer.Address.Street, &customer.Address.City, &customer.Address.State,
+		&customer.Address.PostalCode, &customer.Address.Country, &createdAt)
+	if err != nil {
+		return models.Customer{}, err
+	}
+	customer.CreatedAt = parseTime(createdAt)
+	return customer, nil
+}
+
+func (s *SQLiteStore) getCustomerWhere(ctx context.Context, where string, arg any) (models.Customer, error) {
+	customer, err := scanCustomer(s.q.QueryRowContext(ctx, sqliteCustomerSelect+" WHERE "+where, arg))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Customer{}, errors.New("Customer not found")
+	}
+	return customer, err
+}
+
+func isUniqueViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+func (s *SQLiteStore) CreateCustomer(ctx context.Context, customer models.Customer) (models.Customer, error) {
+	if customer.CreatedAt.IsZero() {
+		customer.CreatedAt = time.Now()
+	}
+
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return models.Customer{}, err
+	}
+	defer tx.Rollback()
+
+	customer.ID, err = nextSQLiteID(ctx, tx, "customers")
+	if err != nil {
+		return models.Customer{}, err
+	}
+
+	publicID := s.publicID()
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO customers (id, public_id, name, email, password, role, street, city, state, postal_code, country, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		customer.ID, publicID, customer.Name, customer.Email, customer.Password, customer.Role,
+		customer.Address.Street, customer.Address.City, customer.Address.State,
+		customer.Address.PostalCode, customer.Address.Country, formatTime(customer.CreatedAt))
+	if isUniqueViolation(err) {
+		return models.Customer{}, errors.New("Email already exists")
+	}
+	if err != nil {
+		return models.Customer{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Customer{}, err
+	}
+
+	customer.PublicID, _ = publicID.(string)
+	return customer, nil
+}
+
+func (s *SQLiteStore) GetCustomer(ctx context.Context, id int) (models.Customer, error) {
+	return s.getCustomerWhere(ctx, "id = ?", id)
+}
+
+func (s *SQLiteStore) GetCustomerByEmail(ctx context.Context, email string) (models.Customer, error) {
+	return s.getCustomerWhere(ctx, "email = ?", email)
+}
+
+func (s *SQLiteStore) UpdateCustomer(ctx context.Context, id int, customer models.Customer) (models.Customer, error) {
+	res, err := s.q.ExecContext(ctx, `
+		UPDATE customers SET
+			name  = COALESCE(NULLIF(?, ''), name),
+			email = COALESCE(NULLIF(?, ''), email),
+			role  = COALESCE(NULLIF(?, ''), role)
+		WHERE id = ?`,
+		customer.Name, customer.Email, customer.Role, id)
+	if isUniqueViolation(err) {
+		return models.Customer{}, errors.New("Email already exists")
+	}
+	if err != nil {
+		return models.Customer{}, err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.Customer{}, errors.New("Customer not found")
+	}
+
+	return s.GetCustomer(ctx, id)
+}
+
+func (s *SQLiteStore) ListCustomers(ctx context.Context, page models.PageRequest) (models.Page[models.Customer], error) {
+	total, err := s.countRows(ctx, "customers", nil)
+	if err != nil {
+		return models.Page[models.Customer]{}, err
+	}
+
+	p, err := pageQuery(customerOrder, page, nil, nil)
+	if err != nil {
+		return models.Page[models.Customer]{}, err
+	}
+
+	rows, err := s.q.QueryContext(ctx, sqliteCustomerSelect+p.where+" ORDER BY id"+p.limit, p.args...)
+	if err != nil {
+		return models.Page[models.Customer]{}, err
+	}
+	defer rows.Close()
+
+	customers := make([]models.Customer, 0)
+	for rows.Next() {
+		customer, err := scanCustomer(rows)
+		if err != nil {
+			return models.Page[models.Customer]{}, err
+		}
+		customers = append(customers, customer)
+	}
+	if err := rows.Err(); err != nil {
+		return models.Page[models.Customer]{}, err
+	}
+	return customerOrder.PageFrom(customers, total, page.Limit), nil
+}
+
+func (s *SQLiteStore) DeleteCustomer(ctx context.Context, id int) error {
+	res, err := s.q.ExecContext(ctx, `DELETE FROM customers WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("Customer not found")
+	}
+	return nil
+}
+
+func (s *SQLiteStore) CustomerExists(id int) bool {
+	var exists bool
+	s.q.QueryRowContext(context.Background(), `SELECT EXISTS (SELECT 1 FROM customers WHERE id = ?)`, id).Scan(&exists)
+	return exists
+}
+
+func (s *SQLiteStore) CustomersCount() int {
+	var count int
+	s.q.QueryRowContext(context.Background(), `SELECT COUNT(*) FROM customers`).Scan(&count)
+	return count
+}
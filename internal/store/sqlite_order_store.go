@@ -0,0 +1,250 @@
+package store
+
+import (
+	"Book-Store/internal/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CreateOrder checks and decrements stock for every item and records the
+// order in a single transaction, so a failed item leaves stock untouched.
+func (s *SQLiteStore) CreateOrder(ctx context.Context, order models.Order) (models.Order, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return models.Order{}, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx,
+		`SELECT COALESCE(public_id, '') FROM customers WHERE id = ?`, order.Customer.ID).Scan(&order.Customer.PublicID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Order{}, errors.New("customer not found")
+	}
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	var totalPrice float64
+	for i, item := range order.Items {
+		book, err := loadBook(ctx, tx, sqliteBookSelect+` WHERE b.id = ?`, item.Book.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Order{}, errors.New("book not found in order")
+		}
+		if err != nil {
+			return models.Order{}, err
+		}
+		if book.Stock < item.Quantity {
+			return models.Order{}, errors.New("insufficient stock")
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE books SET stock = stock - ? WHERE id = ?`, item.Quantity, book.ID); err != nil {
+			return models.Order{}, err
+		}
+		book.Stock -= item.Quantity
+		order.Items[i].Book = book
+		totalPrice += book.Price * float64(item.Quantity)
+	}
+
+	order.ID, err = nextSQLiteID(ctx, tx, "orders")
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	publicID := s.publicID()
+	order.PublicID, _ = publicID.(string)
+	order.Status = "created"
+	order.TotalPrice = totalPrice
+	order.CreatedAt = time.Now()
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO orders (id, public_id, customer_id, total_price, created_at, status) VALUES (?, ?, ?, ?, ?, ?)`,
+		order.ID, publicID, order.Customer.ID, order.TotalPrice, formatTime(order.CreatedAt), order.Status); err != nil {
+		return models.Order{}, err
+	}
+
+	for i, item := range order.Items {
+		snapshot, err := json.Marshal(item.Book)
+		if err != nil {
+			return models.Order{}, err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO order_items (order_id, position, book_id, book, quantity) VALUES (?, ?, ?, ?, ?)`,
+			order.ID, i, item.Book.ID, string(snapshot), item.Quantity); err != nil {
+			return models.Order{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Order{}, err
+	}
+
+	return order, nil
+}
+
+func (s *SQLiteStore) GetOrder(ctx context.Context, id int) (models.Order, error) {
+	orders, err := s.queryOrders(ctx, ` WHERE id = ?`, id)
+	if err != nil {
+		return models.Order{}, err
+	}
+	if len(orders) == 0 {
+		return models.Order{}, errors.New("order not found")
+	}
+	return orders[0], nil
+}
+
+func (s *SQLiteStore) CompleteOrder(ctx context.Context, id int) (bool, error) {
+	res, err := s.q.ExecContext(ctx,
+		`UPDATE orders SET status = 'completed' WHERE id = ? AND status = 'created'`, id)
+	if err != nil {
+		return false, err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		if _, err := s.GetOrder(ctx, id); err != nil {
+			return false, err
+		}
+		return false, errors.New("order cannot be completed")
+	}
+	return true, nil
+}
+
+func (s *SQLiteStore) CancelOrder(ctx context.Context, id int) (bool, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRowContext(ctx, `SELECT status FROM orders WHERE id = ?`, id).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, errors.New("order not found")
+	}
+	if err != nil {
+		return false, err
+	}
+	if status != "created" {
+		return false, errors.New("order cannot be cancelled")
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE books SET stock = stock + (
+			SELECT SUM(quantity) FROM order_items WHERE order_id = ? AND book_id = books.id
+		)
+		WHERE id IN (SELECT book_id FROM order_items WHERE order_id = ?)`, id, id); err != nil {
+		return false, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE orders SET status = 'cancelled' WHERE id = ?`, id); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (s *SQLiteStore) SearchOrderByStatus(ctx context.Context, status string, page models.PageRequest) (models.Page[models.Order], error) {
+	return s.pageOrders(ctx, page, []string{`status = ?`}, status)
+}
+
+func (s *SQLiteStore) ListOrders(ctx context.Context, page models.PageRequest) (models.Page[models.Order], error) {
+	return s.pageOrders(ctx, page, nil)
+}
+
+func (s *SQLiteStore) GetOrdersInTimeRange(ctx context.Context, start, end time.Time, page models.PageRequest) (models.Page[models.Order], error) {
+	// MemStore compares whole seconds, so the range is widened to match.
+	return s.pageOrders(ctx, page, []string{`created_at >= ?`, `created_at < ?`},
+		formatTime(start.Truncate(time.Second)),
+		formatTime(end.Truncate(time.Second).Add(time.Second)))
+}
+
+// pageOrders loads one page of the orders matching conds.
+func (s *SQLiteStore) pageOrders(ctx context.Context, page models.PageRequest, conds []string, args ...any) (models.Page[models.Order], error) {
+	total, err := s.countRows(ctx, "orders", conds, args...)
+	if err != nil {
+		return models.Page[models.Order]{}, err
+	}
+
+	p, err := pageQuery(orderOrder, page, conds, args)
+	if err != nil {
+		return models.Page[models.Order]{}, err
+	}
+
+	orders, err := s.queryOrders(ctx, p.where+` ORDER BY id`+p.limit, p.args...)
+	if err != nil {
+		return models.Page[models.Order]{}, err
+	}
+	return orderOrder.PageFrom(orders, total, page.Limit), nil
+}
+
+// queryOrders loads the orders selected by clause, the part of the query
+// after FROM orders, along with their items.
+func (s *SQLiteStore) queryOrders(ctx context.Context, clause string, args ...any) ([]models.Order, error) {
+	rows, err := s.q.QueryContext(ctx,
+		`SELECT id, COALESCE(public_id, ''), customer_id,
+			COALESCE((SELECT public_id FROM customers WHERE customers.id = orders.customer_id), ''),
+			total_price, created_at, status FROM orders`+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]models.Order, 0)
+	positions := make(map[int]int)
+	for rows.Next() {
+		var (
+			order     models.Order
+			createdAt string
+		)
+		if err := rows.Scan(&order.ID, &order.PublicID, &order.Customer.ID, &order.Customer.PublicID, &order.TotalPrice, &createdAt, &order.Status); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		order.CreatedAt = parseTime(createdAt)
+		order.Items = make([]models.OrderItem, 0)
+		positions[order.ID] = len(orders)
+		orders = append(orders, order)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return orders, nil
+	}
+
+	ids := make([]string, 0, len(orders))
+	for _, order := range orders {
+		ids = append(ids, strconv.Itoa(order.ID))
+	}
+	itemRows, err := s.q.QueryContext(ctx, `
+		SELECT order_id, book, quantity FROM order_items
+		WHERE order_id IN (`+strings.Join(ids, ", ")+`)
+		ORDER BY order_id, position`)
+	if err != nil {
+		return nil, err
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var (
+			orderID  int
+			snapshot string
+			item     models.OrderItem
+		)
+		if err := itemRows.Scan(&orderID, &snapshot, &item.Quantity); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(snapshot), &item.Book); err != nil {
+			return nil, err
+		}
+		if i, ok := positions[orderID]; ok {
+			orders[i].Items = append(orders[i].Items, item)
+		}
+	}
+	return orders, itemRows.Err()
+}
@@ -0,0 +1,130 @@
+package store
+
+import (
+	"Book-Store/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+func (s *SQLiteStore) CreateRefreshToken(ctx context.Context, token models.RefreshToken) (models.RefreshToken, error) {
+	now := time.Now()
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = now
+	}
+
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM refresh_tokens WHERE expires_at < ?`, formatTime(now)); err != nil {
+		return models.RefreshToken{}, err
+	}
+
+	if err := insertRefreshToken(ctx, tx, token); err != nil {
+		return models.RefreshToken{}, err
+	}
+
+	return token, tx.Commit()
+}
+
+// RotateRefreshToken mirrors MemStore: reusing a rotated token revokes its
+// whole family.
+func (s *SQLiteStore) RotateRefreshToken(ctx context.Context, oldHash string, next models.RefreshToken) (models.RefreshToken, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+	defer tx.Rollback()
+
+	var (
+		old       models.RefreshToken
+		expiresAt string
+		revokedAt sql.NullString
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT customer_id, family_id, expires_at, revoked_at, replaced_by
+		FROM refresh_tokens WHERE token_hash = ?`, oldHash).
+		Scan(&old.CustomerID, &old.FamilyID, &expiresAt, &revokedAt, &old.ReplacedBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.RefreshToken{}, ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+
+	now := time.Now()
+	if revokedAt.Valid {
+		if old.ReplacedBy == "" {
+			return models.RefreshToken{}, ErrRefreshTokenRevoked
+		}
+		if err := revokeRefreshTokenFamily(ctx, tx, old.FamilyID, now); err != nil {
+			return models.RefreshToken{}, err
+		}
+		if err := tx.Commit(); err != nil {
+			return models.RefreshToken{}, err
+		}
+		return models.RefreshToken{}, ErrRefreshTokenReused
+	}
+	if now.After(parseTime(expiresAt)) {
+		return models.RefreshToken{}, ErrRefreshTokenExpired
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE refresh_tokens SET revoked_at = ?, replaced_by = ? WHERE token_hash = ?`,
+		formatTime(now), next.TokenHash, oldHash); err != nil {
+		return models.RefreshToken{}, err
+	}
+
+	next.CustomerID = old.CustomerID
+	next.FamilyID = old.FamilyID
+	next.CreatedAt = now
+	if err := insertRefreshToken(ctx, tx, next); err != nil {
+		return models.RefreshToken{}, err
+	}
+
+	return next, tx.Commit()
+}
+
+func (s *SQLiteStore) RevokeRefreshToken(ctx context.Context, tokenHash string) error {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var familyID string
+	err = tx.QueryRowContext(ctx,
+		`SELECT family_id FROM refresh_tokens WHERE token_hash = ?`, tokenHash).Scan(&familyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := revokeRefreshTokenFamily(ctx, tx, familyID, time.Now()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertRefreshToken(ctx context.Context, q sqlQuerier, token models.RefreshToken) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO refresh_tokens (token_hash, customer_id, family_id, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		token.TokenHash, token.CustomerID, token.FamilyID,
+		formatTime(token.CreatedAt), formatTime(token.ExpiresAt))
+	return err
+}
+
+func revokeRefreshTokenFamily(ctx context.Context, q sqlQuerier, familyID string, now time.Time) error {
+	_, err := q.ExecContext(ctx,
+		`UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL`,
+		formatTime(now), familyID)
+	return err
+}
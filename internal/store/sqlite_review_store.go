@@ -0,0 +1,238 @@
+package store
+
+import (
+	"Book-Store/internal/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const sqliteReviewSelect = `SELECT id, COALESCE(public_id, ''), book_id, customer_id, customer_name,
+	rating, title, body, created_at, updated_at FROM reviews`
+
+func (s *SQLiteStore) CreateReview(ctx context.Context, review models.Review) (models.Review, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return models.Review{}, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM books WHERE id = ?)`, review.BookID).Scan(&exists); err != nil {
+		return models.Review{}, err
+	}
+	if !exists {
+		return models.Review{}, errors.New("book not found")
+	}
+
+	err = tx.QueryRowContext(ctx, `SELECT name FROM customers WHERE id = ?`, review.CustomerID).
+		Scan(&review.CustomerName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Review{}, ErrReviewNotAllowed
+	}
+	if err != nil {
+		return models.Review{}, err
+	}
+
+	var purchased bool
+	if err := tx.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM order_items i
+			JOIN orders o ON o.id = i.order_id
+			WHERE o.customer_id = ? AND o.status = 'completed' AND i.book_id = ?
+		)`, review.CustomerID, review.BookID).Scan(&purchased); err != nil {
+		return models.Review{}, err
+	}
+	if !purchased {
+		return models.Review{}, ErrReviewNotAllowed
+	}
+
+	review.ID, err = nextSQLiteID(ctx, tx, "reviews")
+	if err != nil {
+		return models.Review{}, err
+	}
+	review.CreatedAt = time.Now().UTC()
+	review.UpdatedAt = review.CreatedAt
+
+	publicID := s.publicID()
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO reviews (id, public_id, book_id, customer_id, customer_name, rating, title, body, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		review.ID, publicID, review.BookID, review.CustomerID, review.CustomerName,
+		review.Rating, review.Title, review.Body, formatTime(review.CreatedAt), formatTime(review.UpdatedAt)); err != nil {
+		if isUniqueViolation(err) {
+			return models.Review{}, ErrDuplicateReview
+		}
+		return models.Review{}, err
+	}
+
+	if err := rateBook(ctx, tx, review.BookID); err != nil {
+		return models.Review{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Review{}, err
+	}
+
+	review.PublicID, _ = publicID.(string)
+	return review, nil
+}
+
+func (s *SQLiteStore) GetReview(ctx context.Context, id int) (models.Review, error) {
+	review, err := scanReview(s.q.QueryRowContext(ctx, sqliteReviewSelect+` WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Review{}, errors.New("Review not found")
+	}
+	if err != nil {
+		return models.Review{}, err
+	}
+	return review, nil
+}
+
+func (s *SQLiteStore) ListBookReviews(ctx context.Context, bookID int, page models.PageRequest) (models.Page[models.Review], error) {
+	conds := []string{"book_id = ?"}
+	args := []any{bookID}
+
+	total, err := s.countRows(ctx, "reviews", conds, args...)
+	if err != nil {
+		return models.Page[models.Review]{}, err
+	}
+
+	p, err := pageQuery(reviewOrder, page, conds, args)
+	if err != nil {
+		return models.Page[models.Review]{}, err
+	}
+
+	rows, err := s.q.QueryContext(ctx, sqliteReviewSelect+p.where+` ORDER BY id`+p.limit, p.args...)
+	if err != nil {
+		return models.Page[models.Review]{}, err
+	}
+	defer rows.Close()
+
+	reviews := make([]models.Review, 0)
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return models.Page[models.Review]{}, err
+		}
+		reviews = append(reviews, review)
+	}
+	if err := rows.Err(); err != nil {
+		return models.Page[models.Review]{}, err
+	}
+	return reviewOrder.PageFrom(reviews, total, page.Limit), nil
+}
+
+func (s *SQLiteStore) UpdateReview(ctx context.Context, id int, review models.Review) (models.Review, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return models.Review{}, err
+	}
+	defer tx.Rollback()
+
+	existing, err := scanReview(tx.QueryRowContext(ctx, sqliteReviewSelect+` WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Review{}, errors.New("Review not found")
+	}
+	if err != nil {
+		return models.Review{}, err
+	}
+
+	existing.Rating = review.Rating
+	existing.Title = review.Title
+	existing.Body = review.Body
+	existing.UpdatedAt = time.Now().UTC()
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE reviews SET rating = ?, title = ?, body = ?, updated_at = ? WHERE id = ?`,
+		existing.Rating, existing.Title, existing.Body, formatTime(existing.UpdatedAt), id); err != nil {
+		return models.Review{}, err
+	}
+
+	if err := rateBook(ctx, tx, existing.BookID); err != nil {
+		return models.Review{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Review{}, err
+	}
+	return existing, nil
+}
+
+func (s *SQLiteStore) DeleteReview(ctx context.Context, id int) error {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var bookID int
+	err = tx.QueryRowContext(ctx, `SELECT book_id FROM reviews WHERE id = ?`, id).Scan(&bookID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("Review not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM reviews WHERE id = ?`, id); err != nil {
+		return err
+	}
+
+	if err := rateBook(ctx, tx, bookID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// rateBook recomputes the rating columns of a book from its reviews.
+func rateBook(ctx context.Context, q sqlQuerier, bookID int) error {
+	rows, err := q.QueryContext(ctx,
+		`SELECT rating, COUNT(*) FROM reviews WHERE book_id = ? GROUP BY rating`, bookID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var summary models.RatingSummary
+	for rows.Next() {
+		var rating, count int
+		if err := rows.Scan(&rating, &count); err != nil {
+			return err
+		}
+		for range count {
+			summary.Add(rating)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	histogram, err := json.Marshal(summary.Histogram)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.ExecContext(ctx,
+		`UPDATE books SET rating_average = ?, rating_count = ?, rating_histogram = ? WHERE id = ?`,
+		summary.Average, summary.Count, string(histogram), bookID)
+	return err
+}
+
+func scanReview(row rowScanner) (models.Review, error) {
+	var (
+		review    models.Review
+		createdAt string
+		updatedAt string
+	)
+	if err := row.Scan(&review.ID, &review.PublicID, &review.BookID, &review.CustomerID, &review.CustomerName,
+		&review.Rating, &review.Title, &review.Body, &createdAt, &updatedAt); err != nil {
+		return models.Review{}, err
+	}
+	review.CreatedAt = parseTime(createdAt)
+	review.UpdatedAt = parseTime(updatedAt)
+	return review, nil
+}
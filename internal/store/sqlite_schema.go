@@ -0,0 +1,205 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+const sqliteInitialSchema = `
+CREATE TABLE IF NOT EXISTS authors (
+	id         INTEGER PRIMARY KEY,
+	first_name TEXT NOT NULL DEFAULT '',
+	last_name  TEXT NOT NULL DEFAULT '',
+	bio        TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS books (
+	id           INTEGER PRIMARY KEY,
+	title        TEXT NOT NULL DEFAULT '',
+	author_id    INTEGER NOT NULL,
+	genres       TEXT NOT NULL DEFAULT '[]',
+	published_at TEXT NOT NULL DEFAULT '',
+	price        REAL NOT NULL DEFAULT 0,
+	stock        INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS books_author_id ON books(author_id);
+
+CREATE TABLE IF NOT EXISTS customers (
+	id          INTEGER PRIMARY KEY,
+	name        TEXT NOT NULL DEFAULT '',
+	email       TEXT NOT NULL UNIQUE,
+	password    TEXT NOT NULL DEFAULT '',
+	role        TEXT NOT NULL DEFAULT '',
+	street      TEXT NOT NULL DEFAULT '',
+	city        TEXT NOT NULL DEFAULT '',
+	state       TEXT NOT NULL DEFAULT '',
+	postal_code TEXT NOT NULL DEFAULT '',
+	country     TEXT NOT NULL DEFAULT '',
+	created_at  TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+	id          INTEGER PRIMARY KEY,
+	customer_id INTEGER NOT NULL,
+	total_price REAL NOT NULL DEFAULT 0,
+	created_at  TEXT NOT NULL,
+	status      TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS orders_status ON orders(status);
+CREATE INDEX IF NOT EXISTS orders_created_at ON orders(created_at);
+
+-- book holds the JSON snapshot of the book at purchase time, like MemStore.
+CREATE TABLE IF NOT EXISTS order_items (
+	order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	book_id  INTEGER NOT NULL,
+	book     TEXT NOT NULL,
+	quantity INTEGER NOT NULL,
+	PRIMARY KEY (order_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+	token_hash  TEXT PRIMARY KEY,
+	customer_id INTEGER NOT NULL,
+	family_id   TEXT NOT NULL,
+	created_at  TEXT NOT NULL,
+	expires_at  TEXT NOT NULL,
+	revoked_at  TEXT,
+	replaced_by TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS refresh_tokens_family_id ON refresh_tokens(family_id);
+`
+
+// sqliteMigrations run in order and PRAGMA user_version records how many have
+// been applied. Only ever append to this list.
+var sqliteMigrations = []string{
+	sqliteInitialSchema,
+
+	// Persisted sequences so IDs of deleted rows are never reissued.
+	`CREATE TABLE sequences (
+		name  TEXT PRIMARY KEY,
+		value INTEGER NOT NULL
+	);`,
+
+	`ALTER TABLE books ADD COLUMN public_id TEXT;
+	ALTER TABLE authors ADD COLUMN public_id TEXT;
+	ALTER TABLE customers ADD COLUMN public_id TEXT;
+	ALTER TABLE orders ADD COLUMN public_id TEXT;
+	CREATE UNIQUE INDEX books_public_id ON books(public_id);
+	CREATE UNIQUE INDEX authors_public_id ON authors(public_id);
+	CREATE UNIQUE INDEX customers_public_id ON customers(public_id);
+	CREATE UNIQUE INDEX orders_public_id ON orders(public_id);`,
+
+	// Full-text index over books. Rows hold terms already analyzed by the
+	// search package, keyed by book ID, and are maintained by the store.
+	`CREATE VIRTUAL TABLE books_fts USING fts5(title, author, bio, genres);`,
+
+	// Vocabulary of books_fts for typo correction, and autocomplete entries
+	// stored once per word start so a range scan on key finds completions.
+	`CREATE VIRTUAL TABLE books_fts_vocab USING fts5vocab(books_fts, 'row');
+	CREATE TABLE suggestions (
+		kind   TEXT NOT NULL,
+		ref_id INTEGER NOT NULL,
+		text   TEXT NOT NULL,
+		key    TEXT NOT NULL
+	);
+	CREATE INDEX suggestions_key ON suggestions(key);
+	CREATE INDEX suggestions_ref ON suggestions(kind, ref_id);`,
+
+	// Publishing metadata. isbn is NULL rather than empty when unknown so
+	// the unique index only covers books that have one.
+	`ALTER TABLE books ADD COLUMN isbn TEXT;
+	ALTER TABLE books ADD COLUMN publisher TEXT NOT NULL DEFAULT '';
+	ALTER TABLE books ADD COLUMN language TEXT NOT NULL DEFAULT '';
+	ALTER TABLE books ADD COLUMN page_count INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE books ADD COLUMN format TEXT NOT NULL DEFAULT '';
+	ALTER TABLE books ADD COLUMN edition TEXT NOT NULL DEFAULT '';
+	CREATE UNIQUE INDEX books_isbn ON books(isbn);`,
+
+	// Every author credited on a book. books.author_id stays as the primary
+	// author; existing books credit it as their only contributor.
+	`CREATE TABLE book_contributors (
+		book_id   INTEGER NOT NULL,
+		position  INTEGER NOT NULL,
+		author_id INTEGER NOT NULL,
+		role      TEXT NOT NULL,
+		PRIMARY KEY (book_id, position)
+	);
+	CREATE INDEX book_contributors_author_id ON book_contributors(author_id);
+	INSERT INTO book_contributors (book_id, position, author_id, role)
+		SELECT id, 0, author_id, 'author' FROM books;`,
+
+	// Series a book belongs to and its position in the reading order.
+	`CREATE TABLE series (
+		id          INTEGER PRIMARY KEY,
+		public_id   TEXT UNIQUE,
+		name        TEXT NOT NULL DEFAULT '',
+		description TEXT NOT NULL DEFAULT ''
+	);
+	ALTER TABLE books ADD COLUMN series_id INTEGER;
+	ALTER TABLE books ADD COLUMN series_position REAL NOT NULL DEFAULT 0;
+	CREATE INDEX books_series_id ON books(series_id);`,
+
+	// Cover image details; the images themselves are files.
+	`ALTER TABLE books ADD COLUMN cover_format TEXT NOT NULL DEFAULT '';
+	ALTER TABLE books ADD COLUMN cover_width INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE books ADD COLUMN cover_height INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE books ADD COLUMN cover_updated_at TEXT NOT NULL DEFAULT '';`,
+
+	// Customer reviews, and the rating summary each book keeps of them.
+	`CREATE TABLE reviews (
+		id            INTEGER PRIMARY KEY,
+		public_id     TEXT UNIQUE,
+		book_id       INTEGER NOT NULL,
+		customer_id   INTEGER NOT NULL,
+		customer_name TEXT NOT NULL DEFAULT '',
+		rating        INTEGER NOT NULL,
+		title         TEXT NOT NULL DEFAULT '',
+		body          TEXT NOT NULL DEFAULT '',
+		created_at    TEXT NOT NULL,
+		updated_at    TEXT NOT NULL,
+		UNIQUE (book_id, customer_id)
+	);
+	ALTER TABLE books ADD COLUMN rating_average REAL NOT NULL DEFAULT 0;
+	ALTER TABLE books ADD COLUMN rating_count INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE books ADD COLUMN rating_histogram TEXT NOT NULL DEFAULT '[0,0,0,0,0]';`,
+
+	// Shopping carts of customers and anonymous sessions. Items keep the
+	// order they were added in through their rowid.
+	`CREATE TABLE carts (
+		key        TEXT PRIMARY KEY,
+		updated_at TEXT NOT NULL
+	);
+	CREATE TABLE cart_items (
+		cart     TEXT NOT NULL REFERENCES carts(key) ON DELETE CASCADE,
+		book_id  INTEGER NOT NULL,
+		quantity INTEGER NOT NULL,
+		PRIMARY KEY (cart, book_id)
+	);`,
+}
+
+func migrateSQLite(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return err
+	}
+
+	for i := version; i < len(sqliteMigrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(sqliteMigrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("sqlite migration %d failed: %w", i+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, i+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package store
+
+import (
+	"Book-Store/internal/models"
+	"context"
+	"encoding/json"
+)
+
+// sqlitePublicIDTables are the entities that carry public IDs; entity names
+// double as table names.
+var sqlitePublicIDTables = []string{"books", "authors", "series", "reviews", "customers", "orders"}
+
+// nextSQLiteID advances the persisted sequence for table. A table without a
+// sequence yet starts after its highest existing ID.
+func nextSQLiteID(ctx context.Context, q sqlQuerier, table string) (int, error) {
+	var id int
+	err := q.QueryRowContext(ctx, `
+		INSERT INTO sequences (name, value)
+		VALUES (?, (SELECT COALESCE(MAX(id), 0) FROM `+table+`) + 1)
+		ON CONFLICT(name) DO UPDATE SET value = value + 1
+		RETURNING value`, table).Scan(&id)
+	return id, err
+}
+
+func (s *SQLiteStore) publicID() any {
+	if !s.publicIDs {
+		return nil
+	}
+	return newPublicID()
+}
+
+func (s *SQLiteStore) EnablePublicIDs(ctx context.Context) error {
+	s.publicIDs = true
+
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range sqlitePublicIDTables {
+		rows, err := tx.QueryContext(ctx, `SELECT id FROM `+table+` WHERE public_id IS NULL`)
+		if err != nil {
+			return err
+		}
+		var ids []int
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			if _, err := tx.ExecContext(ctx,
+				`UPDATE `+table+` SET public_id = ? WHERE id = ?`, newPublicID(), id); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Order items keep a copy of the book as sold, which needs the new
+	// public IDs as well.
+	if err := linkOrderItemPublicIDs(ctx, tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// linkOrderItemPublicIDs fills in the public IDs missing from the book
+// snapshots of order items.
+func linkOrderItemPublicIDs(ctx context.Context, q sqlQuerier) error {
+	publicIDs := make(map[string]map[int]string)
+	for _, table := range []string{"books", "authors", "series"} {
+		rows, err := q.QueryContext(ctx, `SELECT id, public_id FROM `+table+` WHERE public_id IS NOT NULL`)
+		if err != nil {
+			return err
+		}
+		publicIDs[table] = make(map[int]string)
+		for rows.Next() {
+			var (
+				id       int
+				publicID string
+			)
+			if err := rows.Scan(&id, &publicID); err != nil {
+				rows.Close()
+				return err
+			}
+			publicIDs[table][id] = publicID
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+	lookup := func(entity string, id int) string {
+		return publicIDs[entity][id]
+	}
+
+	type itemSnapshot struct {
+		orderID, position int
+		book              models.Book
+	}
+	rows, err := q.QueryContext(ctx, `SELECT order_id, position, book FROM order_items`)
+	if err != nil {
+		return err
+	}
+	var linked []itemSnapshot
+	for rows.Next() {
+		var (
+			item     itemSnapshot
+			snapshot string
+		)
+		if err := rows.Scan(&item.orderID, &item.position, &snapshot); err != nil {
+			rows.Close()
+			return err
+		}
+		if err := json.Unmarshal([]byte(snapshot), &item.book); err != nil {
+			rows.Close()
+			return err
+		}
+		var changed bool
+		if item.book, changed = linkBookPublicIDs(item.book, lookup); changed {
+			linked = append(linked, item)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, item := range linked {
+		snapshot, err := json.Marshal(item.book)
+		if err != nil {
+			return err
+		}
+		if _, err := q.ExecContext(ctx,
+			`UPDATE order_items SET book = ? WHERE order_id = ? AND position = ?`,
+			string(snapshot), item.orderID, item.position); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) ResolvePublicID(ctx context.Context, entity, publicID string) (int, bool) {
+	known := false
+	for _, table := range sqlitePublicIDTables {
+		if table == entity {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return 0, false
+	}
+
+	var id int
+	if err := s.q.QueryRowContext(ctx,
+		`SELECT id FROM `+entity+` WHERE public_id = ?`, publicID).Scan(&id); err != nil {
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,126 @@
+package store
+
+import (
+	"Book-Store/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+)
+
+func (s *SQLiteStore) CreateSeries(ctx context.Context, series models.Series) (models.Series, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return models.Series{}, err
+	}
+	defer tx.Rollback()
+
+	series.ID, err = nextSQLiteID(ctx, tx, "series")
+	if err != nil {
+		return models.Series{}, err
+	}
+
+	publicID := s.publicID()
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO series (id, public_id, name, description) VALUES (?, ?, ?, ?)`,
+		series.ID, publicID, series.Name, series.Description); err != nil {
+		return models.Series{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Series{}, err
+	}
+
+	series.PublicID, _ = publicID.(string)
+	return series, nil
+}
+
+func (s *SQLiteStore) GetSeries(ctx context.Context, id int) (models.Series, error) {
+	var series models.Series
+	err := s.q.QueryRowContext(ctx,
+		`SELECT id, COALESCE(public_id, ''), name, description FROM series WHERE id = ?`, id).
+		Scan(&series.ID, &series.PublicID, &series.Name, &series.Description)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Series{}, errors.New("Series not found")
+	}
+	if err != nil {
+		return models.Series{}, err
+	}
+	return series, nil
+}
+
+func (s *SQLiteStore) ListSeries(ctx context.Context, page models.PageRequest) (models.Page[models.Series], error) {
+	total, err := s.countRows(ctx, "series", nil)
+	if err != nil {
+		return models.Page[models.Series]{}, err
+	}
+
+	p, err := pageQuery(seriesOrder, page, nil, nil)
+	if err != nil {
+		return models.Page[models.Series]{}, err
+	}
+
+	rows, err := s.q.QueryContext(ctx,
+		`SELECT id, COALESCE(public_id, ''), name, description FROM series`+p.where+` ORDER BY id`+p.limit,
+		p.args...)
+	if err != nil {
+		return models.Page[models.Series]{}, err
+	}
+	defer rows.Close()
+
+	series := make([]models.Series, 0)
+	for rows.Next() {
+		var ser models.Series
+		if err := rows.Scan(&ser.ID, &ser.PublicID, &ser.Name, &ser.Description); err != nil {
+			return models.Page[models.Series]{}, err
+		}
+		series = append(series, ser)
+	}
+	if err := rows.Err(); err != nil {
+		return models.Page[models.Series]{}, err
+	}
+	return seriesOrder.PageFrom(series, total, page.Limit), nil
+}
+
+func (s *SQLiteStore) UpdateSeries(ctx context.Context, id int, series models.Series) (models.Series, error) {
+	res, err := s.q.ExecContext(ctx,
+		`UPDATE series SET name = ?, description = ? WHERE id = ?`, series.Name, series.Description, id)
+	if err != nil {
+		return models.Series{}, err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.Series{}, errors.New("Series not found")
+	}
+
+	return s.GetSeries(ctx, id)
+}
+
+func (s *SQLiteStore) DeleteSeries(ctx context.Context, id int) error {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM series WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.New("Series not found")
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE books SET series_id = NULL, series_position = 0 WHERE series_id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) ListSeriesBooks(ctx context.Context, id int) ([]models.Book, error) {
+	if _, err := s.GetSeries(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.queryBooks(ctx, sqliteBookSelect+` WHERE b.series_id = ? ORDER BY b.series_position, b.id`, id)
+}
@@ -0,0 +1,222 @@
+package store
+
+import (
+	"Book-Store/internal/models"
+	"Book-Store/internal/pagination"
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteTimeFormat is fixed-width UTC so stored timestamps sort and compare
+// correctly as plain strings.
+const sqliteTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// sqlQuerier is satisfied by both *sql.DB and *sql.Tx, so the same store code
+// runs standalone or inside WithTx.
+type sqlQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type SQLiteStore struct {
+	db *sql.DB
+	q  sqlQuerier
+
+	publicIDs bool
+
+	// Set on the view handed to WithTx callbacks.
+	tx         *sql.Tx
+	savepoints int
+}
+
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	// Write transactions take the lock up front so concurrent orders queue on
+	// busy_timeout instead of failing when a reader upgrades.
+	params.Set("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrateSQLite(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	s := &SQLiteStore{db: db, q: db}
+	if err := s.syncBookSearch(context.Background()); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// sqliteTx is a unit of work started by begin. Outside WithTx it is a real
+// transaction; inside it is a savepoint, so a failing store call undoes only
+// its own changes and leaves the decision to the WithTx callback.
+type sqliteTx struct {
+	sqlQuerier
+	commit   func() error
+	rollback func() error
+	done     bool
+}
+
+func (t *sqliteTx) Commit() error {
+	t.done = true
+	return t.commit()
+}
+
+func (t *sqliteTx) Rollback() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+	return t.rollback()
+}
+
+func (s *SQLiteStore) begin(ctx context.Context) (*sqliteTx, error) {
+	if s.tx == nil {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &sqliteTx{sqlQuerier: tx, commit: tx.Commit, rollback: tx.Rollback}, nil
+	}
+
+	s.savepoints++
+	name := fmt.Sprintf("sp_%d", s.savepoints)
+	if _, err := s.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, err
+	}
+	return &sqliteTx{
+		sqlQuerier: s.tx,
+		commit: func() error {
+			_, err := s.tx.ExecContext(ctx, "RELEASE "+name)
+			return err
+		},
+		rollback: func() error {
+			if _, err := s.tx.ExecContext(ctx, "ROLLBACK TO "+name); err != nil {
+				return err
+			}
+			_, err := s.tx.ExecContext(ctx, "RELEASE "+name)
+			return err
+		},
+	}, nil
+}
+
+// WithTx runs fn inside one SQLite transaction, committed only if fn returns
+// nil. Called on the view handed to fn, it runs fn in a savepoint instead.
+func (s *SQLiteStore) WithTx(ctx context.Context, fn func(tx Stores) error) error {
+	if s.tx != nil {
+		return s.savepoint(ctx, fn)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := fn(&SQLiteStore{db: s.db, q: tx, tx: tx, publicIDs: s.publicIDs}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) savepoint(ctx context.Context, fn func(tx Stores) error) error {
+	sp, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			sp.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := fn(s); err != nil {
+		sp.Rollback()
+		return err
+	}
+	return sp.Commit()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeFormat)
+}
+
+func parseTime(s string) time.Time {
+	t, err := time.Parse(sqliteTimeFormat, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// sqlitePage holds the SQL for one page of an ID-ordered listing.
+type sqlitePage struct {
+	where string
+	args  []any
+	limit string
+}
+
+// pageQuery builds a page of a listing filtered by conds. The cursor becomes
+// an id > ? condition and one row beyond the limit is read so PageFrom can
+// tell whether another page follows.
+func pageQuery[T any](order pagination.Order[T], page models.PageRequest, conds []string, args []any) (sqlitePage, error) {
+	if page.Cursor != "" {
+		after, err := order.AfterID(page.Cursor)
+		if err != nil {
+			return sqlitePage{}, err
+		}
+		conds = append(conds, "id > ?")
+		args = append(args, after)
+	}
+
+	var p sqlitePage
+	if len(conds) > 0 {
+		p.where = " WHERE " + strings.Join(conds, " AND ")
+	}
+	p.args = args
+	if page.Limit > 0 {
+		p.limit = fmt.Sprintf(" LIMIT %d", page.Limit+1)
+	}
+	return p, nil
+}
+
+// countRows counts the rows of table matching conds.
+func (s *SQLiteStore) countRows(ctx context.Context, table string, conds []string, args ...any) (int, error) {
+	query := `SELECT COUNT(*) FROM ` + table
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	var count int
+	err := s.q.QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
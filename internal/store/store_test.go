@@ -0,0 +1,972 @@
+package store_test
+
+import (
+	"Book-Store/internal/models"
+	"Book-Store/internal/store"
+	"Book-Store/internal/store/storetest"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// forEachBackend runs test against an empty store of every backend.
+func forEachBackend(t *testing.T, test func(t *testing.T, s store.Store)) {
+	for _, backend := range storetest.Backends {
+		t.Run(backend.Name, func(t *testing.T) {
+			test(t, backend.Open(t))
+		})
+	}
+}
+
+func mustAuthor(t *testing.T, s store.Store, first, last string) models.Author {
+	t.Helper()
+	a, err := s.CreateAuthor(context.Background(), models.Author{FirstName: first, LastName: last})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func mustBook(t *testing.T, s store.Store, book models.Book) models.Book {
+	t.Helper()
+	b, err := s.CreateBook(context.Background(), book)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func mustCustomer(t *testing.T, s store.Store, email string) models.Customer {
+	t.Helper()
+	c, err := s.CreateCustomer(context.Background(), models.Customer{Name: email, Email: email})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// searchBooks returns the books found by a search, leaving out its facets.
+func searchBooks(ctx context.Context, s store.Store, criteria models.SearchCriteria) ([]models.Book, error) {
+	result, err := s.SearchBooks(ctx, criteria)
+	return result.Books, err
+}
+
+func TestBooks(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s store.Store) {
+		ctx := context.Background()
+		author := mustAuthor(t, s, "Ursula", "Le Guin")
+
+		if _, err := s.CreateBook(ctx, models.Book{Title: "Orphan", Author: models.Author{ID: author.ID + 100}}); err == nil {
+			t.Error("CreateBook() with an unknown author succeeded")
+		}
+
+		book := mustBook(t, s, models.Book{
+			Title:  "The Dispossessed",
+			Author: models.Author{ID: author.ID},
+			Genres: []string{"sf"},
+			Price:  12.5,
+			Stock:  3,
+		})
+		mustBook(t, s, models.Book{Title: "A Wizard of Earthsea", Author: models.Author{ID: author.ID}, Genres: []string{"fantasy"}})
+
+		got, err := s.GetBook(ctx, book.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Title != "The Dispossessed" || got.Author.LastName != "Le Guin" || got.Price != 12.5 || got.Stock != 3 {
+			t.Errorf("GetBook() = %+v", got)
+		}
+
+		found, err := searchBooks(ctx, s, models.SearchCriteria{Title: "dispossessed"})
+		if err != nil || len(found) != 1 || found[0].ID != book.ID {
+			t.Errorf("SearchBooks(title) = %+v, %v", found, err)
+		}
+		found, err = searchBooks(ctx, s, models.SearchCriteria{Author: "guin"})
+		if err != nil || len(found) != 2 {
+			t.Errorf("SearchBooks(author) found %d books, %v; want 2", len(found), err)
+		}
+		if n := len(s.GetBooksPerGenre("fantasy")); n != 1 {
+			t.Errorf("GetBooksPerGenre() found %d books, want 1", n)
+		}
+
+		updated, err := s.UpdateBook(ctx, book.ID, models.Book{Title: "The Dispossessed", Author: models.Author{ID: author.ID}, Stock: 0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.Stock != 0 || len(s.OutOfStock()) == 0 {
+			t.Errorf("UpdateBook() = %+v, want it out of stock", updated)
+		}
+
+		if err := s.DeleteBook(ctx, book.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.GetBook(ctx, book.ID); err == nil || s.BookExists(book.ID) {
+			t.Error("deleted book is still found")
+		}
+		if n := s.BooksCount(); n != 1 {
+			t.Errorf("BooksCount() = %d, want 1", n)
+		}
+	})
+}
+
+func TestSearchFacets(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s store.Store) {
+		ctx := context.Background()
+		leGuin := mustAuthor(t, s, "Ursula", "Le Guin")
+		herbert := mustAuthor(t, s, "Frank", "Herbert")
+		mustBook(t, s, models.Book{Title: "The Dispossessed", Author: models.Author{ID: leGuin.ID}, Genres: []string{"sf"}, Price: 8, Stock: 2})
+		mustBook(t, s, models.Book{Title: "A Wizard of Earthsea", Author: models.Author{ID: leGuin.ID}, Genres: []string{"fantasy"}, Price: 12})
+		mustBook(t, s, models.Book{Title: "Dune", Author: models.Author{ID: herbert.ID}, Genres: []string{"sf"}, Price: 30, Stock: 1})
+
+		result, err := s.SearchBooks(ctx, models.SearchCriteria{Genres: []string{"sf", "fantasy"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Books) != 3 {
+			t.Errorf("SearchBooks(any of two genres) found %d books, want 3", len(result.Books))
+		}
+
+		counts := func(facets []models.FacetCount) map[string]int {
+			m := make(map[string]int)
+			for _, f := range facets {
+				m[f.Value] = f.Count
+			}
+			return m
+		}
+		if got := counts(result.Facets.Genres); got["sf"] != 2 || got["fantasy"] != 1 {
+			t.Errorf("genre facets = %v", result.Facets.Genres)
+		}
+		authors := 0
+		for _, f := range result.Facets.Authors {
+			authors += f.Count
+			if f.Label == "" {
+				t.Errorf("author facet %+v has no label", f)
+			}
+		}
+		if authors != 3 || len(result.Facets.Authors) != 2 {
+			t.Errorf("author facets = %v", result.Facets.Authors)
+		}
+
+		result, err = s.SearchBooks(ctx, models.SearchCriteria{Genres: []string{"sf"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Books) != 2 {
+			t.Errorf("SearchBooks(genre) found %d books, want 2", len(result.Books))
+		}
+		if got := counts(result.Facets.Genres); len(got) != 1 || got["sf"] != 2 {
+			t.Errorf("genre facets of a genre search = %v, want only the matches counted", result.Facets.Genres)
+		}
+
+		result, err = s.SearchBooks(ctx, models.SearchCriteria{AuthorID: &leGuin.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Books) != 2 {
+			t.Errorf("SearchBooks(author_id) found %d books, want 2", len(result.Books))
+		}
+		if got := counts(result.Facets.Authors); len(got) != 1 || got[strconv.Itoa(leGuin.ID)] != 2 {
+			t.Errorf("author facets of an author search = %v, want the author by ID", result.Facets.Authors)
+		}
+
+		// With public IDs on, the author facet offers the public ID to pass
+		// back as author_id.
+		if err := s.EnablePublicIDs(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if leGuin, err = s.GetAuthor(ctx, leGuin.ID); err != nil {
+			t.Fatal(err)
+		}
+		result, err = s.SearchBooks(ctx, models.SearchCriteria{AuthorID: &leGuin.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := counts(result.Facets.Authors); len(got) != 1 || got[leGuin.PublicID] != 2 {
+			t.Errorf("author facets with public IDs = %v, want %q", result.Facets.Authors, leGuin.PublicID)
+		}
+	})
+}
+
+func TestSearchSort(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s store.Store) {
+		ctx := context.Background()
+		leGuin := mustAuthor(t, s, "Ursula", "Le Guin")
+		herbert := mustAuthor(t, s, "Frank", "Herbert")
+		dispossessed := mustBook(t, s, models.Book{Title: "The Dispossessed", Author: models.Author{ID: leGuin.ID}, Price: 10, Stock: 9})
+		earthsea := mustBook(t, s, models.Book{Title: "A Wizard of Earthsea", Author: models.Author{ID: leGuin.ID}, Price: 10, Stock: 9})
+		dune := mustBook(t, s, models.Book{Title: "Dune", Author: models.Author{ID: herbert.ID}, Price: 20, Stock: 9})
+		customer := mustCustomer(t, s, "shevek@example.com")
+
+		// Only completed orders count towards popularity.
+		for _, sale := range []struct {
+			book     models.Book
+			quantity int
+			complete bool
+		}{
+			{earthsea, 2, true},
+			{dispossessed, 1, true},
+			{dispossessed, 5, false},
+		} {
+			o, err := s.CreateOrder(ctx, models.Order{
+				Customer: models.Customer{ID: customer.ID},
+				Items:    []models.OrderItem{{Book: models.Book{ID: sale.book.ID}, Quantity: sale.quantity}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sale.complete {
+				if _, err := s.CompleteOrder(ctx, o.ID); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+
+		tests := []struct {
+			sort string
+			want []int
+		}{
+			{"-price,title", []int{dune.ID, earthsea.ID, dispossessed.ID}},
+			{"author,-title", []int{dune.ID, dispossessed.ID, earthsea.ID}},
+			{"-popularity,title", []int{earthsea.ID, dispossessed.ID, dune.ID}},
+		}
+		for _, tt := range tests {
+			keys, err := models.ParseSort(tt.sort)
+			if err != nil {
+				t.Fatal(err)
+			}
+			found, err := searchBooks(ctx, s, models.SearchCriteria{Sort: keys})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int
+			for _, b := range found {
+				got = append(got, b.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("sort=%s: IDs = %v, want %v", tt.sort, got, tt.want)
+			}
+		}
+	})
+}
+
+func TestFullTextSearch(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s store.Store) {
+		ctx := context.Background()
+		leGuin := mustAuthor(t, s, "Ursula", "Le Guin")
+		herbert := mustAuthor(t, s, "Frank", "Herbert")
+
+		inTitle := mustBook(t, s, models.Book{Title: "Dune", Author: models.Author{ID: herbert.ID}, Genres: []string{"sf"}})
+		inGenre := mustBook(t, s, models.Book{Title: "The Dispossessed", Author: models.Author{ID: leGuin.ID}, Genres: []string{"dune"}})
+		mustBook(t, s, models.Book{Title: "A Wizard of Earthsea", Author: models.Author{ID: leGuin.ID}, Genres: []string{"fantasy"}})
+
+		found, err := searchBooks(ctx, s, models.SearchCriteria{Query: "dunes"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(found) != 2 || found[0].ID != inTitle.ID || found[1].ID != inGenre.ID {
+			t.Fatalf("SearchBooks(q) = %+v, want the title match ranked above the genre match", found)
+		}
+		if found[0].Score <= found[1].Score || found[1].Score <= 0 {
+			t.Errorf("scores = %v, %v, want positive and descending", found[0].Score, found[1].Score)
+		}
+
+		// Renaming a book updates what it is found by.
+		if _, err := s.UpdateBook(ctx, inTitle.ID, models.Book{Title: "Children of Dune", Author: models.Author{ID: herbert.ID}}); err != nil {
+			t.Fatal(err)
+		}
+		found, err = searchBooks(ctx, s, models.SearchCriteria{Query: "children"})
+		if err != nil || len(found) != 1 || found[0].ID != inTitle.ID {
+			t.Errorf("SearchBooks(q) after renaming = %+v, %v", found, err)
+		}
+	})
+}
+
+func TestTypoTolerantSearchAndSuggestions(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s store.Store) {
+		ctx := context.Background()
+		leGuin := mustAuthor(t, s, "Ursula", "Le Guin")
+		book := mustBook(t, s, models.Book{Title: "The Dispossessed", Author: models.Author{ID: leGuin.ID}})
+
+		found, err := searchBooks(ctx, s, models.SearchCriteria{Query: "dispossesed"})
+		if err != nil || len(found) != 1 || found[0].ID != book.ID {
+			t.Errorf("SearchBooks(misspelt q) = %+v, %v", found, err)
+		}
+
+		suggestions, err := s.SuggestBooks(ctx, "dispo", 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := models.Suggestion{Type: models.SuggestionTitle, ID: book.ID, Text: "The Dispossessed"}
+		if len(suggestions) != 1 || suggestions[0] != want {
+			t.Errorf("SuggestBooks(title prefix) = %+v, want %+v", suggestions, want)
+		}
+		suggestions, err = s.SuggestBooks(ctx, "ursu", 5)
+		if err != nil || len(suggestions) != 1 || suggestions[0].Type != models.SuggestionAuthor || suggestions[0].ID != leGuin.ID {
+			t.Errorf("SuggestBooks(author prefix) = %+v, %v", suggestions, err)
+		}
+
+		if err := s.DeleteBook(ctx, book.ID); err != nil {
+			t.Fatal(err)
+		}
+		suggestions, err = s.SuggestBooks(ctx, "dispo", 5)
+		if err != nil || len(suggestions) != 0 {
+			t.Errorf("SuggestBooks() after deleting the book = %+v, %v", suggestions, err)
+		}
+	})
+}
+
+func TestScanBooks(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s store.Store) {
+		ctx := context.Background()
+		author := mustAuthor(t, s, "Ursula", "Le Guin")
+		var fantasy []int
+		for i := range 8 {
+			genre := "sf"
+			if i%4 != 0 {
+				genre = "fantasy"
+			}
+			book := mustBook(t, s, models.Book{Title: fmt.Sprintf("Book %d", i), Author: author, Genres: []string{genre}})
+			if genre == "fantasy" {
+				fantasy = append(fantasy, book.ID)
+			}
+		}
+
+		// Sort, Limit and Cursor don't apply to a scan.
+		criteria := models.SearchCriteria{
+			Genres: []string{"fantasy"},
+			Sort:   []models.SortKey{{Field: models.SortTitle, Desc: true}},
+			Limit:  1,
+			Cursor: "not a cursor",
+		}
+		var got []int
+		var batches []int
+		err := s.ScanBooks(ctx, criteria, 4, func(books []models.Book) error {
+			batches = append(batches, len(books))
+			for _, book := range books {
+				got = append(got, book.ID)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(got, fantasy) {
+			t.Errorf("ScanBooks() = %v, want %v", got, fantasy)
+		}
+		if !slices.Equal(batches, []int{4, 2}) {
+			t.Errorf("ScanBooks() batches = %v, want [4 2]", batches)
+		}
+
+		calls := 0
+		err = s.ScanBooks(ctx, models.SearchCriteria{}, 2, func(books []models.Book) error {
+			calls++
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) || calls != 1 {
+			t.Errorf("ScanBooks() with a failing fn = %v after %d calls, want %v after 1", err, calls, errAbort)
+		}
+	})
+}
+
+func TestListingPages(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s store.Store) {
+		ctx := context.Background()
+		var want []int
+		for _, name := range []string{"Le Guin", "Herbert", "Banks", "Butler", "Leckie"} {
+			want = append(want, mustAuthor(t, s, "A", name).ID)
+		}
+
+		var got []int
+		page := models.PageRequest{Limit: 2}
+		for pages := 0; ; pages++ {
+			if pages == len(want) {
+				t.Fatal("ListAuthors() never ran out of pages")
+			}
+			result, err := s.ListAuthors(ctx, page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Total != len(want) {
+				t.Errorf("Total = %d, want %d", result.Total, len(want))
+			}
+			for _, a := range result.Items {
+				got = append(got, a.ID)
+			}
+			if result.NextCursor == "" {
+				break
+			}
+			page.Cursor = result.NextCursor
+
+			// Records added mid-listing don't shift the pages already read.
+			if pages == 0 {
+				want = append(want, mustAuthor(t, s, "A", "Jemisin").ID)
+			}
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("paged IDs = %v, want %v", got, want)
+		}
+
+		if _, err := s.ListAuthors(ctx, models.PageRequest{Limit: 2, Cursor: "garbage"}); err == nil {
+			t.Error("ListAuthors() with a bad cursor succeeded")
+		}
+	})
+}
+
+func TestBookISBN(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s store.Store) {
+		ctx := context.Background()
+		author := mustAuthor(t, s, "Frank", "Herbert")
+		dune := mustBook(t, s, models.Book{Title: "Dune", Author: models.Author{ID: author.ID}, ISBN: "9780441013593"})
+		messiah := mustBook(t, s, models.Book{Title: "Dune Messiah", Author: models.Author{ID: author.ID}})
+
+		got, err := s.GetBookByISBN(ctx, "9780441013593")
+		if err != nil || got.ID != dune.ID {
+			t.Errorf("GetBookByISBN() = %+v, %v, want %q", got, err, dune.Title)
+		}
+
+		if _, err := s.CreateBook(ctx, models.Book{Title: "Copy", Author: models.Author{ID: author.ID}, ISBN: "9780441013593"}); !errors.Is(err, store.ErrDuplicateISBN) {
+			t.Errorf("CreateBook() with a taken ISBN: error = %v, want %v", err, store.ErrDuplicateISBN)
+		}
+		if _, err := s.UpdateBook(ctx, messiah.ID, models.Book{Title: "Dune Messiah", Author: models.Author{ID: author.ID}, ISBN: "9780441013593"}); !errors.Is(err, store.ErrDuplicateISBN) {
+			t.Errorf("UpdateBook() to a taken ISBN: error = %v, want %v", err, store.ErrDuplicateISBN)
+		}
+		// Books without an ISBN don't clash with each other.
+		mustBook(t, s, models.Book{Title: "Children of Dune", Author: models.Author{ID: author.ID}})
+
+		if err := s.DeleteBook(ctx, dune.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.GetBookByISBN(ctx, "9780441013593"); err == nil {
+			t.Error("GetBookByISBN() found a deleted book")
+		}
+	})
+}
+
+func TestContributors(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s store.Store) {
+		ctx := context.Background()
+		lem := mustAuthor(t, s, "Stanisław", "Lem")
+		kandel := mustAuthor(t, s, "Michael", "Kandel")
+		mustBook(t, s, models.Book{Title: "Solaris", Author: models.Author{ID: lem.ID}})
+		cyberiad := mustBook(t, s, models.Book{Title: "The Cyberiad", Contributors: []models.Contributor{
+			{Author: models.Author{ID: kandel.ID}, Role: models.ContributorTranslator},
+			{Author: models.Author{ID: lem.ID}},
+		}})
+
+		got, err := s.GetBook(ctx, cyberiad.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Author.ID != lem.ID || got.Author.LastName != "Lem" {
+			t.Errorf("Author = %+v, want the first credited author", got.Author)
+		}
+		if len(got.Contributors) != 2 || got.Contributors[0].Author.LastName != "Kandel" || got.Contributors[0].Role != models.ContributorTranslator {
+			t.Errorf("Contributors = %+v", got.Contributors)
+		}
+
+		for _, tt := range []struct {
+			author models.Author
+			want   int
+		}{{lem, 2}, {kandel, 1}} {
+			page, err := s.ListAuthorBooks(ctx, tt.author.ID, models.PageRequest{})
+			if err != nil || len(page.Items) != tt.want {
+				t.Errorf("ListAuthorBooks(%s) = %d books, %v; want %d", tt.author.LastName, len(page.Items), err, tt.want)
+			}
+		}
+
+		if _, err := s.CreateBook(ctx, models.Book{Title: "Twice", Contributors: []models.Contributor{
+			{Author: models.Author{ID: lem.ID}}, {Author: models.Author{ID: lem.ID}, Role: models.ContributorAuthor},
+		}}); err == nil {
+			t.Error("CreateBook() crediting an author twice in one role succeeded")
+		}
+	})
+}
+
+func TestAuthorChangesReachBooks(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s store.Store) {
+		ctx := context.Background()
+		lem := mustAuthor(t, s, "Stanislaw", "Lem")
+		kandel := mustAuthor(t, s, "Michael", "Kandel")
+		solaris := mustBook(t, s, models.Book{Title: "Solaris", Author: models.Author{ID: lem.ID}})
+		cyberiad := mustBook(t, s, models.Book{Title: "The Cyberiad", Contributors: []models.Contributor{
+			{Author: models.Author{ID: lem.ID}},
+			{Author: models.Author{ID: kandel.ID}, Role: models.ContributorTranslator},
+		}})
+
+		if _, err := s.UpdateAuthor(ctx, lem.ID, models.Author{FirstName: "Stanisław", LastName: "Lem"}); err != nil {
+			t.Fatal(err)
+		}
+		got, err := s.GetBook(ctx, cyberiad.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Author.FirstName != "Stanisław" || got.Contributors[0].Author.FirstName != "Stanisław" {
+			t.Errorf("book after renaming its author = %+v", got)
+		}
+
+		if err := s.DeleteAuthor(ctx, lem.ID, models.AuthorDeletion{}); !errors.Is(err, store.ErrAuthorHasBooks) {
+			t.Errorf("DeleteAuthor() of a credited author: error = %v, want %v", err, store.ErrAuthorHasBooks)
+		}
+
+		// Reassigning moves the translator's credit onto the remaining author.
+		if err := s.DeleteAuthor(ctx, kandel.ID, models.AuthorDeletion{ReassignTo: &lem.ID}); err != nil {
+			t.Fatal(err)
+		}
+		got, err = s.GetBook(ctx, cyberiad.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Contributors) != 2 || got.Contributors[1].Author.ID != lem.ID || got.Contributors[1].Role != models.ContributorTranslator {
+			t.Errorf("contributors after reassigning = %+v", got.Contributors)
+		}
+
+		// Cascading deletes the books left without an author.
+		if err := s.DeleteAuthor(ctx, lem.ID, models.AuthorDeletion{Cascade: true}); err != nil {
+			t.Fatal(err)
+		}
+		for _, b := range []models.Book{solaris, cyberiad} {
+			if s.BookExists(b.ID) {
+				t.Errorf("%q survived the deletion of its only author", b.Title)
+			}
+		}
+	})
+}
+
+func TestSeries(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s store.Store) {
+		ctx := context.Background()
+		author := mustAuthor(t, s, "Ursula", "Le Guin")
+		series, err := s.CreateSeries(ctx, models.Series{Name: "Earthsea"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		inSeries := func(position float64) *models.SeriesEntry {
+			return &models.SeriesEntry{ID: series.ID, Position: position}
+		}
+
+		tombs := mustBook(t, s, models.Book{Title: "The Tombs of Atuan", Author: models.Author{ID: author.ID}, Series: inSeries(2)})
+		wizard := mustBook(t, s, models.Book{Title: "A Wizard of Earthsea", Author: models.Author{ID: author.ID}, Series: inSeries(1)})
+		novella := mustBook(t, s, models.Book{Title: "The Word of Unbinding", Author: models.Author{ID: author.ID}, Series: inSeries(1.5)})
+		mustBook(t, s, models.Book{Title: "The Dispossessed", Author: models.Author{ID: author.ID}})
+
+		if _, err := s.CreateBook(ctx, models.Book{Title: "Lost", Author: models.Author{ID: author.ID}, Series: &models.SeriesEntry{ID: series.ID + 100}}); err == nil {
+			t.Error("CreateBook() in an unknown series succeeded")
+		}
+
+		books, err := s.ListSeriesBooks(ctx, series.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []int
+		for _, b := range books {
+			got = append(got, b.ID)
+		}
+		if want := []int{wizard.ID, novella.ID, tombs.ID}; !slices.Equal(got, want) {
+			t.Errorf("ListSeriesBooks() = %v, want reading order %v", got, want)
+		}
+
+		found, err := searchBooks(ctx, s, models.SearchCriteria{Series: &series.ID})
+		if err != nil || len(found) != 3 {
+			t.Errorf("SearchBooks(series) found %d books, %v; want 3", len(found), err)
+		}
+
+		if _, err := s.UpdateSeries(ctx, series.ID, models.Series{Name: "The Earthsea Cycle"}); err != nil {
+			t.Fatal(err)
+		}
+		if b, err := s.GetBook(ctx, wizard.ID); err != nil || b.Series == nil || b.Series.Name != "The Earthsea Cycle" || b.Series.Position != 1 {
+			t.Errorf("book after renaming its series = %+v, %v", b.Series, err)
+		}
+
+		if err := s.DeleteSeries(ctx, series.ID); err != nil {
+			t.Fatal(err)
+		}
+		if b, err := s.GetBook(ctx, tombs.ID); err != nil || b.Series != nil {
+			t.Errorf("book after deleting its series = %+v, %v, want it standalone", b.Series, err)
+		}
+	})
+}
+
+func TestBookCover(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s store.Store) {
+		ctx := context.Background()
+		author := mustAuthor(t, s, "Octavia E.", "Butler")
+		book := mustBook(t, s, models.Book{Title: "Kindred", Author: models.Author{ID: author.ID}})
+
+		cover := models.BookCover{Format: "png", Width: 600, Height: 900, UpdatedAt: time.Now().UTC().Truncate(time.Millisecond)}
+		if _, err := s.SetBookCover(ctx, book.ID, &cover); err != nil {
+			t.Fatal(err)
+		}
+		// Editing the book keeps its cover.
+		if _, err := s.UpdateBook(ctx, book.ID, models.Book{Title: "Kindred", Author: models.Author{ID: author.ID}, Price: 9}); err != nil {
+			t.Fatal(err)
+		}
+		got, err := s.GetBook(ctx, book.ID)
+		if err != nil || got.Cover == nil || got.Cover.Width != 600 || !got.Cover.UpdatedAt.Equal(cover.UpdatedAt) {
+			t.Errorf("cover after UpdateBook() = %+v, %v, want %+v", got.Cover, err, cover)
+		}
+
+		if _, err := s.SetBookCover(ctx, book.ID, nil); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := s.GetBook(ctx, book.ID); err != nil || got.Cover != nil {
+			t.Errorf("cover after removing it = %+v, %v", got.Cover, err)
+		}
+		if _, err := s.SetBookCover(ctx, book.ID+100, &cover); err == nil {
+			t.Error("SetBookCover() of an unknown book succeeded")
+		}
+	})
+}
+
+// buy places and completes an order of one copy of book for customer.
+func buy(t *testing.T, s store.Store, customer models.Customer, book models.Book) {
+	t.Helper()
+	ctx := context.Background()
+	o, err := s.CreateOrder(ctx, models.Order{
+		Customer: models.Customer{ID: customer.ID},
+		Items:    []models.OrderItem{{Book: models.Book{ID: book.ID}, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CompleteOrder(ctx, o.ID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReviews(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s store.Store) {
+		ctx := context.Background()
+		author := mustAuthor(t, s, "Ursula", "Le Guin")
+		book := mustBook(t, s, models.Book{Title: "The Dispossessed", Author: models.Author{ID: author.ID}, Stock: 10})
+		unrated := mustBook(t, s, models.Book{Title: "The Lathe of Heaven", Author: models.Author{ID: author.ID}, Stock: 10})
+		shevek := mustCustomer(t, s, "shevek@example.com")
+		takver := mustCustomer(t, s, "takver@example.com")
+
+		review := func(c models.Customer, rating int) (models.Review, error) {
+			return s.CreateReview(ctx, models.Review{BookID: book.ID, CustomerID: c.ID, Rating: rating, Title: "Anarres"})
+		}
+		rating := func() models.RatingSummary {
+			t.Helper()
+			b, err := s.GetBook(ctx, book.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return b.Rating
+		}
+
+		if _, err := review(shevek, 4); !errors.Is(err, store.ErrReviewNotAllowed) {
+			t.Errorf("review without a purchase: error = %v, want %v", err, store.ErrReviewNotAllowed)
+		}
+
+		buy(t, s, shevek, book)
+		buy(t, s, takver, book)
+		first, err := review(shevek, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first.CustomerName != shevek.Name {
+			t.Errorf("CustomerName = %q, want %q", first.CustomerName, shevek.Name)
+		}
+		if _, err := review(shevek, 5); !errors.Is(err, store.ErrDuplicateReview) {
+			t.Errorf("second review: error = %v, want %v", err, store.ErrDuplicateReview)
+		}
+		if _, err := review(takver, 1); err != nil {
+			t.Fatal(err)
+		}
+
+		want := models.RatingSummary{Average: 2.5, Count: 2, Histogram: [models.MaxRating]int{1, 0, 0, 1, 0}}
+		if got := rating(); got != want {
+			t.Errorf("rating = %+v, want %+v", got, want)
+		}
+
+		if _, err := s.UpdateReview(ctx, first.ID, models.Review{Rating: 5, Title: "Anarres"}); err != nil {
+			t.Fatal(err)
+		}
+		if got := rating(); got.Average != 3 || got.Histogram != [models.MaxRating]int{1, 0, 0, 0, 1} {
+			t.Errorf("rating after an update = %+v", got)
+		}
+
+		reviews, err := s.ListBookReviews(ctx, book.ID, models.PageRequest{})
+		if err != nil || len(reviews.Items) != 2 {
+			t.Errorf("ListBookReviews() = %d reviews, %v; want 2", len(reviews.Items), err)
+		}
+
+		minRating := 3.0
+		found, err := searchBooks(ctx, s, models.SearchCriteria{MinRating: &minRating})
+		if err != nil || len(found) != 1 || found[0].ID != book.ID {
+			t.Errorf("SearchBooks(min_rating) = %+v, %v", found, err)
+		}
+		keys, err := models.ParseSort("-rating")
+		if err != nil {
+			t.Fatal(err)
+		}
+		found, err = searchBooks(ctx, s, models.SearchCriteria{Sort: keys})
+		if err != nil || len(found) != 2 || found[0].ID != book.ID || found[1].ID != unrated.ID {
+			t.Errorf("SearchBooks(sort=-rating) = %+v, %v", found, err)
+		}
+
+		if err := s.DeleteReview(ctx, first.ID); err != nil {
+			t.Fatal(err)
+		}
+		if got := rating(); got.Count != 1 || got.Average != 1 {
+			t.Errorf("rating after deleting a review = %+v", got)
+		}
+	})
+}
+
+func TestCustomers(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s store.Store) {
+		ctx := context.Background()
+		customer := mustCustomer(t, s, "shevek@example.com")
+
+		if _, err := s.CreateCustomer(ctx, models.Customer{Email: "shevek@example.com"}); err == nil {
+			t.Error("CreateCustomer() with a taken email succeeded")
+		}
+
+		got, err := s.GetCustomerByEmail(ctx, "shevek@example.com")
+		if err != nil || got.ID != customer.ID {
+			t.Errorf("GetCustomerByEmail() = %+v, %v", got, err)
+		}
+
+		if _, err := s.UpdateCustomer(ctx, customer.ID, models.Customer{Name: "Shevek", Role: models.RoleStaff}); err != nil {
+			t.Fatal(err)
+		}
+		got, err = s.GetCustomer(ctx, customer.ID)
+		if err != nil || got.Name != "Shevek" || got.Role != models.RoleStaff || got.Email != "shevek@example.com" {
+			t.Errorf("GetCustomer() after update = %+v, %v", got, err)
+		}
+
+		if err := s.DeleteCustomer(ctx, customer.ID); err != nil {
+			t.Fatal(err)
+		}
+		if s.CustomerExists(customer.ID) || s.CustomersCount() != 0 {
+			t.Error("deleted customer is still found")
+		}
+	})
+}
+
+func TestOrders(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s store.Store) {
+		ctx := context.Background()
+		author := mustAuthor(t, s, "Ursula", "Le Guin")
+		book := mustBook(t, s, models.Book{Title: "The Dispossessed", Author: models.Author{ID: author.ID}, Price: 10, Stock: 3})
+		customer := mustCustomer(t, s, "shevek@example.com")
+
+		order := func(quantity int) models.Order {
+			return models.Order{
+				Customer: models.Customer{ID: customer.ID},
+				Items:    []models.OrderItem{{Book: models.Book{ID: book.ID}, Quantity: quantity}},
+			}
+		}
+		stock := func() int {
+			b, err := s.GetBook(ctx, book.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return b.Stock
+		}
+
+		if _, err := s.CreateOrder(ctx, order(4)); err == nil {
+			t.Error("CreateOrder() beyond the stock succeeded")
+		}
+		if got := stock(); got != 3 {
+			t.Errorf("stock after a refused order = %d, want 3", got)
+		}
+
+		first, err := s.CreateOrder(ctx, order(2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first.TotalPrice != 20 || first.Status != "created" {
+			t.Errorf("CreateOrder() = %+v", first)
+		}
+		if got := stock(); got != 1 {
+			t.Errorf("stock after an order = %d, want 1", got)
+		}
+
+		if _, err := s.CancelOrder(ctx, first.ID); err != nil {
+			t.Fatal(err)
+		}
+		if got := stock(); got != 3 {
+			t.Errorf("stock after cancelling = %d, want 3", got)
+		}
+		if _, err := s.CompleteOrder(ctx, first.ID); err == nil {
+			t.Error("CompleteOrder() of a cancelled order succeeded")
+		}
+
+		second, err := s.CreateOrder(ctx, order(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.CompleteOrder(ctx, second.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := s.GetOrder(ctx, second.ID)
+		if err != nil || got.Status != "completed" || len(got.Items) != 1 || got.Items[0].Book.Title != "The Dispossessed" {
+			t.Errorf("GetOrder() = %+v, %v", got, err)
+		}
+
+		completed, err := s.SearchOrderByStatus(ctx, "completed", models.PageRequest{})
+		if err != nil || len(completed.Items) != 1 || completed.Items[0].ID != second.ID {
+			t.Errorf("SearchOrderByStatus() = %+v, %v", completed, err)
+		}
+
+		now := time.Now()
+		recent, err := s.GetOrdersInTimeRange(ctx, now.Add(-time.Hour), now.Add(time.Hour), models.PageRequest{Limit: 1})
+		if err != nil || len(recent.Items) != 1 || recent.Total != 2 || recent.NextCursor == "" {
+			t.Fatalf("GetOrdersInTimeRange(limit 1) = %+v, %v; want 1 of 2 orders and a cursor", recent, err)
+		}
+		rest, err := s.GetOrdersInTimeRange(ctx, now.Add(-time.Hour), now.Add(time.Hour), models.PageRequest{Limit: 1, Cursor: recent.NextCursor})
+		if err != nil || len(rest.Items) != 1 || rest.Items[0].ID != second.ID || rest.NextCursor != "" {
+			t.Errorf("GetOrdersInTimeRange(next page) = %+v, %v; want the last order", rest, err)
+		}
+		old, err := s.GetOrdersInTimeRange(ctx, now.Add(-2*time.Hour), now.Add(-time.Hour), models.PageRequest{})
+		if err != nil || len(old.Items) != 0 {
+			t.Errorf("GetOrdersInTimeRange() in the past found %d orders, %v", len(old.Items), err)
+		}
+	})
+}
+
+func TestCarts(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s store.Store) {
+		ctx := context.Background()
+		author := mustAuthor(t, s, "Ursula", "Le Guin")
+		first := mustBook(t, s, models.Book{Title: "The Dispossessed", Author: author, Stock: 3})
+		second := mustBook(t, s, models.Book{Title: "The Lathe of Heaven", Author: author, Stock: 3})
+		session := models.SessionCartKey("s")
+		customer := models.CustomerCartKey(1)
+
+		if cart, err := s.GetCart(ctx, session); err != nil || len(cart.Items) != 0 {
+			t.Errorf("GetCart() of a new cart = %+v, %v; want it empty", cart, err)
+		}
+
+		if _, err := s.AddCartItem(ctx, session, first.ID, 1); err != nil {
+			t.Fatal(err)
+		}
+		cart, err := s.AddCartItem(ctx, session, first.ID, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(cart.Items) != 1 || cart.Items[0].Quantity != 3 {
+			t.Errorf("AddCartItem() twice = %+v, want the quantities added up", cart.Items)
+		}
+		if _, err := s.AddCartItem(ctx, session, 9999, 1); err == nil {
+			t.Error("AddCartItem() accepted a book that does not exist")
+		}
+
+		if _, err := s.SetCartItem(ctx, customer, first.ID, 1); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.SetCartItem(ctx, customer, second.ID, 2); err != nil {
+			t.Fatal(err)
+		}
+		if cart, err := s.SetCartItem(ctx, customer, second.ID, 0); err != nil || len(cart.Items) != 1 {
+			t.Errorf("SetCartItem() to zero = %+v, %v; want the book taken out", cart.Items, err)
+		}
+
+		cart, err = s.MergeCart(ctx, session, customer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(cart.Items) != 1 || cart.Items[0].BookID != first.ID || cart.Items[0].Quantity != 4 {
+			t.Errorf("MergeCart() = %+v, want 4 copies of book %d", cart.Items, first.ID)
+		}
+		if cart, err := s.GetCart(ctx, session); err != nil || len(cart.Items) != 0 {
+			t.Errorf("merged cart = %+v, %v; want it deleted", cart.Items, err)
+		}
+
+		// Only anonymous carts expire.
+		if _, err := s.AddCartItem(ctx, session, first.ID, 1); err != nil {
+			t.Fatal(err)
+		}
+		if n, err := s.ExpireSessionCarts(ctx, time.Now().Add(-time.Hour)); err != nil || n != 0 {
+			t.Errorf("ExpireSessionCarts() of fresh carts = %d, %v; want 0", n, err)
+		}
+		if n, err := s.ExpireSessionCarts(ctx, time.Now().Add(time.Hour)); err != nil || n != 1 {
+			t.Errorf("ExpireSessionCarts() = %d, %v; want 1", n, err)
+		}
+		if cart, err := s.GetCart(ctx, session); err != nil || len(cart.Items) != 0 {
+			t.Errorf("expired session cart = %+v, %v; want it deleted", cart.Items, err)
+		}
+		if cart, err := s.GetCart(ctx, customer); err != nil || len(cart.Items) != 1 {
+			t.Errorf("customer cart after expiry = %+v, %v; want it kept", cart.Items, err)
+		}
+
+		if err := s.DeleteCart(ctx, customer); err != nil {
+			t.Fatal(err)
+		}
+		if cart, err := s.GetCart(ctx, customer); err != nil || len(cart.Items) != 0 {
+			t.Errorf("GetCart() after DeleteCart() = %+v, %v; want it empty", cart.Items, err)
+		}
+	})
+}
+
+func TestRefreshTokens(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, s store.Store) {
+		ctx := context.Background()
+		expires := time.Now().Add(time.Hour)
+
+		if _, err := s.CreateRefreshToken(ctx, models.RefreshToken{
+			TokenHash: "a", CustomerID: 7, FamilyID: "f", ExpiresAt: expires,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		next, err := s.RotateRefreshToken(ctx, "a", models.RefreshToken{TokenHash: "b", ExpiresAt: expires})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next.CustomerID != 7 || next.FamilyID != "f" {
+			t.Errorf("RotateRefreshToken() = %+v, want it in the family of its parent", next)
+		}
+
+		if _, err := s.RotateRefreshToken(ctx, "a", models.RefreshToken{TokenHash: "c", ExpiresAt: expires}); !errors.Is(err, store.ErrRefreshTokenReused) {
+			t.Errorf("reusing a rotated token: error = %v, want %v", err, store.ErrRefreshTokenReused)
+		}
+		if _, err := s.RotateRefreshToken(ctx, "b", models.RefreshToken{TokenHash: "d", ExpiresAt: expires}); err == nil {
+			t.Error("token of a revoked family still rotates")
+		}
+		if _, err := s.RotateRefreshToken(ctx, "missing", models.RefreshToken{TokenHash: "e", ExpiresAt: expires}); !errors.Is(err, store.ErrRefreshTokenNotFound) {
+			t.Errorf("unknown token: error = %v, want %v", err, store.ErrRefreshTokenNotFound)
+		}
+
+		if _, err := s.CreateRefreshToken(ctx, models.RefreshToken{
+			TokenHash: "old", CustomerID: 7, FamilyID: "g", ExpiresAt: time.Now().Add(-time.Minute),
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.RotateRefreshToken(ctx, "old", models.RefreshToken{TokenHash: "f", ExpiresAt: expires}); err == nil {
+			t.Error("expired token still rotates")
+		}
+	})
+}
+
+func TestSQLiteStoreReopens(t *testing.T) {
+	ctx := context.Background()
+	path := t.TempDir() + "/bookstore.db"
+
+	s, err := store.NewSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	author, err := s.CreateAuthor(ctx, models.Author{FirstName: "Ursula", LastName: "Le Guin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	// Opening an existing database keeps its data.
+	s, err = store.NewSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if got, err := s.GetAuthor(ctx, author.ID); err != nil || got.LastName != "Le Guin" {
+		t.Errorf("GetAuthor() after reopening = %+v, %v", got, err)
+	}
+}
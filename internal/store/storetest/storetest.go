@@ -0,0 +1,50 @@
+// Package storetest opens stores for tests, kept in a temporary directory
+// that is removed when the test ends.
+package storetest
+
+import (
+	"Book-Store/internal/store"
+	"path/filepath"
+	"testing"
+)
+
+// Backend opens an empty store of one storage backend.
+type Backend struct {
+	Name string
+	Open func(t testing.TB) store.Store
+}
+
+// Backends lists every storage backend, so a test can run against each.
+var Backends = []Backend{
+	{Name: "mem", Open: func(t testing.TB) store.Store { return NewMemStore(t) }},
+	{Name: "sqlite", Open: func(t testing.TB) store.Store { return NewSQLiteStore(t) }},
+}
+
+// NewMemStore returns an empty MemStore persisted under t.TempDir().
+func NewMemStore(t testing.TB) *store.MemStore {
+	t.Helper()
+	return OpenMemStore(t, filepath.Join(t.TempDir(), "database.json"))
+}
+
+// OpenMemStore loads the MemStore kept at path, as the server does on start,
+// and closes it when the test ends.
+func OpenMemStore(t testing.TB, path string) *store.MemStore {
+	t.Helper()
+	s := store.NewMemStore()
+	if err := s.LoadFromFile(path); err != nil {
+		t.Fatalf("storetest: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// NewSQLiteStore returns an empty SQLiteStore kept under t.TempDir().
+func NewSQLiteStore(t testing.TB) *store.SQLiteStore {
+	t.Helper()
+	s, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "bookstore.db"))
+	if err != nil {
+		t.Fatalf("storetest: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
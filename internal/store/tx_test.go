@@ -0,0 +1,172 @@
+package store_test
+
+import (
+	"Book-Store/internal/models"
+	"Book-Store/internal/store"
+	"Book-Store/internal/store/storetest"
+	"context"
+	"errors"
+	"testing"
+)
+
+var errAbort = errors.New("abort")
+
+// hasAuthor reports whether s holds a under its ID. IDs taken by rolled back
+// changes are issued again, so the name is compared too.
+func hasAuthor(ctx context.Context, s store.Stores, a models.Author) bool {
+	got, err := s.GetAuthor(ctx, a.ID)
+	return err == nil && got.LastName == a.LastName
+}
+
+func TestWithTx(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// run creates authors in a transaction on s and returns the ones it
+		// expects to survive and to be rolled back.
+		run func(t *testing.T, s store.Store) (kept, dropped []models.Author, err error)
+	}{
+		{
+			name: "commit",
+			run: func(t *testing.T, s store.Store) ([]models.Author, []models.Author, error) {
+				var kept []models.Author
+				err := s.WithTx(ctx, func(tx store.Stores) error {
+					for _, name := range []string{"Le Guin", "Herbert"} {
+						a, err := tx.CreateAuthor(ctx, models.Author{FirstName: "A", LastName: name})
+						if err != nil {
+							return err
+						}
+						kept = append(kept, a)
+					}
+					return nil
+				})
+				return kept, nil, err
+			},
+		},
+		{
+			name: "error rolls back",
+			run: func(t *testing.T, s store.Store) ([]models.Author, []models.Author, error) {
+				var dropped []models.Author
+				err := s.WithTx(ctx, func(tx store.Stores) error {
+					for _, name := range []string{"Le Guin", "Herbert"} {
+						a, err := tx.CreateAuthor(ctx, models.Author{FirstName: "A", LastName: name})
+						if err != nil {
+							return err
+						}
+						dropped = append(dropped, a)
+					}
+					return errAbort
+				})
+				return nil, dropped, err
+			},
+		},
+		{
+			name: "failed nested transaction undoes only its own changes",
+			run: func(t *testing.T, s store.Store) ([]models.Author, []models.Author, error) {
+				var kept, dropped []models.Author
+				err := s.WithTx(ctx, func(tx store.Stores) error {
+					a, err := tx.CreateAuthor(ctx, models.Author{FirstName: "A", LastName: "Le Guin"})
+					if err != nil {
+						return err
+					}
+					kept = append(kept, a)
+
+					nestedErr := tx.WithTx(ctx, func(tx store.Stores) error {
+						a, err := tx.CreateAuthor(ctx, models.Author{FirstName: "A", LastName: "Herbert"})
+						if err != nil {
+							return err
+						}
+						dropped = append(dropped, a)
+						return errAbort
+					})
+					if !errors.Is(nestedErr, errAbort) {
+						t.Errorf("nested WithTx() error = %v, want %v", nestedErr, errAbort)
+					}
+					if hasAuthor(ctx, tx, dropped[0]) {
+						t.Error("author of the failed nested transaction is still visible")
+					}
+
+					a, err = tx.CreateAuthor(ctx, models.Author{FirstName: "A", LastName: "Banks"})
+					if err != nil {
+						return err
+					}
+					kept = append(kept, a)
+					return nil
+				})
+				return kept, dropped, err
+			},
+		},
+		{
+			name: "failed outer transaction undoes committed nested ones",
+			run: func(t *testing.T, s store.Store) ([]models.Author, []models.Author, error) {
+				var dropped []models.Author
+				err := s.WithTx(ctx, func(tx store.Stores) error {
+					err := tx.WithTx(ctx, func(tx store.Stores) error {
+						a, err := tx.CreateAuthor(ctx, models.Author{FirstName: "A", LastName: "Le Guin"})
+						if err != nil {
+							return err
+						}
+						dropped = append(dropped, a)
+						return nil
+					})
+					if err != nil {
+						return err
+					}
+					return errAbort
+				})
+				return nil, dropped, err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachBackend(t, func(t *testing.T, s store.Store) {
+				kept, dropped, err := tt.run(t, s)
+				if wantErr := len(kept) == 0; wantErr != errors.Is(err, errAbort) {
+					t.Fatalf("WithTx() error = %v", err)
+				}
+				for _, a := range kept {
+					if !hasAuthor(ctx, s, a) {
+						t.Errorf("author %q missing after commit", a.LastName)
+					}
+				}
+				for _, a := range dropped {
+					if hasAuthor(ctx, s, a) {
+						t.Errorf("author %q survived the rollback", a.LastName)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestMemWithTxPanicRollsBack(t *testing.T) {
+	ctx := context.Background()
+	s := storetest.NewMemStore(t)
+
+	var created models.Author
+	func() {
+		defer func() {
+			if r := recover(); r != errAbort {
+				t.Errorf("recovered %v, want the panic to pass through", r)
+			}
+		}()
+		s.WithTx(ctx, func(tx store.Stores) error {
+			a, err := tx.CreateAuthor(ctx, models.Author{FirstName: "A", LastName: "Le Guin"})
+			if err != nil {
+				return err
+			}
+			created = a
+			panic(errAbort)
+		})
+	}()
+
+	if hasAuthor(ctx, s, created) {
+		t.Errorf("author %q survived the panic", created.LastName)
+	}
+	// The lock must have been released.
+	if _, err := s.CreateAuthor(ctx, models.Author{FirstName: "A", LastName: "Herbert"}); err != nil {
+		t.Errorf("CreateAuthor() after the panic: %v", err)
+	}
+}
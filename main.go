@@ -1,14 +1,20 @@
 package main
 
 import (
+	"Book-Store/internal/authentication"
 	"Book-Store/internal/config"
+	"Book-Store/internal/covers"
 	"Book-Store/internal/http/handlers"
 	"Book-Store/internal/http/middleware"
 	"Book-Store/internal/http/router"
+	"Book-Store/internal/models"
+	"Book-Store/internal/recommendations"
 	"Book-Store/internal/reports"
 	"Book-Store/internal/scheduler"
 	"Book-Store/internal/store"
+	"context"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
@@ -19,61 +25,128 @@ import (
 )
 
 func main() {
-	cfg := config.LoadConfig()
-	memStore := store.NewMemStore()
-
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found")
 	}
 
+	cfg := config.LoadConfig()
+
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
 		log.Fatal("JWT_SECRET not found in environment")
 	}
 
-	apiCfg := &middleware.ApiConfig{}
+	apiCfg := &middleware.ApiConfig{Token: jwtSecret}
 
-	log.Printf("Loading database from: %s", cfg.DBPath)
-	if err := memStore.LoadFromFile(cfg.DBPath); err != nil {
+	dataStore, err := openStore(cfg)
+	if err != nil {
 		log.Fatalf("Failed to load database: %v", err)
 	}
 
+	if err := bootstrapAdmin(context.Background(), dataStore, os.Getenv("ADMIN_EMAIL"), os.Getenv("ADMIN_PASSWORD")); err != nil {
+		log.Fatalf("Failed to bootstrap admin: %v", err)
+	}
+
+	var ids store.PublicIDResolver
+	if cfg.PublicIDs {
+		if err := dataStore.EnablePublicIDs(context.Background()); err != nil {
+			log.Fatalf("Failed to enable public IDs: %v", err)
+		}
+		ids = dataStore
+	}
+
+	coverStore := covers.NewCoverStore(cfg.CoverDirectory)
+	recommender := recommendations.NewRecommender(dataStore, dataStore)
+
 	bookHandler := &handlers.BookHandler{
-		BookStore:   memStore,
-		AuthorStore: memStore,
+		BookStore:   dataStore,
+		AuthorStore: dataStore,
+		SeriesStore: dataStore,
+		ReviewStore: dataStore,
+		Tx:          dataStore,
+		IDs:         ids,
+
+		Recommendations: recommender,
+
+		ExportSender: cfg.ExportSender,
+		Currency:     cfg.Currency,
+
+		Covers:        coverStore,
+		MaxCoverBytes: int64(cfg.CoverMaxBytes),
+
+		MaxImportBytes: int64(cfg.ImportMaxBytes),
 	}
 
-	authorHandler := &handlers.AuthorHandler{Store: memStore}
+	authorHandler := &handlers.AuthorHandler{
+		Store:  dataStore,
+		Books:  dataStore,
+		Tx:     dataStore,
+		IDs:    ids,
+		Covers: coverStore,
+	}
+	seriesHandler := &handlers.SeriesHandler{
+		Store: dataStore,
+		IDs:   ids,
+	}
 	customerHandler := &handlers.CustomerHandler{
-		Store: memStore,
-		Cfg:   apiCfg,
+		Store:           dataStore,
+		Cfg:             apiCfg,
+		IDs:             ids,
+		Recommendations: recommender,
+	}
+	orderHandler := &handlers.OrderHandler{Store: dataStore, IDs: ids, Recommendations: recommender}
+	authHandler := &handlers.AuthHandler{
+		Store:  dataStore,
+		Tokens: dataStore,
+		Carts:  dataStore,
+		Cfg:    apiCfg,
+	}
+	cartHandler := &handlers.CartHandler{
+		Store: dataStore,
+		Books: dataStore,
+		Tx:    dataStore,
+		IDs:   ids,
 	}
-	orderHandler := &handlers.OrderHandler{Store: memStore}
 
 	reportStore := reports.NewReportStore(cfg.ReportOutputDirectory)
 	reportHandler := &handlers.ReportHandler{
-		OrderStore:  memStore,
+		OrderStore:  dataStore,
 		ReportStore: reportStore,
 	}
 
-	reportScheduler := scheduler.NewReportScheduler(memStore, reportStore, apiCfg, cfg.ReportInterval)
+	reportScheduler := scheduler.NewReportScheduler(dataStore, reportStore, apiCfg, cfg.ReportInterval)
 	reportScheduler.Start()
 
+	recommendationScheduler := scheduler.NewRecommendationScheduler(recommender, cfg.RecommendationInterval)
+	recommendationScheduler.Start()
+
+	cartScheduler := scheduler.NewCartScheduler(dataStore, cfg.SessionCartTTL, cfg.CartExpiryInterval)
+	cartScheduler.Start()
+
+	var backupHandler *handlers.BackupHandler
+	if backupStore, ok := dataStore.(store.BackupStore); ok {
+		backupHandler = &handlers.BackupHandler{Store: backupStore}
+	}
+
 	metricsHandler := &handlers.MetricsHandler{
-		BookStore:     memStore,
-		AuthorStore:   memStore,
-		CustomerStore: memStore,
-		OrderStore:    memStore,
+		BookStore:     dataStore,
+		AuthorStore:   dataStore,
+		CustomerStore: dataStore,
+		OrderStore:    dataStore,
 	}
 
 	router.Router(
 		apiCfg,
 		bookHandler,
 		authorHandler,
+		seriesHandler,
 		customerHandler,
 		orderHandler,
 		reportHandler,
 		metricsHandler,
+		authHandler,
+		backupHandler,
+		cartHandler,
 		apiCfg,
 	)
 
@@ -84,10 +157,73 @@ func main() {
 		<-sigChan
 		log.Println("Shutdown signal received, stopping scheduler...")
 		reportScheduler.Stop()
+		recommendationScheduler.Stop()
+		cartScheduler.Stop()
+		if closer, ok := dataStore.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				log.Printf("Error closing store: %v", err)
+			}
+		}
 		os.Exit(0)
 	}()
 
 	fmt.Printf("Running Server on port :%s\n", cfg.ServerPort)
 	fmt.Printf("Report generation interval: %v\n", cfg.ReportInterval)
-	log.Fatal(http.ListenAndServe(":"+cfg.ServerPort, nil))
+	var handler http.Handler = http.DefaultServeMux
+	if cfg.PublicIDs {
+		handler = middleware.HideInternalIDs(handler)
+	}
+	log.Fatal(http.ListenAndServe(":"+cfg.ServerPort, handler))
+}
+
+func openStore(cfg *config.Config) (store.Store, error) {
+	switch cfg.StoreBackend {
+	case config.StoreBackendSQLite:
+		log.Printf("Opening SQLite database: %s", cfg.SQLitePath)
+		return store.NewSQLiteStore(cfg.SQLitePath)
+	case config.StoreBackendMemory:
+		memStore := store.NewMemStore()
+		memStore.ConfigureBackups(cfg.BackupDirectory, cfg.BackupCount)
+		log.Printf("Loading database from: %s", cfg.DBPath)
+		if err := memStore.LoadFromFile(cfg.DBPath); err != nil {
+			return nil, err
+		}
+		return memStore, nil
+	default:
+		return nil, fmt.Errorf("unknown store backend %q", cfg.StoreBackend)
+	}
+}
+
+// bootstrapAdmin creates the first admin account from ADMIN_EMAIL and
+// ADMIN_PASSWORD. Further roles are granted by that admin through
+// PUT /customers/{id}.
+func bootstrapAdmin(ctx context.Context, customers store.CustomerStore, email, password string) error {
+	if email == "" || password == "" {
+		return nil
+	}
+
+	if existing, err := customers.GetCustomerByEmail(ctx, email); err == nil {
+		if existing.EffectiveRole() != models.RoleAdmin {
+			log.Printf("Admin bootstrap skipped: %s already exists as %s", email, existing.EffectiveRole())
+		}
+		return nil
+	}
+
+	hashedPassword, err := authentication.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	admin, err := customers.CreateCustomer(ctx, models.Customer{
+		Name:     "Administrator",
+		Email:    email,
+		Password: hashedPassword,
+		Role:     models.RoleAdmin,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Created admin account %s (id %d)", admin.Email, admin.ID)
+	return nil
 }